@@ -20,7 +20,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	// Router'ı al
-	mux := router.Setup()
+	mux := router.Setup("*") // Vercel için tüm originler
 
 	// İsteği Router'a yönlendir
 	mux.ServeHTTP(w, r)
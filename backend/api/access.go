@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// boardRole kullanıcının panodaki rolünü döndürür.
+// Sahip için "owner", üyeler için board_members.role, erişim yoksa boş string döner.
+func boardRole(token, boardID, userID string) (string, error) {
+	role, _, err := boardRoleState(token, boardID, userID)
+	return role, err
+}
+
+// boardRoleState rolle birlikte panonun arşivlenmiş olup olmadığını da döndürür.
+// Rol, pano üyeliği ile panonun çalışma alanındaki üyelikten güçlü olanıdır; sahiplik sadece boards.user_id'dir.
+func boardRoleState(token, boardID, userID string) (string, bool, error) {
+	// RLS: sahip, pano üyesi veya çalışma alanı üyesi değilse pano hiç dönmez
+	endpoint := fmt.Sprintf("boards?id=eq.%s&select=user_id,archived_at,workspace_id", boardID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	var boards []struct {
+		UserID      string  `json:"user_id"`
+		ArchivedAt  *string `json:"archived_at"`
+		WorkspaceID *string `json:"workspace_id"`
+	}
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		return "", false, err
+	}
+	if len(boards) == 0 {
+		return "", false, nil
+	}
+	archived := boards[0].ArchivedAt != nil
+	if boards[0].UserID == userID {
+		return RoleOwner, archived, nil
+	}
+
+	endpoint = fmt.Sprintf("board_members?board_id=eq.%s&user_id=eq.%s&select=role", boardID, userID)
+	resp, err = performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	var members []struct {
+		Role string `json:"role"`
+	}
+	if err := json.Unmarshal(resp, &members); err != nil {
+		return "", false, err
+	}
+	role := ""
+	if len(members) > 0 {
+		role = members[0].Role
+		if role == "" {
+			role = RoleMember
+		}
+	}
+
+	if boards[0].WorkspaceID != nil {
+		wsRole, err := workspaceRole(token, *boards[0].WorkspaceID, userID)
+		if err != nil {
+			return "", false, err
+		}
+		if inherited := workspaceBoardRole(wsRole); roleRank[inherited] > roleRank[role] {
+			role = inherited
+		}
+	}
+	return role, archived, nil
+}
+
+// boardUsersByEmail panoya erişimi olan kullanıcıları (sahip dahil) küçük harfli e-posta -> ID olarak döndürür.
+func boardUsersByEmail(token, boardID string) (map[string]string, error) {
+	endpoint := fmt.Sprintf("boards?id=eq.%s&select=user_id", boardID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var boards []struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		return nil, errBoardNotFound
+	}
+
+	// boards.user_id auth.users'a bağlı, profiles ile ayrı sorgulanır
+	users := make(map[string]string)
+	resp, err = performSupabaseRequest("GET", fmt.Sprintf("profiles?id=eq.%s&select=email", boards[0].UserID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var owners []Profile
+	if err := json.Unmarshal(resp, &owners); err != nil {
+		return nil, err
+	}
+	if len(owners) > 0 {
+		users[strings.ToLower(owners[0].Email)] = boards[0].UserID
+	}
+
+	endpoint = fmt.Sprintf("board_members?board_id=eq.%s&select=user_id,profiles(email)", boardID)
+	resp, err = performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []struct {
+		UserID  string   `json:"user_id"`
+		Profile *Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal(resp, &members); err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if m.Profile != nil && m.Profile.Email != "" {
+			users[strings.ToLower(m.Profile.Email)] = m.UserID
+		}
+	}
+	return users, nil
+}
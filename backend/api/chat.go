@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,12 +16,13 @@ import (
 
 // Message defines the structure of a chat message
 type Message struct {
-	Type        string `json:"type"` // "text", "join", "leave", "history"
-	Content     string `json:"content"`
-	SenderID    string `json:"sender_id"`
-	SenderEmail string `json:"sender_email"`
-	BoardID     string `json:"board_id"`
-	Timestamp   int64  `json:"timestamp"`
+	Type        string      `json:"type"` // "text", "join", "leave", "history", "auth" or a board event
+	Content     string      `json:"content"`
+	SenderID    string      `json:"sender_id"`
+	SenderEmail string      `json:"sender_email"`
+	BoardID     string      `json:"board_id"`
+	Timestamp   int64       `json:"timestamp"`
+	Data        interface{} `json:"data,omitempty"` // Payload of server-side board events
 }
 
 // Client represents a connected user
@@ -29,8 +33,37 @@ type Client struct {
 	Send   chan *Message
 	UserID string
 	Email  string
+	Role   string
+
+	SessionID   string
+	RemoteAddr  string
+	ConnectedAt time.Time
+
+	mu         sync.Mutex
+	credential string
+	expiresAt  time.Time
+	refreshed  chan struct{}
 }
 
+// WebSocket close codes (4000-4999 is reserved for applications)
+const (
+	closeUnauthorized = 4401
+	closeForbidden    = 4403
+)
+
+const (
+	// authSubprotocol is the Sec-WebSocket-Protocol marker that precedes the token:
+	// new WebSocket(url, ["access_token", token])
+	authSubprotocol = "access_token"
+	// authFrameTimeout is how long we wait for the first {"type":"auth"} frame
+	authFrameTimeout = 10 * time.Second
+)
+
+var (
+	errChatUnauthorized = errors.New("unauthorized")
+	errChatForbidden    = errors.New("forbidden")
+)
+
 // Room represents a board's chat room
 type Room struct {
 	BoardID    string
@@ -38,16 +71,28 @@ type Room struct {
 	Broadcast  chan *Message
 	Register   chan *Client
 	Unregister chan *Client
+
+	hub    *Hub
+	direct chan directMessage
+}
+
+// directMessage is a message for a single client of the room (e.g. a mention notification)
+type directMessage struct {
+	client  *Client
+	message *Message
 }
 
-// Hub manages all active rooms
+// Hub manages all active rooms and the registry of realtime sessions
 type Hub struct {
 	Rooms map[string]*Room
 	mu    sync.Mutex
+
+	sessions *sessionRegistry
 }
 
 var GlobalHub = &Hub{
-	Rooms: make(map[string]*Room),
+	Rooms:    make(map[string]*Room),
+	sessions: newSessionRegistry(),
 }
 
 func (h *Hub) GetRoom(boardID string) *Room {
@@ -64,6 +109,8 @@ func (h *Hub) GetRoom(boardID string) *Room {
 		Broadcast:  make(chan *Message),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
+		hub:        h,
+		direct:     make(chan directMessage),
 	}
 	h.Rooms[boardID] = room
 	go room.Run()
@@ -75,12 +122,23 @@ func (r *Room) Run() {
 		select {
 		case client := <-r.Register:
 			r.Clients[client] = true
+			r.hub.sessions.add(client)
 
 		case client := <-r.Unregister:
 			if _, ok := r.Clients[client]; ok {
 				delete(r.Clients, client)
 				close(client.Send)
 			}
+			r.hub.sessions.remove(client)
+
+		case d := <-r.direct:
+			// The client may have left the room meanwhile
+			if _, ok := r.Clients[d.client]; ok {
+				select {
+				case d.client.Send <- d.message:
+				default:
+				}
+			}
 
 		case message := <-r.Broadcast:
 			for client := range r.Clients {
@@ -89,25 +147,96 @@ func (r *Room) Run() {
 				default:
 					close(client.Send)
 					delete(r.Clients, client)
+					r.hub.sessions.remove(client)
 				}
 			}
 		}
 	}
 }
 
+// Publish pushes a server-side board event (e.g. "member_removed") to everyone in the board's room.
+// Boards without connected clients have no room and the event is dropped.
+func (h *Hub) Publish(boardID, eventType string, data interface{}) {
+	h.mu.Lock()
+	room, ok := h.Rooms[boardID]
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	msg := &Message{
+		Type:      eventType,
+		BoardID:   boardID,
+		Timestamp: time.Now().UnixMilli(),
+		Data:      data,
+	}
+	// Don't block the HTTP handler on the room loop
+	go func() { room.Broadcast <- msg }()
+}
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
+	Subprotocols: []string{authSubprotocol},
+}
+
+// role returns the current board role (it can change on revalidation).
+func (c *Client) role() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Role
+}
+
+// ExpiresAt returns when the client's access token expires (zero if unknown).
+func (c *Client) ExpiresAt() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expiresAt
+}
+
+// extendSession accepts a refreshed token for the same user and moves the expiry forward.
+func (c *Client) extendSession(token string) error {
+	principal, err := authenticate(context.Background(), token)
+	if err != nil || principal.UserID != c.UserID {
+		return errChatUnauthorized
+	}
+
+	c.mu.Lock()
+	c.credential = token
+	c.expiresAt = principal.ExpiresAt
+	c.mu.Unlock()
+
+	select {
+	case c.refreshed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// expiryTimer fires when the token expires; a nil channel never fires.
+func (c *Client) expiryTimer() (*time.Timer, <-chan time.Time) {
+	exp := c.ExpiresAt()
+	if exp.IsZero() {
+		return nil, nil
+	}
+	t := time.NewTimer(time.Until(exp))
+	return t, t.C
 }
 
 // WritePump pumps messages from the hub to the websocket connection.
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(54 * time.Second)
+	revalidate := time.NewTicker(revalidateInterval)
+	timer, expired := c.expiryTimer()
 	defer func() {
 		ticker.Stop()
+		revalidate.Stop()
+		if timer != nil {
+			timer.Stop()
+		}
 		c.Conn.Close()
 	}()
 
@@ -126,6 +255,19 @@ func (c *Client) WritePump() {
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+
+		case <-revalidate.C:
+			go c.revalidate()
+
+		case <-c.refreshed:
+			if timer != nil {
+				timer.Stop()
+			}
+			timer, expired = c.expiryTimer()
+
+		case <-expired:
+			closeWithReason(c.Conn, closeUnauthorized, "token expired")
+			return
 		}
 	}
 }
@@ -154,7 +296,23 @@ func (c *Client) ReadPump() {
 			break
 		}
 
-		// Enforce server-side data
+		// Token refresh frames are never broadcast
+		if msg.Type == "auth" {
+			if err := c.extendSession(msg.Content); err != nil {
+				closeWithReason(c.Conn, closeUnauthorized, "invalid token")
+				break
+			}
+			continue
+		}
+
+		// Read-only members (viewers) can follow the chat but not post
+		if !RoleAllows(c.role(), PermComment) {
+			continue
+		}
+
+		// Enforce server-side data (clients can only send chat text, never board events)
+		msg.Type = "text"
+		msg.Data = nil
 		msg.SenderID = c.UserID
 		msg.SenderEmail = c.Email
 		msg.BoardID = c.Room.BoardID
@@ -179,31 +337,129 @@ func (c *Client) ReadPump() {
 	}
 }
 
-// HandleWebSocket handles WS requests
+// closeWithReason sends a close frame so the browser sees the code and reason.
+func closeWithReason(conn *websocket.Conn, code int, reason string) {
+	deadline := time.Now().Add(time.Second)
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+}
+
+// tokenFromSubprotocol extracts the token from "Sec-WebSocket-Protocol: access_token, <token>".
+func tokenFromSubprotocol(r *http.Request) string {
+	protocols := websocket.Subprotocols(r)
+	for i, p := range protocols {
+		if p == authSubprotocol && i+1 < len(protocols) {
+			return strings.TrimSpace(protocols[i+1])
+		}
+	}
+	return ""
+}
+
+// authorizeChat derives the identity from the token and loads the caller's board role.
+func authorizeChat(ctx context.Context, token, boardID string) (*Principal, string, error) {
+	principal, err := authenticate(ctx, token)
+	if err != nil {
+		return nil, "", errChatUnauthorized
+	}
+
+	role, err := chatRole(principal.Token, boardID, principal.UserID)
+	if err != nil {
+		log.Printf("Chat membership check failed: %v", err)
+		return nil, "", errChatForbidden
+	}
+	if !RoleAllows(role, PermRead) || !principal.HasScope(ScopeChat) {
+		return nil, "", errChatForbidden
+	}
+	return principal, role, nil
+}
+
+// chatRole loads the board role for chat. Archived boards are read-only, so everyone
+// is treated as a viewer there: they can follow the history but not post.
+func chatRole(token, boardID, userID string) (string, error) {
+	role, archived, err := boardRoleState(token, boardID, userID)
+	if err != nil {
+		return "", err
+	}
+	if archived && RoleAllows(role, PermRead) {
+		return RoleViewer, nil
+	}
+	return role, nil
+}
+
+// awaitAuthFrame waits for {"type":"auth","content":"<token>"} as the first frame.
+func awaitAuthFrame(conn *websocket.Conn, boardID string) (*Principal, string, string, error) {
+	conn.SetReadDeadline(time.Now().Add(authFrameTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var msg Message
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "auth" || msg.Content == "" {
+		return nil, "", "", errChatUnauthorized
+	}
+	principal, role, err := authorizeChat(context.Background(), msg.Content, boardID)
+	return principal, role, msg.Content, err
+}
+
+// HandleWebSocket handles WS requests.
+// The access token comes either in the Sec-WebSocket-Protocol header or in the first frame;
+// user identity is never taken from the query string.
 func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	boardID := r.URL.Query().Get("board_id")
-	userID := r.URL.Query().Get("user_id")
-	email := r.URL.Query().Get("email")
-
-	if boardID == "" || userID == "" {
+	if boardID == "" {
 		http.Error(w, "Missing params", http.StatusBadRequest)
 		return
 	}
 
+	var principal *Principal
+	var role, credential string
+	if token := tokenFromSubprotocol(r); token != "" {
+		p, rl, err := authorizeChat(r.Context(), token, boardID)
+		if errors.Is(err, errChatForbidden) {
+			http.Error(w, "Bu panoya erişim yetkiniz yok", http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Geçersiz Token", http.StatusUnauthorized)
+			return
+		}
+		principal, role, credential = p, rl, token
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 
+	if principal == nil {
+		p, rl, token, err := awaitAuthFrame(conn, boardID)
+		if err != nil {
+			code := closeUnauthorized
+			if errors.Is(err, errChatForbidden) {
+				code = closeForbidden
+			}
+			closeWithReason(conn, code, err.Error())
+			conn.Close()
+			return
+		}
+		principal, role, credential = p, rl, token
+	}
+
 	room := GlobalHub.GetRoom(boardID)
 	client := &Client{
 		Hub:    GlobalHub,
 		Room:   room,
 		Conn:   conn,
 		Send:   make(chan *Message, 256),
-		UserID: userID,
-		Email:  email,
+		UserID: principal.UserID,
+		Email:  principal.Email,
+		Role:   role,
+
+		SessionID:   newSessionID(),
+		RemoteAddr:  r.RemoteAddr,
+		ConnectedAt: time.Now(),
+
+		credential: credential,
+		expiresAt:  principal.ExpiresAt,
+		refreshed:  make(chan struct{}, 1),
 	}
 
 	client.Room.Register <- client
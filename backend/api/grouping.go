@@ -0,0 +1,276 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Gruplama alanları (GetTasks ?group_by=)
+const (
+	GroupByAssignee  = "assignee"
+	GroupByPriority  = "priority"
+	GroupByLabel     = "label"
+	GroupByDueBucket = "due_bucket"
+	GroupByStatus    = "status"
+)
+
+// maxGroupDepth swimlane için en fazla iki seviye (ör. assignee,status)
+const maxGroupDepth = 2
+
+// Bitiş tarihi kovaları (sıralı)
+var dueBuckets = []struct{ Key, Title string }{
+	{"overdue", "Gecikmiş"},
+	{"today", "Bugün"},
+	{"this_week", "Bu hafta"},
+	{"later", "Daha sonra"},
+	{"no_date", "Tarih yok"},
+}
+
+// TaskGroup gruplanmış görev listesinin bir grubu. Son seviyede Tasks, üst seviyelerde Groups doludur.
+type TaskGroup struct {
+	Key    string      `json:"key"` // atanmamış/önceliksiz gibi boş gruplar için ""
+	Title  string      `json:"title"`
+	Count  int         `json:"count"`
+	Tasks  []Task      `json:"tasks,omitempty"`
+	Groups []TaskGroup `json:"groups,omitempty"`
+}
+
+// GroupedTasks GetTasks'ın group_by verildiğindeki yanıtı
+type GroupedTasks struct {
+	GroupBy []string    `json:"group_by"`
+	Total   int         `json:"total"` // tekil görev sayısı (etiket gruplarının toplamı bundan büyük olabilir)
+	Groups  []TaskGroup `json:"groups"`
+}
+
+// taskGrouper bir alana göre görevin grup anahtarını ve grupların sırasını belirler
+type taskGrouper struct {
+	key func(Task) string
+	// keys birden fazla gruba girebilen görevler için (etiketler); verilmişse key yerine kullanılır
+	keys  func(Task) []string
+	title func(key string) string
+	// fixed her zaman (boş olsa da) gösterilecek grupların sırası; nil ise sadece dolu gruplar
+	fixed []string
+	// less sabit listede olmayan anahtarların sırası
+	less func(a, b string) bool
+}
+
+// parseGroupBy "assignee,status" gibi bir değeri doğrular.
+func parseGroupBy(value string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(value, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		switch f {
+		case GroupByAssignee, GroupByPriority, GroupByLabel, GroupByDueBucket, GroupByStatus:
+		default:
+			return nil, fmt.Errorf("geçersiz group_by: %s (assignee, priority, label, due_bucket, status)", f)
+		}
+		if seen[f] {
+			return nil, fmt.Errorf("group_by alanı tekrar ediyor: %s", f)
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("group_by boş olamaz")
+	}
+	if len(fields) > maxGroupDepth {
+		return nil, fmt.Errorf("en fazla %d seviye gruplama yapılabilir", maxGroupDepth)
+	}
+	return fields, nil
+}
+
+// newTaskGrouper alan için grouper oluşturur. wf nil ise (pano verilmemiş) durumlar görevlerden çıkarılır.
+func newTaskGrouper(field string, wf *Workflow, now time.Time) taskGrouper {
+	switch field {
+	case GroupByAssignee:
+		emails := make(map[string]string)
+		return taskGrouper{
+			key: func(t Task) string {
+				if t.AssignedTo == nil {
+					return ""
+				}
+				if t.Assignee != nil {
+					emails[*t.AssignedTo] = t.Assignee.Email
+				}
+				return *t.AssignedTo
+			},
+			title: func(key string) string {
+				if key == "" {
+					return "Atanmamış"
+				}
+				if email := emails[key]; email != "" {
+					return email
+				}
+				return key
+			},
+			less: func(a, b string) bool { return strings.ToLower(emails[a]) < strings.ToLower(emails[b]) },
+		}
+
+	case GroupByLabel:
+		names := make(map[string]string)
+		return taskGrouper{
+			keys: func(t Task) []string {
+				keys := make([]string, 0, len(t.Labels))
+				for _, l := range t.Labels {
+					names[l.ID] = l.Name
+					keys = append(keys, l.ID)
+				}
+				return keys
+			},
+			title: func(key string) string {
+				if key == "" {
+					return "Etiketsiz"
+				}
+				return names[key]
+			},
+			less: func(a, b string) bool { return strings.ToLower(names[a]) < strings.ToLower(names[b]) },
+		}
+
+	case GroupByPriority:
+		return taskGrouper{
+			key: func(t Task) string {
+				if p, ok := normalizePriority(t.Priority); ok {
+					return p
+				}
+				return ""
+			},
+			title: func(key string) string {
+				if key == "" {
+					return "Önceliksiz"
+				}
+				return key
+			},
+			fixed: []string{PriorityHigh, PriorityMedium, PriorityLow},
+			less:  func(a, b string) bool { return a < b },
+		}
+
+	case GroupByDueBucket:
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		titles := make(map[string]string)
+		fixed := make([]string, 0, len(dueBuckets))
+		for _, b := range dueBuckets {
+			titles[b.Key] = b.Title
+			fixed = append(fixed, b.Key)
+		}
+		return taskGrouper{
+			key:   func(t Task) string { return dueBucket(t.DueDate, today) },
+			title: func(key string) string { return titles[key] },
+			fixed: fixed,
+			less:  func(a, b string) bool { return a < b },
+		}
+
+	default: // GroupByStatus
+		g := taskGrouper{
+			key:   func(t Task) string { return t.Status },
+			title: func(key string) string { return key },
+			less:  func(a, b string) bool { return a < b },
+		}
+		if wf != nil {
+			statuses := append([]BoardStatus(nil), wf.Statuses...)
+			sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Order < statuses[j].Order })
+			for _, s := range statuses {
+				g.fixed = append(g.fixed, s.Key)
+			}
+			g.title = func(key string) string {
+				if s, ok := wf.Status(key); ok && s.Title != "" {
+					return s.Title
+				}
+				return key
+			}
+		}
+		return g
+	}
+}
+
+// dueBucket bitiş tarihini kovaya yerleştirir. today gün başlangıcıdır (UTC olarak temsil edilir).
+func dueBucket(due *string, today time.Time) string {
+	if due == nil || *due == "" {
+		return "no_date"
+	}
+	value := *due
+	if len(value) > 10 {
+		value = value[:10]
+	}
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "no_date"
+	}
+	switch {
+	case d.Before(today):
+		return "overdue"
+	case d.Equal(today):
+		return "today"
+	case d.Before(today.AddDate(0, 0, 7)):
+		return "this_week"
+	default:
+		return "later"
+	}
+}
+
+// groupKeys görevin girdiği grupların anahtarları (etiketsiz görevler "" grubuna düşer)
+func (g taskGrouper) groupKeys(t Task) []string {
+	if g.keys == nil {
+		return []string{g.key(t)}
+	}
+	keys := g.keys(t)
+	if len(keys) == 0 {
+		return []string{""}
+	}
+	return keys
+}
+
+// groupTasks görevleri sıralı gruplara ayırır; görevlerin kendi sırası (sortTasks) grup içinde korunur.
+// Etikete göre gruplamada birden fazla etiketi olan görev her etiketin grubunda yer alır.
+func groupTasks(tasks []Task, fields []string, wf *Workflow, now time.Time) []TaskGroup {
+	g := newTaskGrouper(fields[0], wf, now)
+
+	buckets := make(map[string][]Task)
+	for _, t := range tasks {
+		for _, k := range g.groupKeys(t) {
+			buckets[k] = append(buckets[k], t)
+		}
+	}
+
+	var keys []string
+	inFixed := make(map[string]bool)
+	for _, k := range g.fixed {
+		keys = append(keys, k)
+		inFixed[k] = true
+	}
+	var rest []string
+	for k := range buckets {
+		if !inFixed[k] && k != "" {
+			rest = append(rest, k)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		a, b := rest[i], rest[j]
+		if g.less(a, b) != g.less(b, a) {
+			return g.less(a, b)
+		}
+		return a < b // harita sırası rastgele, eşitlikte anahtarla sabitle
+	})
+	keys = append(keys, rest...)
+	if _, ok := buckets[""]; ok && !inFixed[""] {
+		keys = append(keys, "") // atanmamış / önceliksiz / etiketsiz / bilinmeyen en sonda
+	}
+
+	groups := make([]TaskGroup, 0, len(keys))
+	for _, k := range keys {
+		members := buckets[k]
+		group := TaskGroup{Key: k, Title: g.title(k), Count: len(members)}
+		if len(fields) > 1 {
+			group.Groups = groupTasks(members, fields[1:], wf, now)
+		} else {
+			group.Tasks = members
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
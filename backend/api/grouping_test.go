@@ -0,0 +1,137 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDueBucket(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		due  *string
+		want string
+	}{
+		{"tarih yok", nil, "no_date"},
+		{"boş tarih", strPtr(""), "no_date"},
+		{"geçersiz tarih", strPtr("10.03.2026"), "no_date"},
+		{"dün", strPtr("2026-03-09"), "overdue"},
+		{"geçen yıl", strPtr("2025-12-31"), "overdue"},
+		{"bugün", strPtr("2026-03-10"), "today"},
+		{"zaman damgalı bugün", strPtr("2026-03-10T23:59:00Z"), "today"},
+		{"yarın", strPtr("2026-03-11"), "this_week"},
+		{"altı gün sonra", strPtr("2026-03-16"), "this_week"},
+		{"yedi gün sonra", strPtr("2026-03-17"), "later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dueBucket(tt.due, today); got != tt.want {
+				t.Errorf("dueBucket(%v) = %s, beklenen %s", stringValue(tt.due), got, tt.want)
+			}
+		})
+	}
+}
+
+// bucketOf görevlerin due_bucket gruplarını anahtar -> başlıklar olarak döndürür.
+func bucketOf(tasks []Task, now time.Time) map[string][]string {
+	result := make(map[string][]string)
+	for _, g := range groupTasks(tasks, []string{GroupByDueBucket}, nil, now) {
+		for _, t := range g.Tasks {
+			result[g.Key] = append(result[g.Key], t.Title)
+		}
+	}
+	return result
+}
+
+func TestDueBucketTimezoneAroundMidnight(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// UTC'de 1 Mart 22:30: İstanbul'da (UTC+3) 2 Mart 01:30, New York'ta (UTC-5) 1 Mart 17:30
+	instant := time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC)
+	tasks := []Task{
+		{Title: "1 Mart", DueDate: strPtr("2026-03-01")},
+		{Title: "2 Mart", DueDate: strPtr("2026-03-02")},
+		{Title: "8 Mart", DueDate: strPtr("2026-03-08")},
+		{Title: "9 Mart", DueDate: strPtr("2026-03-09")},
+		{Title: "Tarihsiz"},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want map[string][]string
+	}{
+		{
+			name: "UTC",
+			now:  instant,
+			want: map[string][]string{
+				"today": {"1 Mart"}, "this_week": {"2 Mart"}, "later": {"8 Mart", "9 Mart"}, "no_date": {"Tarihsiz"},
+			},
+		},
+		{
+			name: "gece yarısını geçmiş saat dilimi",
+			now:  instant.In(istanbul),
+			want: map[string][]string{
+				"overdue": {"1 Mart"}, "today": {"2 Mart"}, "this_week": {"8 Mart"}, "later": {"9 Mart"}, "no_date": {"Tarihsiz"},
+			},
+		},
+		{
+			name: "henüz gece yarısına gelmemiş saat dilimi",
+			now:  instant.In(newYork),
+			want: map[string][]string{
+				"today": {"1 Mart"}, "this_week": {"2 Mart"}, "later": {"8 Mart", "9 Mart"}, "no_date": {"Tarihsiz"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bucketOf(tasks, tt.now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupTasksSwimlanes(t *testing.T) {
+	wf := testWorkflow()
+	alice, bob := "u-alice", "u-bob"
+	tasks := []Task{
+		{Title: "A1", Status: "Done", AssignedTo: &bob, Assignee: &Profile{Email: "bob@example.com"}},
+		{Title: "A2", Status: "Todo", AssignedTo: &alice, Assignee: &Profile{Email: "alice@example.com"}},
+		{Title: "A3", Status: "Todo"},
+		{Title: "A4", Status: "In Review", AssignedTo: &alice, Assignee: &Profile{Email: "alice@example.com"}},
+	}
+
+	groups := groupTasks(tasks, []string{GroupByAssignee, GroupByStatus}, &wf, time.Now())
+
+	// Kişiler e-postaya göre, atanmamış en sonda; her şeritte panonun bütün sütunları sırayla
+	var lanes []string
+	for _, g := range groups {
+		lanes = append(lanes, g.Title)
+		if len(g.Groups) != len(wf.Statuses) {
+			t.Fatalf("%s şeridinde %d sütun, beklenen %d", g.Title, len(g.Groups), len(wf.Statuses))
+		}
+		for i, col := range g.Groups {
+			if col.Key != wf.Statuses[i].Key || col.Title != wf.Statuses[i].Title || col.Count != len(col.Tasks) {
+				t.Errorf("%s şeridi %d. sütun: %+v", g.Title, i, col)
+			}
+		}
+	}
+	if want := []string{"alice@example.com", "bob@example.com", "Atanmamış"}; !reflect.DeepEqual(lanes, want) {
+		t.Errorf("şeritler = %v, beklenen %v", lanes, want)
+	}
+	if got := groups[0].Groups[1].Tasks; len(got) != 1 || got[0].Title != "A4" {
+		t.Errorf("alice / In Review = %+v", got)
+	}
+	if groups[2].Key != "" || groups[2].Count != 1 {
+		t.Errorf("atanmamış şerit = %+v", groups[2])
+	}
+}
@@ -3,12 +3,16 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go-panel/backend/db"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
+	"strings"
+	"time"
 )
 
 type Subtask struct {
@@ -24,19 +28,52 @@ type Profile struct {
 }
 
 type Task struct {
-	ID          string    `json:"id,omitempty"`
-	Title       string    `json:"title,omitempty"`
-	Description *string   `json:"description,omitempty"`
-	Status      string    `json:"status,omitempty"`   // Todo, Doing, Done
-	Priority    string    `json:"priority,omitempty"` // Low, Medium, High
-	DueDate     *string   `json:"due_date,omitempty"`
-	Position    int       `json:"position,omitempty"`
-	UserID      string    `json:"user_id,omitempty"`
-	BoardID     string    `json:"board_id,omitempty"`
-	AssignedTo  *string   `json:"assigned_to,omitempty"`
-	Subtasks    []Subtask `json:"subtasks,omitempty"`
-	Profile     *Profile  `json:"profiles,omitempty"`  // Creator (via user_id)
-	Assignee    *Profile  `json:"assignees,omitempty"` // Assignee (via assigned_to)
+	ID           string          `json:"id,omitempty"`
+	Title        string          `json:"title,omitempty"`
+	Description  *string         `json:"description,omitempty"`
+	Status       string          `json:"status,omitempty"`   // boards.workflow'daki sütun anahtarı
+	Priority     string          `json:"priority,omitempty"` // Low, Medium, High
+	DueDate      *string         `json:"due_date,omitempty"`
+	Position     int             `json:"position,omitempty"`
+	UserID       string          `json:"user_id,omitempty"`
+	BoardID      string          `json:"board_id,omitempty"`
+	AssignedTo   *string         `json:"assigned_to,omitempty"`
+	Subtasks     []Subtask       `json:"subtasks,omitempty"`
+	Labels       []Label         `json:"labels,omitempty"`           // task_labels üzerinden pano etiketleri
+	Comments     *int            `json:"comment_count,omitempty"`    // silinmemiş yorum sayısı (sadece okuma)
+	Attachments  *int            `json:"attachment_count,omitempty"` // ek sayısı (sadece okuma)
+	Blocked      *bool           `json:"blocked,omitempty"`          // açık engelleyicisi var (sadece okuma)
+	RecurrenceID *string         `json:"recurrence_id,omitempty"`    // tekrarlayan serisi (task_recurrences)
+	OccurrenceAt *string         `json:"occurrence_at,omitempty"`    // serideki tekrar zamanı
+	Recurrence   *TaskRecurrence `json:"recurrence,omitempty"`
+	Profile      *Profile        `json:"profiles,omitempty"`  // Creator (via user_id)
+	Assignee     *Profile        `json:"assignees,omitempty"` // Assignee (via assigned_to)
+}
+
+// clearManaged istemcinin görev gövdesiyle yazamayacağı alanları temizler; etiketler, yorumlar, ekler,
+// bağımlılıklar ve tekrarlar kendi uç noktalarıyla yönetilir.
+func (t *Task) clearManaged() {
+	t.Labels, t.Comments, t.Attachments, t.Blocked = nil, nil, nil, nil
+	t.RecurrenceID, t.OccurrenceAt, t.Recurrence = nil, nil, nil
+}
+
+// SupabaseError Supabase REST API'sinin döndürdüğü HTTP hatası
+type SupabaseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *SupabaseError) Error() string {
+	return fmt.Sprintf("Supabase Hatası (%d): %s", e.StatusCode, e.Body)
+}
+
+// supabaseStatus hatanın Supabase HTTP durum kodunu döndürür (bilinmiyorsa 500).
+func supabaseStatus(err error) int {
+	var sbErr *SupabaseError
+	if errors.As(err, &sbErr) {
+		return sbErr.StatusCode
+	}
+	return http.StatusInternalServerError
 }
 
 // performSupabaseRequest, Supabase REST API'sine istek atmak için yardımcı fonksiyon
@@ -81,13 +118,14 @@ func performSupabaseRequest(method, endpoint, token string, body interface{}) ([
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("Supabase Hatası (%d): %s", resp.StatusCode, string(respBytes))
+		return nil, &SupabaseError{StatusCode: resp.StatusCode, Body: string(respBytes)}
 	}
 
 	return respBytes, nil
 }
 
 // GetTasks giriş yapmış kullanıcıya ait görevleri getirir.
+// Filtreler: board_id, status, priority, assigned_to, labels + labels_match (bkz. loadTasks).
 func GetTasks(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if len(authHeader) < 8 {
@@ -96,56 +134,159 @@ func GetTasks(w http.ResponseWriter, r *http.Request) {
 	}
 	token := authHeader[7:]
 
-	// SELECT *, subtasks(*), profiles!user_id(email), assignees:profiles!assigned_to(email)
-	// Not: PostgREST'te birden fazla FK aynı tabloya gidiyorsa !FK_COL_NAME syntax'ı ile ayırmak gerekir.
-	endpoint := "tasks?select=*,subtasks(*),profiles!user_id(email),assignees:profiles!assigned_to(email)"
-	boardID := r.URL.Query().Get("board_id")
-	if boardID != "" {
-		endpoint = fmt.Sprintf("%s&board_id=eq.%s", endpoint, boardID)
+	query := r.URL.Query()
+
+	// group_by=assignee,status gibi gruplama (swimlane) istenmişse önce parametreleri doğrula
+	var groupBy []string
+	now := time.Now()
+	if value := query.Get("group_by"); value != "" {
+		fields, err := parseGroupBy(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		groupBy = fields
+		// due_bucket günleri ?tz= ile, verilmezse panonun saat diliminde (settings.timezone) hesaplanır
+		tz := query.Get("tz")
+		if boardID := query.Get("board_id"); tz == "" && boardID != "" && containsString(groupBy, GroupByDueBucket) {
+			board, err := loadBoard(token, boardID)
+			if err != nil && err != errBoardNotFound {
+				fmt.Println("GetTasks Hatası:", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if board != nil && board.Settings != nil {
+				tz = board.Settings.Timezone
+			}
+		}
+		if tz != "" {
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				http.Error(w, "Geçersiz tz: "+tz, http.StatusBadRequest)
+				return
+			}
+			now = now.In(loc)
+		}
 	}
 
-	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	tasks, err := loadTasks(token, query)
+	if _, ok := err.(requestError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		fmt.Println("GetTasks Hatası:", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	if groupBy == nil {
+		if err := json.NewEncoder(w).Encode(tasks); err != nil {
+			http.Error(w, "Veri yazma hatası", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Durum grupları panonun sütun sırasını izler; pano verilmemişse görevlerdeki durumlar kullanılır
+	var workflow *Workflow
+	if boardID := query.Get("board_id"); boardID != "" {
+		wf, err := boardWorkflow(token, boardID)
+		if err != nil {
+			fmt.Println("GetTasks Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		workflow = &wf
+	}
+
+	result := GroupedTasks{
+		GroupBy: groupBy,
+		Total:   len(tasks),
+		Groups:  groupTasks(tasks, groupBy, workflow, now),
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "Veri yazma hatası", http.StatusInternalServerError)
+	}
+}
+
+// loadTasks query filtrelerine uyan görevleri alt görev, etiket ve profilleriyle yükler, öncelik ve tarihe göre sıralar.
+// GetTasks ve dışa aktarma uç noktaları aynı filtreleri kullanır. labels (ID veya isim, virgülle) filtresi
+// labels_match=any (varsayılan) ile etiketlerden birini, all ile hepsini taşıyan görevleri bırakır.
+func loadTasks(token string, query url.Values) ([]Task, error) {
+	filter, err := parseLabelFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// SELECT *, comment_count, attachment_count, blocked:is_blocked, subtasks(*), labels:board_labels(...), recurrence:task_recurrences(...), profiles!user_id(email), assignees:profiles!assigned_to(email)
+	// Not: PostgREST'te birden fazla FK aynı tabloya gidiyorsa !FK_COL_NAME syntax'ı ile ayırmak gerekir.
+	// Etiketler task_labels ara tablosu üzerinden (çoka çok) gömülür; comment_count, attachment_count ve is_blocked hesaplanan kolonlardır.
+	endpoint := "tasks?select=*,comment_count,attachment_count,blocked:is_blocked,subtasks(*),labels:board_labels(id,name,color),recurrence:task_recurrences!recurrence_id(id,rule,timezone,next_at,stopped_at),profiles!user_id(email),assignees:profiles!assigned_to(email)"
+	for _, param := range []string{"board_id", "status", "priority", "assigned_to"} {
+		if value := query.Get(param); value != "" {
+			endpoint = fmt.Sprintf("%s&%s=eq.%s", endpoint, param, url.QueryEscape(value))
+		}
+	}
+
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	var tasks []Task
 	if err := json.Unmarshal(resp, &tasks); err != nil {
-		fmt.Println("GetTasks Unmarshal Hatası:", err)
-		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
-		return
-	}
-
-	// Helper function for priority weight
-	getPriorityWeight := func(p string) int {
-		// Basit case-insensitive kontrol
-		// strings paketi import edilmeli ama burada hardcode da yapabiliriz veya strings import edebiliriz.
-		// Performance açısından switch ile lowercase yapmadan kontrol edelim veya strings.ToLower kullanalım.
-		// Import listesine "strings" eklemediysek, manuel check yapalim.
-
-		// Eğer strings importlu değilse:
-		/*
-			p = strings.ToLower(p)
-		*/
-		// Manuel lowercase conversion veya çoklu check:
-		switch p {
-		case "High", "high", "HIGH", "Yüksek", "yüksek":
-			return 3
-		case "Medium", "medium", "MEDIUM", "Orta", "orta":
-			return 2
-		case "Low", "low", "LOW", "Düşük", "düşük":
-			return 1
-		default:
-			return 0
-		}
+		return nil, fmt.Errorf("veri işleme hatası: %w", err)
 	}
 
-	// Sorting Logic
+	tasks = filterTasksByLabels(tasks, filter)
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+// Öncelik değerleri; Türkçe karşılıkları da kabul edilir.
+const (
+	PriorityHigh   = "High"
+	PriorityMedium = "Medium"
+	PriorityLow    = "Low"
+)
+
+// normalizePriority öncelik değerini (High, Yüksek, high ...) standart forma çevirir.
+// Tanınmayan değerler için false döner.
+func normalizePriority(p string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(p)) {
+	case "high", "yüksek", "yuksek":
+		return PriorityHigh, true
+	case "medium", "orta":
+		return PriorityMedium, true
+	case "low", "düşük", "dusuk":
+		return PriorityLow, true
+	case "":
+		return "", true
+	}
+	return "", false
+}
+
+// priorityWeight sıralama için öncelik ağırlığı (bilinmeyen değerler en sonda)
+func priorityWeight(p string) int {
+	normalized, _ := normalizePriority(p)
+	switch normalized {
+	case PriorityHigh:
+		return 3
+	case PriorityMedium:
+		return 2
+	case PriorityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortTasks görevleri önceliğe, sonra bitiş tarihine, sonra pozisyona göre sıralar.
+func sortTasks(tasks []Task) {
 	sort.SliceStable(tasks, func(i, j int) bool {
-		p1 := getPriorityWeight(tasks[i].Priority)
-		p2 := getPriorityWeight(tasks[j].Priority)
+		p1 := priorityWeight(tasks[i].Priority)
+		p2 := priorityWeight(tasks[j].Priority)
 
 		if p1 != p2 {
 			return p1 > p2 // Higher priority first
@@ -169,11 +310,6 @@ func GetTasks(w http.ResponseWriter, r *http.Request) {
 		// String comparison for ISO8601 dates works for ordering
 		return *d1 < *d2
 	})
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tasks); err != nil {
-		http.Error(w, "Veri yazma hatası", http.StatusInternalServerError)
-	}
 }
 
 // CreateTask yeni bir görev ekler.
@@ -198,6 +334,44 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	fmt.Printf("CreateTask Parsed Struct: %+v\n", task)
+	task.clearManaged()
+
+	board, err := loadBoard(token, task.BoardID)
+	if err != nil {
+		fmt.Println("CreateTask Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Atanan kişi verilmemişse panonun varsayılanı kullanılır
+	if task.AssignedTo == nil && board.Settings != nil && board.Settings.DefaultAssignee != "" {
+		assignee := board.Settings.DefaultAssignee
+		task.AssignedTo = &assignee
+	}
+
+	// Durum panonun sütunlarından biri olmalı; verilmezse ilk sütun kullanılır
+	workflow := board.effectiveWorkflow()
+	if task.Status == "" {
+		task.Status = firstStatus(workflow)
+	} else if task.Status, err = validateStatusMove(workflow, "", task.Status); err != nil {
+		writeStatusError(w, "CreateTask", err)
+		return
+	}
+
+	// WIP limitleri (sahip override_wip=true ile aşabilir)
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "board_id gerekli", http.StatusBadRequest)
+		return
+	}
+	entry := wipEntry{}
+	if task.AssignedTo != nil {
+		entry.Assignee = *task.AssignedTo
+	}
+	override, ok := enforceWIP(w, token, access, workflow, task.Status, []wipEntry{entry}, wipOverrideRequested(r))
+	if !ok {
+		return
+	}
 
 	// INSERT INTO tasks ...
 	resp, err := performSupabaseRequest("POST", "tasks", token, task)
@@ -207,6 +381,16 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if override != nil {
+		var created []Task
+		json.Unmarshal(resp, &created)
+		ids := make([]string, 0, len(created))
+		for _, t := range created {
+			ids = append(ids, t.ID)
+		}
+		recordWIPOverride(token, access, override, ids)
+	}
+
 	// Supabase return=representation ile array döner
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
@@ -231,6 +415,82 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Görev ID gerekli", http.StatusBadRequest)
 		return
 	}
+	task.clearManaged()
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	// Görevi başka bir panoya taşımak o panoda yetki gerektirir, burada izin vermiyoruz
+	if task.BoardID != "" && task.BoardID != access.BoardID {
+		http.Error(w, "Görev başka bir panoya taşınamaz", http.StatusBadRequest)
+		return
+	}
+	// Oluşturan ve pano güncellemeyle değişmez ("kendi görevini silme" izni oluşturana bağlı)
+	task.UserID, task.BoardID = "", ""
+
+	// Sütun değişikliği panonun geçiş kurallarına uymalı
+	var dependencyOverride *dependencyViolation
+	completing := false // görev tamamlanma sütununa giriyor
+	if task.Status != "" {
+		workflow, err := boardWorkflow(token, access.BoardID)
+		if err != nil {
+			fmt.Println("UpdateTask Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if task.Status, err = validateStatusMove(workflow, access.TaskStatus, task.Status); err != nil {
+			writeStatusError(w, "UpdateTask", err)
+			return
+		}
+		completing = task.Status != access.TaskStatus &&
+			workflow.Category(task.Status) == CategoryDone && workflow.Category(access.TaskStatus) != CategoryDone
+		// Açık engelleyicisi olan görev tamamlanamaz (sahip/yönetici override_dependencies=true ile aşabilir)
+		if completing {
+			if dependencyOverride, ok = enforceDependencies(w, token, access, workflow, task.Status, []string{task.ID}, dependencyOverrideRequested(r)); !ok {
+				return
+			}
+		}
+	}
+
+	// WIP limitleri: sütun değişiyorsa görev yeni sütuna girer; sadece atama değişiyorsa
+	// kişi başı limit kontrol edilir
+	var wipOverride *wipViolation
+	statusChanged := task.Status != "" && task.Status != access.TaskStatus
+	if statusChanged || task.AssignedTo != nil {
+		workflow, err := boardWorkflow(token, access.BoardID)
+		if err != nil {
+			fmt.Println("UpdateTask Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		target := access.TaskStatus
+		if statusChanged {
+			target = task.Status
+		}
+		if column, ok := workflow.Status(target); ok && (column.WIPLimit > 0 || column.AssigneeWIPLimit > 0) {
+			current, err := taskAssignee(token, task.ID)
+			if err != nil {
+				fmt.Println("UpdateTask Hatası:", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			entry := wipEntry{TaskID: task.ID, Assignee: current}
+			if task.AssignedTo != nil {
+				entry.Assignee = *task.AssignedTo
+			}
+			entry.Reassigned = !statusChanged
+			if statusChanged || entry.Assignee != current {
+				override, ok := enforceWIP(w, token, access, workflow, target, []wipEntry{entry}, wipOverrideRequested(r))
+				if !ok {
+					return
+				}
+				wipOverride = override
+			}
+		}
+	}
 
 	// UPDATE tasks SET ... WHERE id = ...
 	endpoint := fmt.Sprintf("tasks?id=eq.%s", task.ID)
@@ -240,11 +500,33 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	recordWIPOverride(token, access, wipOverride, []string{task.ID})
+	recordDependencyOverride(access, dependencyOverride)
+	if completing {
+		// Tekrarlayan serinin son örneği tamamlandıysa sonraki tekrar hemen oluşturulur
+		go completeRecurringTasks(token, []string{task.ID})
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
 }
 
+// taskAssignee görevin atanan kişisini döndürür (atanmamışsa boş).
+func taskAssignee(token, taskID string) (string, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("tasks?id=eq.%s&select=assigned_to", taskID), token, nil)
+	if err != nil {
+		return "", err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		return "", err
+	}
+	if len(tasks) == 0 || tasks[0].AssignedTo == nil {
+		return "", nil
+	}
+	return *tasks[0].AssignedTo, nil
+}
+
 // DeleteTask görevi siler.
 func DeleteTask(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
@@ -260,6 +542,14 @@ func DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Ek kayıtları cascade ile silinir; dosyaları depodan silmek için anahtarlar önceden alınır
+	attachments, err := attachmentKeys(token, fmt.Sprintf("task_id=eq.%s", taskID))
+	if err != nil {
+		fmt.Println("DeleteTask Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// DELETE FROM tasks WHERE id = ...
 	endpoint := fmt.Sprintf("tasks?id=eq.%s", taskID)
 	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
@@ -268,6 +558,7 @@ func DeleteTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	purgeAttachments(attachments)
 
 	// Standart Go-Panel yanıtına uymak için
 	w.WriteHeader(http.StatusOK)
@@ -289,14 +580,29 @@ func DeleteTasksByStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// DELETE FROM tasks WHERE status = ...
-	endpoint := fmt.Sprintf("tasks?status=eq.%s", status)
+	// Yetki kontrolü RequireBoardPermission ile yapıldı; silme sadece o panoyla sınırlı
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "board_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := attachmentKeys(token, fmt.Sprintf("board_id=eq.%s&tasks.status=eq.%s", access.BoardID, status))
+	if err != nil {
+		fmt.Println("DeleteTasksByStatus Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// DELETE FROM tasks WHERE board_id = ... AND status = ...
+	endpoint := fmt.Sprintf("tasks?board_id=eq.%s&status=eq.%s", access.BoardID, status)
 	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
 	if err != nil {
 		fmt.Println("DeleteTasksByStatus Hatası:", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	purgeAttachments(attachments)
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Görevler silindi", "details": string(resp)})
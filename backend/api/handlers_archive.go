@@ -0,0 +1,598 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Pano arşivi biçimi. Alan eklemek geriye uyumludur; anlamı değişen her değişiklikte sürüm artırılır.
+const (
+	BoardArchiveFormat  = "go-panel/board-archive"
+	BoardArchiveVersion = 1
+
+	// maxArchiveSize içe aktarılabilecek arşivin en büyük boyutu
+	maxArchiveSize = 20 << 20
+)
+
+// BoardArchive panonun tüm içeriğini taşıyan JSON arşivi.
+// Kullanıcılar ID yerine e-posta ile tutulur; içe aktarmada hedef sistemdeki hesaplara eşlenir.
+type BoardArchive struct {
+	Format     string           `json:"format"`
+	Version    int              `json:"version"`
+	ExportedAt string           `json:"exported_at"`
+	Board      ArchiveBoard     `json:"board"`
+	Labels     []TemplateLabel  `json:"labels"`
+	Members    []ArchiveMember  `json:"members"`
+	Tasks      []ArchiveTask    `json:"tasks"`
+	Messages   []ArchiveMessage `json:"messages"`
+}
+
+// ArchiveBoard arşivdeki pano bilgisi
+type ArchiveBoard struct {
+	ID         string         `json:"id"`
+	Title      string         `json:"title"`
+	Type       string         `json:"type"`
+	OwnerEmail string         `json:"owner_email,omitempty"`
+	CreatedAt  string         `json:"created_at,omitempty"`
+	Workflow   Workflow       `json:"workflow"`
+	Settings   *BoardSettings `json:"settings,omitempty"`
+}
+
+// ArchiveMember arşivdeki pano üyesi
+type ArchiveMember struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// ArchiveTask arşivdeki görev; ID içe aktarmada yeni ID'ye eşlenir.
+type ArchiveTask struct {
+	ID            string           `json:"id"`
+	Title         string           `json:"title"`
+	Description   *string          `json:"description,omitempty"`
+	Status        string           `json:"status"`
+	Priority      string           `json:"priority,omitempty"`
+	DueDate       *string          `json:"due_date,omitempty"`
+	Position      int              `json:"position"`
+	CreatorEmail  string           `json:"creator_email,omitempty"`
+	AssigneeEmail string           `json:"assignee_email,omitempty"`
+	Subtasks      []ArchiveSubtask `json:"subtasks,omitempty"`
+}
+
+// ArchiveSubtask arşivdeki alt görev
+type ArchiveSubtask struct {
+	Title       string `json:"title"`
+	IsCompleted bool   `json:"is_completed"`
+	Position    int    `json:"position"`
+}
+
+// ArchiveMessage arşivdeki sohbet mesajı
+type ArchiveMessage struct {
+	SenderEmail string `json:"sender_email,omitempty"`
+	Content     string `json:"content"`
+	CreatedAt   string `json:"created_at,omitempty"`
+}
+
+// ImportReport içe aktarma doğrulama raporu (dry_run) ve sonucu
+type ImportReport struct {
+	Valid            bool              `json:"valid"`
+	DryRun           bool              `json:"dry_run"`
+	Title            string            `json:"title"`
+	Errors           []string          `json:"errors"`
+	Warnings         []string          `json:"warnings"`
+	UnresolvedEmails []string          `json:"unresolved_emails"`
+	PendingMembers   []ArchiveMember   `json:"pending_members"` // arşivdeki üyeler; otomatik eklenmez, davet edilebilir
+	Counts           map[string]int    `json:"counts"`
+	Result           *CloneBoardResult `json:"result,omitempty"`
+}
+
+func (r *ImportReport) errorf(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *ImportReport) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ExportBoard panoyu görevleri, alt görevleri, üye e-postaları ve sohbet geçmişiyle birlikte
+// sürümlü bir JSON arşivi olarak indirir.
+func ExportBoard(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	archive, err := buildBoardArchive(token, access.BoardID)
+	if err != nil {
+		fmt.Println("ExportBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="board-%s.json"`, access.BoardID))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(archive)
+}
+
+// buildBoardArchive arşivi oluşturmak için panonun tüm içeriğini yükler.
+func buildBoardArchive(token, boardID string) (*BoardArchive, error) {
+	snap, err := loadBoardSnapshot(token, boardID, CloneBoardRequest{CopySettings: true})
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &BoardArchive{
+		Format:     BoardArchiveFormat,
+		Version:    BoardArchiveVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Board: ArchiveBoard{
+			ID:        snap.Board.ID,
+			Title:     snap.Board.Title,
+			Type:      snap.Board.Type,
+			CreatedAt: snap.Board.CreatedAt,
+			Workflow:  *snap.Board.Workflow,
+			Settings:  snap.Board.Settings,
+		},
+		Labels:   snap.Labels,
+		Members:  []ArchiveMember{},
+		Tasks:    []ArchiveTask{},
+		Messages: []ArchiveMessage{},
+	}
+	if archive.Labels == nil {
+		archive.Labels = []TemplateLabel{}
+	}
+
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("profiles?id=eq.%s&select=email", snap.Board.UserID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var owners []Profile
+	if err := json.Unmarshal(resp, &owners); err == nil && len(owners) > 0 {
+		archive.Board.OwnerEmail = owners[0].Email
+	}
+
+	resp, err = performSupabaseRequest("GET", fmt.Sprintf("board_members?board_id=eq.%s&select=role,profiles(email)", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var members []struct {
+		Role    string   `json:"role"`
+		Profile *Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal(resp, &members); err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if m.Profile == nil || m.Profile.Email == "" {
+			continue
+		}
+		archive.Members = append(archive.Members, ArchiveMember{Email: m.Profile.Email, Role: m.Role})
+	}
+
+	endpoint := fmt.Sprintf("tasks?board_id=eq.%s&select=*,subtasks(*),profiles!user_id(email),assignees:profiles!assigned_to(email)&order=position.asc", boardID)
+	resp, err = performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		at := ArchiveTask{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			Priority:    t.Priority,
+			DueDate:     t.DueDate,
+			Position:    t.Position,
+		}
+		if t.Profile != nil {
+			at.CreatorEmail = t.Profile.Email
+		}
+		if t.Assignee != nil {
+			at.AssigneeEmail = t.Assignee.Email
+		}
+		for _, st := range t.Subtasks {
+			at.Subtasks = append(at.Subtasks, ArchiveSubtask{Title: st.Title, IsCompleted: st.IsCompleted, Position: st.Position})
+		}
+		archive.Tasks = append(archive.Tasks, at)
+	}
+
+	resp, err = performSupabaseRequest("GET", fmt.Sprintf("messages?board_id=eq.%s&select=sender_email,content,created_at&order=created_at.asc", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(resp, &archive.Messages); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// ImportBoard arşivden çağıranın hesabı altında yeni bir pano oluşturur.
+// Görev ID'leri yeniden üretilir (eşleme sonuçta döner). Arşivdeki üyeler eklenmez, raporda
+// pending_members olarak döner; mesajlar içe aktaran adına eklenir. ?dry_run=true yalnızca
+// doğrulama raporu döndürür.
+func ImportBoard(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+
+	var archive BoardArchive
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxArchiveSize)).Decode(&archive); err != nil {
+		http.Error(w, "Geçersiz arşiv: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if title := strings.TrimSpace(r.URL.Query().Get("title")); title != "" {
+		archive.Board.Title = title
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	users, err := resolveArchiveUsers(token, &archive)
+	if err != nil {
+		fmt.Println("ImportBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report, snap := prepareImport(&archive, users, principal.UserID)
+	report.DryRun = dryRun
+	if dryRun || !report.Valid {
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Valid {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	req := CloneBoardRequest{
+		Title:            archive.Board.Title,
+		CopyTasks:        true,
+		CopySubtasks:     true,
+		CopyAssignments:  true,
+		CopySettings:     true,
+		keepSubtaskState: true,
+	}
+	messages := archiveMessageRows(archive.Messages, principal.UserID)
+
+	steps := snap.steps(req) + len(messages)
+	if steps <= cloneAsyncThreshold {
+		result, err := importBoardArchive(token, principal.UserID, snap, req, messages, nil)
+		if err != nil {
+			fmt.Println("ImportBoard Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report.Result = result
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	jobTok, err := jobToken(principal, token)
+	if err != nil {
+		fmt.Println("ImportBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	job := jobs.create("import_board", principal.UserID)
+	job.SetTotal(steps)
+	go func() {
+		result, err := importBoardArchive(jobTok, principal.UserID, snap, req, messages, job)
+		if err != nil {
+			fmt.Println("ImportBoard (arka plan) Hatası:", err)
+		} else {
+			report.Result = result
+		}
+		job.Finish(report, err)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// resolveArchiveUsers arşivde geçen e-postaları profillerden kullanıcı ID'lerine eşler.
+// Anahtarlar küçük harfe çevrilmiş e-postalardır.
+func resolveArchiveUsers(token string, archive *BoardArchive) (map[string]string, error) {
+	seen := make(map[string]bool)
+	var emails []string
+	add := func(email string) {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email != "" && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	add(archive.Board.OwnerEmail)
+	for _, m := range archive.Members {
+		add(m.Email)
+	}
+	for _, t := range archive.Tasks {
+		add(t.AssigneeEmail)
+	}
+	return lookupUsersByEmail(token, emails)
+}
+
+// lookupUsersByEmail e-posta -> kullanıcı ID eşlemesi döndürür (URL uzunluğu için parça parça sorgular).
+func lookupUsersByEmail(token string, emails []string) (map[string]string, error) {
+	users := make(map[string]string, len(emails))
+	for start := 0; start < len(emails); start += cloneBatchSize {
+		end := start + cloneBatchSize
+		if end > len(emails) {
+			end = len(emails)
+		}
+		quoted := make([]string, 0, end-start)
+		for _, e := range emails[start:end] {
+			quoted = append(quoted, `"`+strings.ReplaceAll(e, `"`, ``)+`"`)
+		}
+		filter := url.QueryEscape("in.(" + strings.Join(quoted, ",") + ")")
+		resp, err := performSupabaseRequest("GET", "profiles?select=id,email&email="+filter, token, nil)
+		if err != nil {
+			return nil, err
+		}
+		var profiles []struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+		}
+		if err := json.Unmarshal(resp, &profiles); err != nil {
+			return nil, err
+		}
+		for _, p := range profiles {
+			users[strings.ToLower(p.Email)] = p.ID
+		}
+	}
+	return users, nil
+}
+
+// prepareImport arşivi doğrular ve yazılacak pano içeriğine çevirir.
+func prepareImport(archive *BoardArchive, users map[string]string, importerID string) (*ImportReport, *boardSnapshot) {
+	report := &ImportReport{
+		Title:            archive.Board.Title,
+		Errors:           []string{},
+		Warnings:         []string{},
+		UnresolvedEmails: []string{},
+		PendingMembers:   []ArchiveMember{},
+		Counts:           map[string]int{},
+	}
+	unresolved := make(map[string]bool)
+	resolve := func(email string) (string, bool) {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			return "", false
+		}
+		id, ok := users[email]
+		if !ok && !unresolved[email] {
+			unresolved[email] = true
+			report.UnresolvedEmails = append(report.UnresolvedEmails, email)
+		}
+		return id, ok
+	}
+
+	if archive.Format != BoardArchiveFormat {
+		report.errorf("Desteklenmeyen arşiv biçimi: %q", archive.Format)
+	}
+	if archive.Version < 1 || archive.Version > BoardArchiveVersion {
+		report.errorf("Desteklenmeyen arşiv sürümü: %d (en fazla %d)", archive.Version, BoardArchiveVersion)
+	}
+	if len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	if strings.TrimSpace(archive.Board.Title) == "" {
+		report.errorf("board.title boş olamaz")
+	}
+
+	workflow := archive.Board.Workflow
+	if len(workflow.Statuses) == 0 {
+		workflow = effectiveWorkflow(archive.Board.Type, workflow)
+		report.warnf("Arşivde sütun tanımı yok; %q tipinin varsayılan sütunları kullanılacak", archive.Board.Type)
+	} else if err := workflow.validate(); err != nil {
+		report.errorf("board.workflow: %v", err)
+	}
+
+	snap := &boardSnapshot{
+		Board: Board{
+			Title:    archive.Board.Title,
+			Type:     archive.Board.Type,
+			UserID:   importerID,
+			Workflow: &workflow,
+			Settings: archive.Board.Settings,
+		},
+	}
+
+	labelNames := make(map[string]bool)
+	for i, l := range archive.Labels {
+		name := strings.TrimSpace(l.Name)
+		switch {
+		case name == "":
+			report.errorf("labels[%d]: isim boş olamaz", i)
+		case labelNames[name]:
+			report.errorf("labels[%d]: etiket tekrar ediyor: %s", i, name)
+		default:
+			labelNames[name] = true
+			color := l.Color
+			if color == "" {
+				color = "#6b7280"
+			}
+			snap.Labels = append(snap.Labels, TemplateLabel{Name: name, Color: color})
+		}
+	}
+
+	// Arşivdeki kişiler onayları olmadan panoya eklenmez: yeni panonun tek üyesi içe aktarandır.
+	// Orijinal sahip ve üyeler pending_members'ta listelenir, davet linkiyle katılabilirler.
+	pending := make(map[string]bool)
+	addPending := func(email, role string) {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" || pending[email] || users[email] == importerID {
+			return
+		}
+		pending[email] = true
+		report.PendingMembers = append(report.PendingMembers, ArchiveMember{Email: email, Role: role})
+	}
+	addPending(archive.Board.OwnerEmail, RoleAdmin)
+	for i, m := range archive.Members {
+		role := m.Role
+		if !ValidMemberRole(role) {
+			report.warnf("members[%d]: geçersiz rol %q, %q olarak önerilecek", i, m.Role, RoleMember)
+			role = RoleMember
+		}
+		addPending(m.Email, role)
+	}
+	if len(report.PendingMembers) > 0 {
+		report.warnf("%d kişi panoya otomatik eklenmeyecek; davet linkiyle (/api/boards/invites) katılabilirler", len(report.PendingMembers))
+	}
+
+	// Atamalar yalnızca yeni panoda erişimi olan kullanıcılar (içe aktaran) için korunur
+	access := map[string]bool{importerID: true}
+
+	taskIDs := make(map[string]bool)
+	subtasks := 0
+	for i, t := range archive.Tasks {
+		if strings.TrimSpace(t.Title) == "" {
+			report.errorf("tasks[%d]: başlık boş olamaz", i)
+		}
+		if !workflow.Has(t.Status) {
+			report.errorf("tasks[%d]: tanımsız sütun %q", i, t.Status)
+		}
+		if t.ID != "" {
+			if taskIDs[t.ID] {
+				report.errorf("tasks[%d]: görev ID tekrar ediyor: %s", i, t.ID)
+			}
+			taskIDs[t.ID] = true
+		}
+		if t.DueDate != nil && *t.DueDate != "" && !validDate(*t.DueDate) {
+			report.errorf("tasks[%d]: geçersiz bitiş tarihi %q", i, *t.DueDate)
+		}
+
+		task := Task{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			Priority:    t.Priority,
+			DueDate:     t.DueDate,
+			Position:    t.Position,
+		}
+		if t.AssigneeEmail != "" {
+			if id, ok := resolve(t.AssigneeEmail); ok && access[id] {
+				task.AssignedTo = &id
+			} else if ok {
+				report.warnf("tasks[%d]: atanan kişi (%s) panonun üyesi değil, atama boş kalacak", i, t.AssigneeEmail)
+			} else {
+				report.warnf("tasks[%d]: atanan kişi bulunamadı (%s), atama boş kalacak", i, t.AssigneeEmail)
+			}
+		}
+		for j, st := range t.Subtasks {
+			if strings.TrimSpace(st.Title) == "" {
+				report.errorf("tasks[%d].subtasks[%d]: başlık boş olamaz", i, j)
+			}
+			task.Subtasks = append(task.Subtasks, Subtask{Title: st.Title, IsCompleted: st.IsCompleted, Position: st.Position})
+		}
+		subtasks += len(t.Subtasks)
+		snap.Tasks = append(snap.Tasks, task)
+	}
+
+	for i, m := range archive.Messages {
+		if strings.TrimSpace(m.Content) == "" {
+			report.errorf("messages[%d]: içerik boş olamaz", i)
+		}
+	}
+	if len(report.UnresolvedEmails) > 0 {
+		report.warnf("%d e-posta bu sistemde kayıtlı değil; atamaları atlanacak", len(report.UnresolvedEmails))
+	}
+	if len(archive.Messages) > 0 {
+		report.warnf("Sohbet geçmişi içe aktaran adına eklenecek; orijinal gönderen e-postası gösterim için korunur")
+	}
+
+	report.Counts["labels"] = len(snap.Labels)
+	report.Counts["pending_members"] = len(report.PendingMembers)
+	report.Counts["tasks"] = len(snap.Tasks)
+	report.Counts["subtasks"] = subtasks
+	report.Counts["messages"] = len(archive.Messages)
+	report.Valid = len(report.Errors) == 0
+	return report, snap
+}
+
+// validDate YYYY-MM-DD veya RFC3339 tarihlerini kabul eder.
+func validDate(value string) bool {
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// archiveMessageRows mesajları messages tablosu satırlarına çevirir.
+// Başkası adına mesaj yazılamaz: tüm mesajlar içe aktaran adına eklenir, orijinal gönderenin
+// e-postası sadece gösterim için sender_email'de kalır.
+func archiveMessageRows(messages []ArchiveMessage, importerID string) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(messages))
+	for _, m := range messages {
+		row := map[string]interface{}{
+			"user_id":      importerID,
+			"sender_email": m.SenderEmail,
+			"content":      m.Content,
+		}
+		if m.CreatedAt != "" {
+			row["created_at"] = m.CreatedAt
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// importBoardArchive panoyu yazar ve sohbet geçmişini ekler; mesajlar eklenemezse pano silinir.
+func importBoardArchive(token, ownerID string, snap *boardSnapshot, req CloneBoardRequest, messages []map[string]interface{}, job *Job) (*CloneBoardResult, error) {
+	result, err := writeBoardSnapshot(token, ownerID, snap, req, job)
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(messages); start += cloneBatchSize {
+		end := start + cloneBatchSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+		batch := messages[start:end]
+		for _, m := range batch {
+			m["board_id"] = result.Board.ID
+		}
+		if _, err := performSupabaseRequest("POST", "messages", token, batch); err != nil {
+			performSupabaseRequest("DELETE", fmt.Sprintf("boards?id=eq.%s", result.Board.ID), token, nil)
+			return nil, err
+		}
+		if job != nil {
+			job.Advance(len(batch))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,436 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-panel/backend/storage"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultMaxAttachmentSize = 25 << 20 // ATTACHMENT_MAX_BYTES ile değiştirilebilir
+	maxAttachmentsPerTask    = 50
+	maxAttachmentNameLength  = 255
+	attachmentURLTTL         = 15 * time.Minute
+	attachmentFilesPath      = "/api/files" // yerel depolamada imzalı linklerin sunulduğu yol
+)
+
+const attachmentColumns = "id,task_id,board_id,user_id,filename,content_type,size_bytes,created_at,uploader:profiles!user_id(email)"
+
+// blockedAttachmentTypes tarayıcıda betik çalıştırabilecek içerikler (uzantıdan bağımsız, içerikten tespit edilir)
+var blockedAttachmentTypes = []string{"text/html", "text/xml"}
+
+// inlineAttachmentTypes tarayıcıda indirmeden gösterilebilecek içerikler
+var inlineAttachmentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"}
+
+// Attachment görev eki (içerik blob deposunda, meta veri task_attachments'ta)
+type Attachment struct {
+	ID          string   `json:"id"`
+	TaskID      string   `json:"task_id"`
+	BoardID     string   `json:"board_id"`
+	UserID      string   `json:"user_id"`
+	Filename    string   `json:"filename"`
+	ContentType string   `json:"content_type"`
+	SizeBytes   int64    `json:"size_bytes"`
+	StorageKey  string   `json:"-"`
+	CreatedAt   string   `json:"created_at"`
+	Uploader    *Profile `json:"uploader,omitempty"`
+}
+
+var (
+	attachmentStoreOnce sync.Once
+	attachmentStoreInst storage.Store
+	attachmentStoreErr  error
+)
+
+// attachmentStore ortam değişkenlerine göre blob deposunu ilk kullanımda oluşturur.
+func attachmentStore() (storage.Store, error) {
+	attachmentStoreOnce.Do(func() {
+		attachmentStoreInst, attachmentStoreErr = storage.NewFromEnv(attachmentFilesPath)
+		if attachmentStoreErr != nil {
+			log.Printf("Dosya deposu başlatılamadı: %v", attachmentStoreErr)
+		}
+	})
+	return attachmentStoreInst, attachmentStoreErr
+}
+
+// maxAttachmentSize tek bir dosyanın en büyük boyutu
+func maxAttachmentSize() int64 {
+	if v, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_BYTES"), 10, 64); err == nil && v > 0 {
+		return v
+	}
+	return defaultMaxAttachmentSize
+}
+
+// sniffAttachmentType içeriğin gerçek MIME tipini tespit eder; tehlikeli tipler için false döner.
+func sniffAttachmentType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	base := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	return contentType, !containsString(blockedAttachmentTypes, base)
+}
+
+// attachmentInline ekin tarayıcıda gösterilip gösterilmeyeceği
+func attachmentInline(contentType string) bool {
+	return containsString(inlineAttachmentTypes, strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// sanitizeAttachmentName istemcinin verdiği dosya adından dizin kısmını ve kontrol karakterlerini atar.
+func sanitizeAttachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == "/" {
+		name = "dosya"
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentNameLength {
+		name = string(runes[len(runes)-maxAttachmentNameLength:]) // uzantı korunur
+	}
+	return name
+}
+
+// newAttachmentKey ekin depodaki anahtarı: <pano>/<görev>/<rastgele>
+func newAttachmentKey(boardID, taskID string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/%s", boardID, taskID, hex.EncodeToString(buf)), nil
+}
+
+// loadAttachment tek bir eki depolama anahtarıyla birlikte yükler.
+func loadAttachment(token, attachmentID string) (*Attachment, error) {
+	endpoint := fmt.Sprintf("task_attachments?id=eq.%s&select=%s,storage_key", attachmentID, attachmentColumns)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Attachment
+		StorageKey string `json:"storage_key"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errBoardNotFound
+	}
+	a := rows[0].Attachment
+	a.StorageKey = rows[0].StorageKey
+	return &a, nil
+}
+
+// attachmentKeys filtreye uyan eklerin depolama anahtarları (silmeden önce toplanır).
+// Görev kolonlarına tasks.<kolon> ile filtre uygulanabilir (ör. tasks.status=eq.done).
+func attachmentKeys(token, filter string) ([]string, error) {
+	resp, err := performSupabaseRequest("GET", "task_attachments?select=storage_key,tasks!inner(status)&"+filter, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		StorageKey string `json:"storage_key"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(rows))
+	for _, r := range rows {
+		keys = append(keys, r.StorageKey)
+	}
+	return keys, nil
+}
+
+// purgeAttachments kayıtları silinmiş eklerin dosyalarını arka planda depodan siler.
+func purgeAttachments(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	store, err := attachmentStore()
+	if err != nil {
+		return
+	}
+	go func() {
+		for _, key := range keys {
+			if err := store.Delete(context.Background(), key); err != nil {
+				log.Printf("Ek dosyası silinemedi (%s): %v", key, err)
+			}
+		}
+	}()
+}
+
+// GetTaskAttachments görevin eklerini yüklenme sırasıyla listeler (?task_id=).
+func GetTaskAttachments(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	taskID := r.URL.Query().Get("task_id")
+	endpoint := fmt.Sprintf("task_attachments?task_id=eq.%s&select=%s&order=created_at.asc", taskID, attachmentColumns)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetTaskAttachments Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// UploadTaskAttachment göreve dosya ekler (?task_id=, multipart "file" alanı). Boyut sınırı
+// ATTACHMENT_MAX_BYTES'tır; içerik tipi istemcinin beyanından değil içerikten tespit edilir.
+func UploadTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+	taskID := r.URL.Query().Get("task_id")
+
+	store, err := attachmentStore()
+	if err != nil {
+		http.Error(w, "Dosya deposu yapılandırılmamış", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Görev başına ek sayısı sınırı
+	existing, err := attachmentKeys(token, fmt.Sprintf("task_id=eq.%s", taskID))
+	if err != nil {
+		fmt.Println("UploadTaskAttachment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(existing) >= maxAttachmentsPerTask {
+		http.Error(w, fmt.Sprintf("Bir göreve en fazla %d dosya eklenebilir", maxAttachmentsPerTask), http.StatusConflict)
+		return
+	}
+
+	limit := maxAttachmentSize()
+	r.Body = http.MaxBytesReader(w, r.Body, limit+1<<20) // multipart başlıkları için pay
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "multipart/form-data bekleniyor", http.StatusBadRequest)
+		return
+	}
+
+	var filename string
+	var data []byte
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeUploadError(w, err, limit)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		filename = sanitizeAttachmentName(part.FileName())
+		data, err = io.ReadAll(io.LimitReader(part, limit+1))
+		part.Close()
+		if err != nil {
+			writeUploadError(w, err, limit)
+			return
+		}
+		break
+	}
+	if data == nil {
+		http.Error(w, "file alanı gerekli", http.StatusBadRequest)
+		return
+	}
+	if int64(len(data)) > limit {
+		writeUploadError(w, &http.MaxBytesError{Limit: limit}, limit)
+		return
+	}
+	if len(data) == 0 {
+		http.Error(w, "Dosya boş", http.StatusBadRequest)
+		return
+	}
+
+	contentType, allowed := sniffAttachmentType(data)
+	if !allowed {
+		http.Error(w, "Bu dosya türü yüklenemez ("+contentType+")", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	key, err := newAttachmentKey(access.BoardID, taskID)
+	if err != nil {
+		http.Error(w, "Dosya anahtarı üretilemedi", http.StatusInternalServerError)
+		return
+	}
+	if err := store.Put(r.Context(), key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		fmt.Println("UploadTaskAttachment Hatası:", err)
+		http.Error(w, "Dosya kaydedilemedi", http.StatusBadGateway)
+		return
+	}
+
+	row := map[string]interface{}{
+		"task_id":      taskID,
+		"board_id":     access.BoardID,
+		"user_id":      access.UserID,
+		"filename":     filename,
+		"content_type": contentType,
+		"size_bytes":   len(data),
+		"storage_key":  key,
+	}
+	resp, err := performSupabaseRequest("POST", "task_attachments?select="+attachmentColumns, token, row)
+	if err != nil {
+		purgeAttachments([]string{key}) // kaydı olmayan dosya kalmasın
+		fmt.Println("UploadTaskAttachment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var created []Attachment
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "attachment_added", created[0])
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created[0])
+}
+
+// writeUploadError boyut aşımında 413, diğer okuma hatalarında 400 döner.
+func writeUploadError(w http.ResponseWriter, err error, limit int64) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("Dosya en fazla %d MB olabilir", limit>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "Dosya okunamadı", http.StatusBadRequest)
+}
+
+// DownloadTaskAttachment ek için süreli, imzalı bir indirme linki döndürür (?id=).
+// redirect=true ile doğrudan linke yönlendirir.
+func DownloadTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	attachment, err := loadAttachment(token, r.URL.Query().Get("id"))
+	if err != nil {
+		fmt.Println("DownloadTaskAttachment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	store, err := attachmentStore()
+	if err != nil {
+		http.Error(w, "Dosya deposu yapılandırılmamış", http.StatusServiceUnavailable)
+		return
+	}
+
+	expiresAt := time.Now().Add(attachmentURLTTL)
+	url, err := store.SignedURL(attachment.StorageKey, storage.URLOptions{
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Inline:      attachmentInline(attachment.ContentType),
+		TTL:         attachmentURLTTL,
+	})
+	if err != nil {
+		fmt.Println("DownloadTaskAttachment Hatası:", err)
+		http.Error(w, "İndirme linki üretilemedi", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("redirect") == "true" {
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{
+		"url":        url,
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// DeleteTaskAttachment eki siler (?id=). Yükleyen kendi ekini, sahip ve yöneticiler her eki silebilir.
+func DeleteTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Ekin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := loadAttachment(token, r.URL.Query().Get("id"))
+	if err != nil {
+		fmt.Println("DeleteTaskAttachment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if attachment.UserID != access.UserID && !RoleAllows(access.Role, PermDeleteTasks) {
+		http.Error(w, "Bu dosyayı silme yetkiniz yok", http.StatusForbidden)
+		return
+	}
+
+	if _, err := performSupabaseRequest("DELETE", fmt.Sprintf("task_attachments?id=eq.%s", attachment.ID), token, nil); err != nil {
+		fmt.Println("DeleteTaskAttachment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	purgeAttachments([]string{attachment.StorageKey})
+
+	GlobalHub.Publish(access.BoardID, "attachment_deleted", map[string]interface{}{
+		"id":         attachment.ID,
+		"task_id":    attachment.TaskID,
+		"deleted_by": access.UserID,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeAttachmentFile yerel depolamadaki dosyaları imzalı linkle sunar (oturum gerekmez).
+// S3 kullanılırken linkler doğrudan bucket'a gittiği için bu rota 404 döner.
+func ServeAttachmentFile(w http.ResponseWriter, r *http.Request) {
+	store, err := attachmentStore()
+	local, ok := store.(*storage.LocalStore)
+	if err != nil || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	local.ServeFile(w, r, chi.URLParam(r, "*"))
+}
@@ -6,15 +6,32 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type Board struct {
-	ID         string `json:"id,omitempty"`
-	Title      string `json:"title"`
-	Type       string `json:"type"` // standard, professional, smart, minimal
-	UserID     string `json:"user_id,omitempty"`
-	InviteCode string `json:"invite_code,omitempty"`
-	CreatedAt  string `json:"created_at,omitempty"`
+	ID          string         `json:"id,omitempty"`
+	Title       string         `json:"title"`
+	Type        string         `json:"type"` // standard, professional, smart, minimal, custom
+	Description *string        `json:"description,omitempty"`
+	Color       *string        `json:"color,omitempty"`
+	UserID      string         `json:"user_id,omitempty"`
+	WorkspaceID *string        `json:"workspace_id,omitempty"`
+	InviteCode  string         `json:"invite_code,omitempty"`
+	CreatedAt   string         `json:"created_at,omitempty"`
+	ArchivedAt  *string        `json:"archived_at,omitempty"` // doluysa pano arşivlenmiş (salt okunur)
+	Workflow    *Workflow      `json:"workflow,omitempty"`
+	Settings    *BoardSettings `json:"settings,omitempty"`
+}
+
+// CreateBoardRequest pano oluşturma isteği.
+// Type hazır şablonlardan birini seçer; TemplateID verilirse kullanıcının kayıtlı şablonu uygulanır.
+type CreateBoardRequest struct {
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	TemplateID  string `json:"template_id,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"` // boşsa kişisel çalışma alanı
 }
 
 // JoinBoardRequest panoya katılma isteği
@@ -22,7 +39,25 @@ type JoinBoardRequest struct {
 	InviteCode string `json:"invite_code"`
 }
 
+// JoinBoardResult redeem_board_invite RPC sonucu
+type JoinBoardResult struct {
+	Status   string  `json:"status"` // joined, already_member, invalid
+	BoardID  string  `json:"board_id,omitempty"`
+	Role     string  `json:"role,omitempty"`
+	InviteID *string `json:"invite_id,omitempty"`
+}
+
+// UpdateMemberRoleRequest üye rolü değiştirme isteği
+type UpdateMemberRoleRequest struct {
+	BoardID string `json:"board_id"`
+	UserID  string `json:"user_id"`
+	Role    string `json:"role"`
+}
+
 // GetBoards kullanıcının panolarını getirir.
+// Arşivlenmiş panolar varsayılan olarak gelmez; ?archived=true sadece arşivdekileri, ?archived=all hepsini döndürür.
+// ?workspace_id tek bir çalışma alanıyla sınırlar, ?group_by=workspace panoları çalışma alanlarına göre gruplar.
+// Her panoya kullanıcının tercihleri ("preferences") eklenir ve sıralama bu tercihlere göre yapılır.
 func GetBoards(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if len(authHeader) < 8 {
@@ -31,19 +66,79 @@ func GetBoards(w http.ResponseWriter, r *http.Request) {
 	}
 	token := authHeader[7:]
 
-	// RLS sayesinde hem sahip olduğu hem üye olduğu panolar gelir
-	resp, err := performSupabaseRequest("GET", "boards?select=*&order=created_at.desc", token, nil)
+	endpoint := "boards?select=*&order=created_at.desc"
+	switch r.URL.Query().Get("archived") {
+	case "", "false":
+		endpoint += "&archived_at=is.null"
+	case "true":
+		endpoint += "&archived_at=not.is.null"
+	case "all":
+	default:
+		http.Error(w, "archived parametresi true, false veya all olmalı", http.StatusBadRequest)
+		return
+	}
+
+	if workspaceID := r.URL.Query().Get("workspace_id"); workspaceID != "" {
+		endpoint += "&workspace_id=eq." + workspaceID
+	}
+
+	// RLS sayesinde sahip olduğu, üye olduğu ve çalışma alanları üzerinden erişebildiği panolar gelir
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetBoards Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+	var boards []json.RawMessage
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	// Kişisel tercihler: sabitlenenler önce, elle sıralama, gizlenenler (?include_hidden=true değilse) hariç
+	prefs, err := loadBoardPreferences(token, principal.UserID)
 	if err != nil {
 		fmt.Println("GetBoards Hatası:", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	boards, err = applyBoardPreferences(boards, prefs, r.URL.Query().Get("include_hidden") == "true")
+	if err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Write(resp)
+	if r.URL.Query().Get("group_by") != "workspace" {
+		json.NewEncoder(w).Encode(boards)
+		return
+	}
+
+	// group_by=workspace: [{workspace, boards}] (kişisel alan önce)
+	workspaces, err := loadWorkspaces(token, principal.UserID)
+	if err != nil {
+		fmt.Println("GetBoards Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	groups, err := groupBoardsByWorkspace(boards, workspaces)
+	if err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(groups)
 }
 
-// JoinBoard davet kodu ile panoya kullanıcı ekler
+// joinFailures davet kodu denemelerinde başarısız girişimleri sınırlar (kod tahminine karşı)
+var joinFailures = newRateLimiter(10, 15*time.Minute)
+
+// JoinBoard davet kodu (davet linki veya panonun varsayılan kodu) ile panoya kullanıcı ekler
 func JoinBoard(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if len(authHeader) < 8 {
@@ -51,9 +146,6 @@ func JoinBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	token := authHeader[7:]
-	// User ID'yi context'ten veya token'dan almamız lazım ama Supabase insert sırasında auth.uid()'yi kullanır (RLS varsa).
-	// Fakat board_members tablosuna insert yaparken user_id'yi manuel vermemiz gerekiyorsa token'dan parse etmeliyiz.
-	// Neyse ki db.Client.Auth.User ile middleware'de aldık.
 	userID := r.Context().Value("userID")
 	if userID == nil {
 		// Middleware yoksa veya hata varsa
@@ -61,51 +153,61 @@ func JoinBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userKey := "user:" + userID.(string)
+	ipKey := "ip:" + clientIP(r)
+	for _, key := range []string{userKey, ipKey} {
+		if blocked, retry := joinFailures.Blocked(key); blocked {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(retry.Seconds())+1))
+			http.Error(w, "Çok fazla başarısız deneme, lütfen daha sonra tekrar deneyin", http.StatusTooManyRequests)
+			return
+		}
+	}
+
 	var req JoinBoardRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Geçersiz İstek", http.StatusBadRequest)
 		return
 	}
-
-	// 1. Koda sahip panoyu bul
-	// GET boards?invite_code=eq.CODE&select=id
-	query := fmt.Sprintf("boards?invite_code=eq.%s&select=id", req.InviteCode)
-	resp, err := performSupabaseRequest("GET", query, token, nil)
-	if err != nil {
-		http.Error(w, "Pano arama hatası", http.StatusInternalServerError)
+	req.InviteCode = strings.TrimSpace(req.InviteCode)
+	if req.InviteCode == "" {
+		http.Error(w, "Davet kodu gerekli", http.StatusBadRequest)
 		return
 	}
 
-	var foundBoards []struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal(resp, &foundBoards); err != nil || len(foundBoards) == 0 {
-		http.Error(w, "Geçersiz Davet Kodu", http.StatusNotFound)
+	// Kod çözümleme, kullanım sayacı ve üyelik kaydı tek transaction'da (RPC) yapılır
+	resp, err := performSupabaseRequest("POST", "rpc/redeem_board_invite", token, map[string]string{"p_code": req.InviteCode})
+	if err != nil {
+		fmt.Println("JoinBoard Hatası:", err)
+		http.Error(w, "Panoya katılınamadı", http.StatusInternalServerError)
 		return
 	}
-	boardID := foundBoards[0].ID
 
-	// 2. board_members tablosuna ekle
-	member := map[string]string{
-		"board_id": boardID,
-		"user_id":  userID.(string),
+	var results []JoinBoardResult
+	if err := json.Unmarshal(resp, &results); err != nil || len(results) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
 	}
+	result := results[0]
 
-	// Aynı kullanıcı tekrar eklenmesin diye ignoreDuplicates denenebilir ama
-	// Supabase REST'te Conflict ayarı header ile yapılır: Prefer: resolution=ignore-duplicates
-	// Biz şimdilik düz POST atalım, hata verirse "Zaten üyesiniz" deriz.
-	insertResp, err := performSupabaseRequest("POST", "board_members", token, member)
-	if err != nil {
-		// Basit hata kontrolü
-		http.Error(w, "Panoya katılınamadı (Belki zaten üyesiniz?)", http.StatusBadRequest)
+	switch result.Status {
+	case "invalid":
+		joinFailures.Hit(userKey)
+		joinFailures.Hit(ipKey)
+		http.Error(w, "Geçersiz Davet Kodu", http.StatusNotFound)
+		return
+	case "already_member":
+		joinFailures.Reset(userKey)
+		http.Error(w, "Bu panonun zaten üyesisiniz", http.StatusConflict)
 		return
 	}
+	joinFailures.Reset(userKey)
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Write(insertResp)
+	json.NewEncoder(w).Encode(result)
 }
 
-// CreateBoard yeni bir pano oluşturur.
+// CreateBoard yeni bir pano oluşturur; seçilen şablonun sütunlarını, etiketlerini,
+// ayarlarını ve örnek görevlerini tek transaction'da (RPC) uygular.
 func CreateBoard(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if len(authHeader) < 8 {
@@ -117,13 +219,63 @@ func CreateBoard(w http.ResponseWriter, r *http.Request) {
 	bodyBytes, _ := io.ReadAll(r.Body)
 	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	var board Board
-	if err := json.NewDecoder(r.Body).Decode(&board); err != nil {
+	var req CreateBoardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
 		return
 	}
+	if strings.TrimSpace(req.Title) == "" {
+		http.Error(w, "Pano başlığı gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var definition TemplateDefinition
+	if req.TemplateID != "" {
+		template, err := loadCustomTemplate(token, req.TemplateID)
+		if err != nil {
+			fmt.Println("CreateBoard Şablon Hatası:", err)
+			http.Error(w, "Şablon bulunamadı", http.StatusNotFound)
+			return
+		}
+		definition = template.Definition
+		req.Type = "custom"
+	} else {
+		if req.Type == "" {
+			req.Type = "standard"
+		}
+		template, ok := builtinTemplate(req.Type)
+		if !ok {
+			http.Error(w, "Geçersiz pano tipi", http.StatusBadRequest)
+			return
+		}
+		definition = template.Definition
+	}
 
-	resp, err := performSupabaseRequest("POST", "boards", token, board)
+	body := map[string]interface{}{
+		"p_title":      req.Title,
+		"p_type":       req.Type,
+		"p_definition": definition,
+	}
+	if req.WorkspaceID != "" {
+		// Çalışma alanına pano eklemek için en az üye olmak gerekir (trigger da kontrol eder)
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+			return
+		}
+		role, err := workspaceRole(token, req.WorkspaceID, principal.UserID)
+		if err != nil {
+			fmt.Println("CreateBoard Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if roleRank[role] < roleRank[RoleMember] {
+			http.Error(w, "Bu çalışma alanına pano ekleme yetkiniz yok", http.StatusForbidden)
+			return
+		}
+		body["p_workspace"] = req.WorkspaceID
+	}
+	resp, err := performSupabaseRequest("POST", "rpc/create_board_from_template", token, body)
 	if err != nil {
 		fmt.Println("CreateBoard Hatası:", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -149,6 +301,13 @@ func DeleteBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	attachments, err := attachmentKeys(token, fmt.Sprintf("board_id=eq.%s", boardID))
+	if err != nil {
+		fmt.Println("DeleteBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	endpoint := fmt.Sprintf("boards?id=eq.%s", boardID)
 	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
 	if err != nil {
@@ -156,6 +315,7 @@ func DeleteBoard(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	purgeAttachments(attachments)
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Pano silindi", "details": string(resp)})
@@ -178,7 +338,7 @@ func GetBoardMembers(w http.ResponseWriter, r *http.Request) {
 
 	// board_members tablosundan user_id'leri ve profiles tablosundan detayları çekiyoruz
 	// JOIN: board_members -> profiles
-	endpoint := fmt.Sprintf("board_members?select=user_id,profiles(email)&board_id=eq.%s", boardID)
+	endpoint := fmt.Sprintf("board_members?select=user_id,role,profiles(email)&board_id=eq.%s", boardID)
 	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
 	if err != nil {
 		fmt.Println("GetBoardMembers Hatası:", err)
@@ -189,3 +349,67 @@ func GetBoardMembers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
 }
+
+// UpdateBoardMemberRole bir üyenin panodaki rolünü değiştirir.
+// Admin rolünü sadece pano sahibi verebilir veya geri alabilir. Rolü çalışma alanından gelen
+// kullanıcılar için 409 döner.
+func UpdateBoardMemberRole(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" || !ValidMemberRole(req.Role) {
+		http.Error(w, "Geçerli bir user_id ve rol (admin, member, viewer) gerekli", http.StatusBadRequest)
+		return
+	}
+
+	currentRole, err := boardRole(token, access.BoardID, req.UserID)
+	if err != nil {
+		fmt.Println("UpdateBoardMemberRole Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if currentRole == "" || currentRole == RoleOwner {
+		http.Error(w, "Kullanıcı bu panonun üyesi değil", http.StatusNotFound)
+		return
+	}
+	if access.Role != RoleOwner && (currentRole == RoleAdmin || req.Role == RoleAdmin) {
+		http.Error(w, "Admin rolünü sadece pano sahibi değiştirebilir", http.StatusForbidden)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_members?board_id=eq.%s&user_id=eq.%s", access.BoardID, req.UserID)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"role": req.Role})
+	if err != nil {
+		fmt.Println("UpdateBoardMemberRole Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var updated []json.RawMessage
+	if err := json.Unmarshal(resp, &updated); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(updated) == 0 {
+		// Rol boardRole'da bulundu ama üyelik satırı yok: erişim çalışma alanından geliyor
+		http.Error(w, "Kullanıcının rolü çalışma alanı üyeliğinden geliyor; rolü çalışma alanında değiştirin", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
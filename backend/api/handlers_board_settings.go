@@ -0,0 +1,280 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxBoardTitleLength       = 200
+	maxBoardDescriptionLength = 2000
+	maxBoardColorLength       = 32 // hex (#3b82f6) veya tailwind sınıfı (bg-blue-500)
+)
+
+// BoardSettingsPatch ayarların kısmi güncellemesi; nil alanlar değişmez, boş string temizler.
+type BoardSettingsPatch struct {
+	DefaultView     *string `json:"default_view,omitempty"`
+	DefaultAssignee *string `json:"default_assignee,omitempty"`
+	Timezone        *string `json:"timezone,omitempty"`
+}
+
+// UpdateBoardRequest pano bilgisi/ayar güncelleme isteği. Sadece gönderilen alanlar değişir.
+// Archived true panoyu arşivler, false arşivden çıkarır. Arşivlenmiş panoda başka bir alan
+// ancak aynı istekte arşivden çıkarılıyorsa değiştirilebilir.
+type UpdateBoardRequest struct {
+	Title       *string             `json:"title,omitempty"`
+	Type        *string             `json:"type,omitempty"`
+	Description *string             `json:"description,omitempty"`
+	Color       *string             `json:"color,omitempty"`
+	Settings    *BoardSettingsPatch `json:"settings,omitempty"`
+	Archived    *bool               `json:"archived,omitempty"`
+	WorkspaceID *string             `json:"workspace_id,omitempty"` // panoyu başka bir çalışma alanına taşır (sadece sahip)
+}
+
+// loadBoard panoyu tüm kolonlarıyla yükler.
+func loadBoard(token, boardID string) (*Board, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("boards?id=eq.%s&select=*", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var boards []Board
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		return nil, errBoardNotFound
+	}
+	return &boards[0], nil
+}
+
+// effectiveWorkflow panonun sütunları (boşsa pano tipinden türetilir)
+func (b *Board) effectiveWorkflow() Workflow {
+	wf := Workflow{}
+	if b.Workflow != nil {
+		wf = *b.Workflow
+	}
+	return effectiveWorkflow(b.Type, wf)
+}
+
+// UpdateBoard panonun başlığını, tipini, açıklamasını, rengini ve ayarlarını günceller;
+// panoyu arşivler, arşivden çıkarır veya başka bir çalışma alanına taşır.
+func UpdateBoard(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateBoardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+
+	board, err := loadBoard(token, access.BoardID)
+	if err != nil {
+		fmt.Println("UpdateBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	unarchiving := req.Archived != nil && !*req.Archived
+	if board.ArchivedAt != nil && !unarchiving {
+		http.Error(w, "Pano arşivlenmiş, salt okunur; önce arşivden çıkarın", http.StatusConflict)
+		return
+	}
+
+	update, changed, err := boardUpdate(token, board, req)
+	if _, ok := err.(requestError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		fmt.Println("UpdateBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.WorkspaceID != nil && (board.WorkspaceID == nil || *req.WorkspaceID != *board.WorkspaceID) {
+		if access.Role != RoleOwner {
+			http.Error(w, "Panoyu sadece sahibi başka bir çalışma alanına taşıyabilir", http.StatusForbidden)
+			return
+		}
+		if *req.WorkspaceID == "" {
+			update["workspace_id"] = nil // kişisel çalışma alanına döner
+		} else {
+			role, err := workspaceRole(token, *req.WorkspaceID, access.UserID)
+			if err != nil {
+				fmt.Println("UpdateBoard Hatası:", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if roleRank[role] < roleRank[RoleMember] {
+				http.Error(w, "Bu çalışma alanına pano ekleme yetkiniz yok", http.StatusForbidden)
+				return
+			}
+			update["workspace_id"] = *req.WorkspaceID
+		}
+		changed = append(changed, "workspace_id")
+	}
+	if req.Archived != nil && *req.Archived != (board.ArchivedAt != nil) {
+		if *req.Archived {
+			update["archived_at"] = time.Now().UTC().Format(time.RFC3339)
+			update["archived_by"] = access.UserID
+		} else {
+			update["archived_at"] = nil
+			update["archived_by"] = nil
+		}
+	}
+	if len(update) == 0 {
+		http.Error(w, "Değiştirilecek alan yok", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := performSupabaseRequest("PATCH", fmt.Sprintf("boards?id=eq.%s", access.BoardID), token, update)
+	if err != nil {
+		fmt.Println("UpdateBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(changed) > 0 {
+		GlobalHub.Publish(access.BoardID, "board_updated", map[string]interface{}{
+			"fields":     changed,
+			"updated_by": access.UserID,
+		})
+	}
+	if archivedAt, ok := update["archived_at"]; ok {
+		event := "board_archived"
+		if archivedAt == nil {
+			event = "board_unarchived"
+		}
+		GlobalHub.Publish(access.BoardID, event, map[string]interface{}{"by": access.UserID})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// boardUpdate istekteki bilgi ve ayar alanlarını doğrulayıp PATCH gövdesine çevirir.
+// changed değişen alanların adlarıdır (arşiv durumu hariç). Doğrulama hataları requestError döner.
+func boardUpdate(token string, board *Board, req UpdateBoardRequest) (map[string]interface{}, []string, error) {
+	update := make(map[string]interface{})
+	var changed []string
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, nil, requestError("Pano başlığı boş olamaz")
+		}
+		if len([]rune(title)) > maxBoardTitleLength {
+			return nil, nil, requestError(fmt.Sprintf("Pano başlığı en fazla %d karakter olabilir", maxBoardTitleLength))
+		}
+		update["title"] = title
+		changed = append(changed, "title")
+	}
+
+	if req.Type != nil {
+		// Tip sadece sütunları tanımlanmamış eski panolarda workflow'u belirler
+		if _, ok := builtinTemplate(*req.Type); !ok && *req.Type != "custom" {
+			return nil, nil, requestError("Geçersiz pano tipi")
+		}
+		update["type"] = *req.Type
+		changed = append(changed, "type")
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len([]rune(description)) > maxBoardDescriptionLength {
+			return nil, nil, requestError(fmt.Sprintf("Açıklama en fazla %d karakter olabilir", maxBoardDescriptionLength))
+		}
+		update["description"] = nullIfEmpty(description)
+		changed = append(changed, "description")
+	}
+
+	if req.Color != nil {
+		color := strings.TrimSpace(*req.Color)
+		if len(color) > maxBoardColorLength {
+			return nil, nil, requestError("Geçersiz renk")
+		}
+		update["color"] = nullIfEmpty(color)
+		changed = append(changed, "color")
+	}
+
+	if req.Settings != nil {
+		settings := BoardSettings{}
+		if board.Settings != nil {
+			settings = *board.Settings
+		}
+		if err := applySettingsPatch(token, board, &settings, *req.Settings); err != nil {
+			return nil, nil, err
+		}
+		update["settings"] = settings
+		changed = append(changed, "settings")
+	}
+
+	return update, changed, nil
+}
+
+// applySettingsPatch ayar değişikliklerini doğrulayarak settings'e uygular.
+func applySettingsPatch(token string, board *Board, settings *BoardSettings, patch BoardSettingsPatch) error {
+	if patch.DefaultView != nil {
+		view := *patch.DefaultView
+		valid := view == ""
+		for _, v := range boardViews {
+			if v == view {
+				valid = true
+			}
+		}
+		if !valid {
+			return requestError(fmt.Sprintf("Geçersiz varsayılan görünüm (%s)", strings.Join(boardViews, ", ")))
+		}
+		settings.DefaultView = view
+	}
+
+	if patch.Timezone != nil {
+		if *patch.Timezone != "" {
+			if _, err := time.LoadLocation(*patch.Timezone); err != nil {
+				return requestError(fmt.Sprintf("Geçersiz saat dilimi: %s", *patch.Timezone))
+			}
+		}
+		settings.Timezone = *patch.Timezone
+	}
+
+	if patch.DefaultAssignee != nil {
+		if *patch.DefaultAssignee != "" {
+			users, err := boardUsersByEmail(token, board.ID)
+			if err != nil {
+				return fmt.Errorf("pano üyeleri yüklenemedi: %w", err)
+			}
+			member := false
+			for _, id := range users {
+				if id == *patch.DefaultAssignee {
+					member = true
+				}
+			}
+			if !member {
+				return requestError("Varsayılan atanan kişi panonun üyesi olmalı")
+			}
+		}
+		settings.DefaultAssignee = *patch.DefaultAssignee
+	}
+	return nil
+}
+
+// nullIfEmpty boş metni NULL olarak yazmak için
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
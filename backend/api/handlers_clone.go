@@ -0,0 +1,384 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CloneBoardRequest pano kopyalama seçenekleri
+type CloneBoardRequest struct {
+	Title           string `json:"title"`
+	CopyTasks       bool   `json:"copy_tasks"`
+	OnlyUnfinished  bool   `json:"only_unfinished"`  // sadece "done" kategorisinde olmayan görevler
+	CopySubtasks    bool   `json:"copy_subtasks"`    // alt görevler tamamlanmamış olarak kopyalanır
+	CopyAssignments bool   `json:"copy_assignments"` // atanan kişi yeni panoda üye değilse atama boş kalır
+	CopyMembers     bool   `json:"copy_members"`
+	CopySettings    bool   `json:"copy_settings"` // ayarlar ve etiketler
+
+	keepSubtaskState bool // içe aktarmada alt görevlerin tamamlanma durumu korunur
+}
+
+// CloneBoardResult kopyalama (ve içe aktarma) sonucu
+type CloneBoardResult struct {
+	Board          Board             `json:"board"`
+	TasksCopied    int               `json:"tasks_copied"`
+	SubtasksCopied int               `json:"subtasks_copied"`
+	MembersCopied  int               `json:"members_copied"`
+	TaskIDMap      map[string]string `json:"task_id_map,omitempty"` // eski görev ID -> yeni görev ID
+}
+
+const (
+	// cloneAsyncThreshold bu kadar görev + alt görevden büyük panolar arka planda kopyalanır
+	cloneAsyncThreshold = 200
+	// cloneBatchSize tek Supabase isteğinde eklenen satır sayısı
+	cloneBatchSize = 100
+)
+
+// boardSnapshot kopyalanacak panonun içeriği
+type boardSnapshot struct {
+	Board   Board
+	Labels  []TemplateLabel
+	Members []struct {
+		UserID string `json:"user_id"`
+		Role   string `json:"role"`
+	}
+	Tasks []Task
+}
+
+func (s *boardSnapshot) steps(req CloneBoardRequest) int {
+	n := len(s.Tasks)
+	if req.CopySubtasks {
+		for _, t := range s.Tasks {
+			n += len(t.Subtasks)
+		}
+	}
+	return n
+}
+
+// loadBoardSnapshot panoyu, etiketlerini, üyelerini ve (istenirse) görevlerini yükler.
+func loadBoardSnapshot(token, boardID string, req CloneBoardRequest) (*boardSnapshot, error) {
+	snap := &boardSnapshot{}
+
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("boards?id=eq.%s&select=*", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var boards []Board
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		return nil, errBoardNotFound
+	}
+	snap.Board = boards[0]
+	workflow := Workflow{}
+	if snap.Board.Workflow != nil {
+		workflow = *snap.Board.Workflow
+	}
+	workflow = effectiveWorkflow(snap.Board.Type, workflow)
+	snap.Board.Workflow = &workflow
+
+	if req.CopySettings {
+		resp, err := performSupabaseRequest("GET", fmt.Sprintf("board_labels?board_id=eq.%s&select=name,color", boardID), token, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp, &snap.Labels); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.CopyMembers {
+		resp, err := performSupabaseRequest("GET", fmt.Sprintf("board_members?board_id=eq.%s&select=user_id,role", boardID), token, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp, &snap.Members); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.CopyTasks {
+		resp, err := performSupabaseRequest("GET", fmt.Sprintf("tasks?board_id=eq.%s&select=*,subtasks(*)&order=position.asc", boardID), token, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp, &snap.Tasks); err != nil {
+			return nil, err
+		}
+		if req.OnlyUnfinished {
+			unfinished := snap.Tasks[:0]
+			for _, t := range snap.Tasks {
+				if workflow.Category(t.Status) != CategoryDone {
+					unfinished = append(unfinished, t)
+				}
+			}
+			snap.Tasks = unfinished
+		}
+	}
+
+	return snap, nil
+}
+
+// writeBoardSnapshot yeni panoyu oluşturup içeriği parça parça yazar (kopyalama ve içe aktarma).
+// Hata olursa yarım kalan pano silinir (görevler cascade ile gider).
+func writeBoardSnapshot(token, ownerID string, snap *boardSnapshot, req CloneBoardRequest, job *Job) (result *CloneBoardResult, err error) {
+	newBoard := Board{
+		Title:    req.Title,
+		Type:     snap.Board.Type,
+		Workflow: snap.Board.Workflow,
+	}
+	if req.CopySettings && snap.Board.Settings != nil {
+		newBoard.Settings = snap.Board.Settings
+	}
+
+	resp, err := performSupabaseRequest("POST", "boards", token, newBoard)
+	if err != nil {
+		return nil, err
+	}
+	var created []Board
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		return nil, fmt.Errorf("yeni pano oluşturulamadı")
+	}
+	boardID := created[0].ID
+
+	defer func() {
+		if err != nil {
+			performSupabaseRequest("DELETE", fmt.Sprintf("boards?id=eq.%s", boardID), token, nil)
+		}
+	}()
+
+	result = &CloneBoardResult{Board: created[0]}
+
+	if req.CopySettings && len(snap.Labels) > 0 {
+		labels := make([]map[string]string, 0, len(snap.Labels))
+		for _, l := range snap.Labels {
+			labels = append(labels, map[string]string{"board_id": boardID, "name": l.Name, "color": l.Color})
+		}
+		if _, err := performSupabaseRequest("POST", "board_labels", token, labels); err != nil {
+			return nil, err
+		}
+	}
+
+	// Atama sadece yeni panoda erişimi olan kullanıcılara korunur
+	members := map[string]bool{ownerID: true}
+	if req.CopyMembers && len(snap.Members) > 0 {
+		rows := make([]map[string]string, 0, len(snap.Members))
+		for _, m := range snap.Members {
+			if m.UserID == ownerID {
+				continue
+			}
+			role := m.Role
+			if role == "" {
+				role = RoleMember
+			}
+			rows = append(rows, map[string]string{"board_id": boardID, "user_id": m.UserID, "role": role})
+			members[m.UserID] = true
+		}
+		// Kaynak panonun sahibi (kopyalayan değilse) admin olarak eklenir
+		if snap.Board.UserID != ownerID && !members[snap.Board.UserID] {
+			rows = append(rows, map[string]string{"board_id": boardID, "user_id": snap.Board.UserID, "role": RoleAdmin})
+			members[snap.Board.UserID] = true
+		}
+		if len(rows) > 0 {
+			if _, err := performSupabaseRequest("POST", "board_members", token, rows); err != nil {
+				return nil, err
+			}
+		}
+		result.MembersCopied = len(rows)
+	}
+
+	tasks := make([]Task, 0, len(snap.Tasks))
+	for _, t := range snap.Tasks {
+		row := Task{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			Priority:    t.Priority,
+			DueDate:     t.DueDate,
+			Position:    t.Position,
+		}
+		if req.CopyAssignments && t.AssignedTo != nil && members[*t.AssignedTo] {
+			row.AssignedTo = t.AssignedTo
+		}
+		if req.CopySubtasks {
+			for _, st := range t.Subtasks {
+				st.IsCompleted = req.keepSubtaskState && st.IsCompleted
+				row.Subtasks = append(row.Subtasks, st)
+			}
+		}
+		tasks = append(tasks, row)
+	}
+
+	inserted, err := insertTasks(token, boardID, tasks, job)
+	if err != nil {
+		return nil, err
+	}
+	result.TasksCopied = inserted.Tasks
+	result.SubtasksCopied = inserted.Subtasks
+	result.TaskIDMap = inserted.IDMap
+
+	return result, nil
+}
+
+// insertedTasks insertTasks sonucu
+type insertedTasks struct {
+	Tasks    int
+	Subtasks int
+	IDMap    map[string]string // gönderilen görev ID -> yeni görev ID
+}
+
+// insertTasks görevleri alt görevleriyle birlikte panoya parça parça ekler.
+// Gönderilen ID'ler yazılmaz, sadece eski -> yeni eşlemesi için kullanılır.
+func insertTasks(token, boardID string, tasks []Task, job *Job) (*insertedTasks, error) {
+	result := &insertedTasks{IDMap: make(map[string]string)}
+
+	for start := 0; start < len(tasks); start += cloneBatchSize {
+		end := start + cloneBatchSize
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		batch := tasks[start:end]
+
+		rows := make([]Task, 0, len(batch))
+		for _, t := range batch {
+			rows = append(rows, Task{
+				Title:       t.Title,
+				Description: t.Description,
+				Status:      t.Status,
+				Priority:    t.Priority,
+				DueDate:     t.DueDate,
+				Position:    t.Position,
+				BoardID:     boardID,
+				AssignedTo:  t.AssignedTo,
+			})
+		}
+
+		resp, err := performSupabaseRequest("POST", "tasks?select=id", token, rows)
+		if err != nil {
+			return nil, err
+		}
+		var inserted []Task
+		if err := json.Unmarshal(resp, &inserted); err != nil || len(inserted) != len(batch) {
+			return nil, fmt.Errorf("görevler eklenemedi")
+		}
+		result.Tasks += len(inserted)
+		for i, t := range batch {
+			if t.ID != "" {
+				result.IDMap[t.ID] = inserted[i].ID
+			}
+		}
+		if job != nil {
+			job.Advance(len(inserted))
+		}
+
+		// PostgREST eklenen satırları gönderilen sırayla döndürür; eski -> yeni eşlemesi buna dayanır
+		var subtasks []Subtask
+		for i, t := range batch {
+			for _, st := range t.Subtasks {
+				subtasks = append(subtasks, Subtask{
+					TaskID:      inserted[i].ID,
+					Title:       st.Title,
+					IsCompleted: st.IsCompleted,
+					Position:    st.Position,
+				})
+			}
+		}
+		for s := 0; s < len(subtasks); s += cloneBatchSize {
+			e := s + cloneBatchSize
+			if e > len(subtasks) {
+				e = len(subtasks)
+			}
+			if _, err := performSupabaseRequest("POST", "subtasks", token, subtasks[s:e]); err != nil {
+				return nil, err
+			}
+			result.Subtasks += e - s
+			if job != nil {
+				job.Advance(e - s)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// CloneBoard panoyu seçilen içerikle yeni bir başlık ve davet koduyla kopyalar.
+// Büyük panolar arka planda kopyalanır; yanıt 202 ve /api/jobs/{id} ile izlenebilen iş döner.
+func CloneBoard(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req CloneBoardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.CopySubtasks && !req.CopyTasks {
+		http.Error(w, "copy_subtasks için copy_tasks gerekli", http.StatusBadRequest)
+		return
+	}
+
+	snap, err := loadBoardSnapshot(token, access.BoardID, req)
+	if err != nil {
+		fmt.Println("CloneBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		req.Title = snap.Board.Title + " (Kopya)"
+	}
+
+	steps := snap.steps(req)
+	if steps <= cloneAsyncThreshold {
+		result, err := writeBoardSnapshot(token, access.UserID, snap, req, nil)
+		if err != nil {
+			fmt.Println("CloneBoard Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+	jobTok, err := jobToken(principal, token)
+	if err != nil {
+		fmt.Println("CloneBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	job := jobs.create("clone_board", access.UserID)
+	job.SetTotal(steps)
+	go func() {
+		result, err := writeBoardSnapshot(jobTok, access.UserID, snap, req, job)
+		if err != nil {
+			fmt.Println("CloneBoard (arka plan) Hatası:", err)
+		}
+		job.Finish(result, err)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,503 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxCommentLength = 10000
+
+// commentColumns yorumlar yazar e-postasıyla birlikte seçilir
+const commentColumns = "id,task_id,board_id,parent_id,user_id,body,mentions,created_at,edited_at,deleted_at,author:profiles!user_id(email)"
+
+// mentionPattern yorum metnindeki @e-posta bahsetmeleri
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// TaskComment görev yorumu. Üst seviye yorumların yanıtları Replies'ta döner.
+// Yanıtı olan silinmiş yorumlar DeletedAt dolu ve gövdesi boş olarak kalır.
+type TaskComment struct {
+	ID        string        `json:"id"`
+	TaskID    string        `json:"task_id"`
+	BoardID   string        `json:"board_id"`
+	ParentID  *string       `json:"parent_id"`
+	UserID    string        `json:"user_id"`
+	Body      string        `json:"body"`
+	Mentions  []string      `json:"mentions"`
+	CreatedAt string        `json:"created_at"`
+	EditedAt  *string       `json:"edited_at"`
+	DeletedAt *string       `json:"deleted_at"`
+	Author    *Profile      `json:"author,omitempty"`
+	Replies   []TaskComment `json:"replies,omitempty"`
+}
+
+// CommentRequest yorum oluşturma (task_id, body, parent_id) ve düzenleme (id, body) isteği
+type CommentRequest struct {
+	ID       string `json:"id,omitempty"`
+	TaskID   string `json:"task_id,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+	Body     string `json:"body"`
+}
+
+// CommentEdit yorumun önceki bir hali
+type CommentEdit struct {
+	ID        string  `json:"id"`
+	CommentID string  `json:"comment_id"`
+	Body      string  `json:"body"`
+	EditedBy  *string `json:"edited_by"`
+	EditedAt  string  `json:"edited_at"`
+}
+
+// boardPerson panoda bahsedilebilecek kişi ve bildirim tercihi
+type boardPerson struct {
+	UserID            string `json:"user_id"`
+	Email             string `json:"email"`
+	NotificationLevel string `json:"notification_level"`
+}
+
+// parseMentions metindeki @e-posta bahsetmelerini (küçük harf, tekrarsız) döndürür.
+func parseMentions(body string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(m[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// loadBoardPeople panonun sahibi, üyeleri ve çalışma alanı üyelerini e-postaya göre döndürür.
+func loadBoardPeople(token, boardID string) (map[string]boardPerson, error) {
+	resp, err := performSupabaseRequest("POST", "rpc/board_people", token, map[string]string{"p_board": boardID})
+	if err != nil {
+		return nil, err
+	}
+	var rows []boardPerson
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	people := make(map[string]boardPerson, len(rows))
+	for _, p := range rows {
+		people[strings.ToLower(p.Email)] = p
+	}
+	return people, nil
+}
+
+// resolveMentions bahsedilen e-postaları pano kişilerine çevirir. Panoda olmayanlar unresolved'a düşer.
+func resolveMentions(token, boardID, body string) ([]boardPerson, []string, error) {
+	emails := parseMentions(body)
+	if len(emails) == 0 {
+		return nil, nil, nil
+	}
+	people, err := loadBoardPeople(token, boardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var mentioned []boardPerson
+	var unresolved []string
+	for _, email := range emails {
+		if p, ok := people[email]; ok {
+			mentioned = append(mentioned, p)
+		} else {
+			unresolved = append(unresolved, email)
+		}
+	}
+	return mentioned, unresolved, nil
+}
+
+// mentionIDs kişilerin kullanıcı ID'leri
+func mentionIDs(people []boardPerson) []string {
+	ids := make([]string, 0, len(people))
+	for _, p := range people {
+		ids = append(ids, p.UserID)
+	}
+	return ids
+}
+
+// notifyMentions bahsedilen kişilere (yazar ve bildirimi kapatanlar hariç) anlık bildirim gönderir.
+// skip daha önce bildirilmiş kullanıcılardır (düzenlemede tekrar bildirilmez).
+func notifyMentions(access *BoardAccess, comment TaskComment, people []boardPerson, skip []string) {
+	for _, p := range people {
+		if p.UserID == access.UserID || p.NotificationLevel == NotifyNone || containsString(skip, p.UserID) {
+			continue
+		}
+		GlobalHub.NotifyUser(p.UserID, access.BoardID, "mentioned", map[string]interface{}{
+			"task_id":    comment.TaskID,
+			"comment_id": comment.ID,
+			"by":         access.UserID,
+			"excerpt":    commentExcerpt(comment.Body),
+		})
+	}
+}
+
+// commentExcerpt bildirimlerde gösterilecek kısa metin
+func commentExcerpt(body string) string {
+	runes := []rune(body)
+	if len(runes) <= 140 {
+		return body
+	}
+	return string(runes[:140]) + "…"
+}
+
+// validateCommentBody yorum metnini doğrular ve kırpılmış halini döndürür.
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("Yorum boş olamaz")
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return "", fmt.Errorf("Yorum en fazla %d karakter olabilir", maxCommentLength)
+	}
+	return body, nil
+}
+
+// loadComment tek bir yorumu yükler.
+func loadComment(token, commentID string) (*TaskComment, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("task_comments?id=eq.%s&select=%s", commentID, commentColumns), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var comments []TaskComment
+	if err := json.Unmarshal(resp, &comments); err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return nil, errBoardNotFound
+	}
+	return &comments[0], nil
+}
+
+// threadComments yorumları oluşturulma sırasıyla üst seviye yorumlar ve yanıtları olarak dizer.
+func threadComments(comments []TaskComment) []TaskComment {
+	replies := make(map[string][]TaskComment)
+	for _, c := range comments {
+		if c.ParentID != nil {
+			replies[*c.ParentID] = append(replies[*c.ParentID], c)
+		}
+	}
+	threads := make([]TaskComment, 0, len(comments))
+	for _, c := range comments {
+		if c.ParentID == nil {
+			c.Replies = replies[c.ID]
+			threads = append(threads, c)
+		}
+	}
+	return threads
+}
+
+// hasReplies yorumun (silinmemiş veya silinmiş) yanıtı var mı
+func hasReplies(token, commentID string) (bool, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("task_comments?parent_id=eq.%s&select=id&limit=1", commentID), token, nil)
+	if err != nil {
+		return false, err
+	}
+	var rows []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
+// GetTaskComments görevin yorumlarını yanıtlarıyla birlikte döndürür (?task_id=).
+func GetTaskComments(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	taskID := r.URL.Query().Get("task_id")
+	endpoint := fmt.Sprintf("task_comments?task_id=eq.%s&select=%s&order=created_at.asc", taskID, commentColumns)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetTaskComments Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var comments []TaskComment
+	if err := json.Unmarshal(resp, &comments); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(threadComments(comments))
+}
+
+// CreateTaskComment göreve yorum veya bir yoruma yanıt ekler. Yanıtlara verilen yanıtlar
+// üst seviye yoruma bağlanır. @e-posta ile bahsedilen pano kişileri bildirim alır.
+func CreateTaskComment(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	body, err := validateCommentBody(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comment := map[string]interface{}{
+		"task_id":  req.TaskID,
+		"board_id": access.BoardID,
+		"user_id":  access.UserID,
+		"body":     body,
+	}
+
+	if req.ParentID != "" {
+		parent, err := loadComment(token, req.ParentID)
+		if err == errBoardNotFound || (err == nil && parent.TaskID != req.TaskID) {
+			http.Error(w, "Yanıtlanan yorum bu göreve ait değil", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			fmt.Println("CreateTaskComment Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		parentID := parent.ID
+		if parent.ParentID != nil {
+			parentID = *parent.ParentID // tek seviye thread
+		}
+		comment["parent_id"] = parentID
+	}
+
+	mentioned, unresolved, err := resolveMentions(token, access.BoardID, body)
+	if err != nil {
+		fmt.Println("CreateTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	comment["mentions"] = mentionIDs(mentioned)
+
+	resp, err := performSupabaseRequest("POST", "task_comments?select="+commentColumns, token, comment)
+	if err != nil {
+		fmt.Println("CreateTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var created []TaskComment
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "comment_created", created[0])
+	notifyMentions(access, created[0], mentioned, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"comment":             created[0],
+		"unresolved_mentions": nonNil(unresolved),
+	})
+}
+
+// UpdateTaskComment yorumu düzenler (sadece yazarı). Önceki hali geçmişe yazılır, yeni
+// bahsedilen kişiler bildirim alır.
+func UpdateTaskComment(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Yorumun panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	body, err := validateCommentBody(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := loadComment(token, req.ID)
+	if err != nil {
+		fmt.Println("UpdateTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if existing.UserID != access.UserID {
+		http.Error(w, "Sadece kendi yorumlarınızı düzenleyebilirsiniz", http.StatusForbidden)
+		return
+	}
+	if existing.DeletedAt != nil {
+		http.Error(w, "Silinmiş yorum düzenlenemez", http.StatusConflict)
+		return
+	}
+	if body == existing.Body {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"comment": existing, "unresolved_mentions": []string{}})
+		return
+	}
+
+	mentioned, unresolved, err := resolveMentions(token, access.BoardID, body)
+	if err != nil {
+		fmt.Println("UpdateTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	update := map[string]interface{}{"body": body, "mentions": mentionIDs(mentioned)}
+	endpoint := fmt.Sprintf("task_comments?id=eq.%s&select=%s", req.ID, commentColumns)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, update)
+	if err != nil {
+		fmt.Println("UpdateTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var updated []TaskComment
+	if err := json.Unmarshal(resp, &updated); err != nil || len(updated) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "comment_updated", updated[0])
+	notifyMentions(access, updated[0], mentioned, existing.Mentions)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"comment":             updated[0],
+		"unresolved_mentions": nonNil(unresolved),
+	})
+}
+
+// DeleteTaskComment yorumu siler (?id=). Yazar kendi yorumunu, sahip ve yöneticiler her yorumu
+// silebilir. Yanıtı olan yorum thread'i korumak için boş bir yer tutucu olarak kalır.
+func DeleteTaskComment(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Yorumun panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	commentID := r.URL.Query().Get("id")
+	comment, err := loadComment(token, commentID)
+	if err != nil {
+		fmt.Println("DeleteTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if comment.UserID != access.UserID && !RoleAllows(access.Role, PermDeleteTasks) {
+		http.Error(w, "Bu yorumu silme yetkiniz yok", http.StatusForbidden)
+		return
+	}
+
+	replies := false
+	if comment.ParentID == nil {
+		if replies, err = hasReplies(token, commentID); err != nil {
+			fmt.Println("DeleteTaskComment Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if replies {
+		update := map[string]interface{}{
+			"body":       "",
+			"mentions":   []string{},
+			"deleted_at": time.Now().UTC().Format(time.RFC3339),
+		}
+		_, err = performSupabaseRequest("PATCH", fmt.Sprintf("task_comments?id=eq.%s", commentID), token, update)
+	} else {
+		_, err = performSupabaseRequest("DELETE", fmt.Sprintf("task_comments?id=eq.%s", commentID), token, nil)
+	}
+	if err != nil {
+		fmt.Println("DeleteTaskComment Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Son yanıtı silinen yer tutucu yorum da kaldırılır
+	if comment.ParentID != nil {
+		if parent, err := loadComment(token, *comment.ParentID); err == nil && parent.DeletedAt != nil {
+			if more, err := hasReplies(token, parent.ID); err == nil && !more {
+				performSupabaseRequest("DELETE", fmt.Sprintf("task_comments?id=eq.%s", parent.ID), token, nil)
+			}
+		}
+	}
+
+	GlobalHub.Publish(access.BoardID, "comment_deleted", map[string]interface{}{
+		"id":          commentID,
+		"task_id":     comment.TaskID,
+		"parent_id":   comment.ParentID,
+		"placeholder": replies,
+		"deleted_by":  access.UserID,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTaskCommentHistory yorumun önceki hallerini eskiden yeniye döndürür (?id=).
+func GetTaskCommentHistory(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	commentID := r.URL.Query().Get("id")
+	endpoint := fmt.Sprintf("task_comment_edits?comment_id=eq.%s&select=*&order=edited_at.asc", commentID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetTaskCommentHistory Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var edits []CommentEdit
+	if err := json.Unmarshal(resp, &edits); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(edits)
+}
+
+// nonNil boş listenin JSON'da null yerine [] yazılması için
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,491 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns dışa aktarılabilecek sütunlar ve değerleri
+var csvColumns = map[string]func(t Task) string{
+	"id":          func(t Task) string { return t.ID },
+	"title":       func(t Task) string { return t.Title },
+	"description": func(t Task) string { return stringValue(t.Description) },
+	"status":      func(t Task) string { return t.Status },
+	"priority":    func(t Task) string { return t.Priority },
+	"due_date":    func(t Task) string { return stringValue(t.DueDate) },
+	"position":    func(t Task) string { return strconv.Itoa(t.Position) },
+	"board_id":    func(t Task) string { return t.BoardID },
+	"assignee": func(t Task) string {
+		if t.Assignee == nil {
+			return ""
+		}
+		return t.Assignee.Email
+	},
+	"creator": func(t Task) string {
+		if t.Profile == nil {
+			return ""
+		}
+		return t.Profile.Email
+	},
+	"subtasks": func(t Task) string { return strconv.Itoa(len(t.Subtasks)) },
+	"subtasks_done": func(t Task) string {
+		done := 0
+		for _, st := range t.Subtasks {
+			if st.IsCompleted {
+				done++
+			}
+		}
+		return strconv.Itoa(done)
+	},
+}
+
+var defaultCSVColumns = []string{"id", "title", "description", "status", "priority", "due_date", "assignee"}
+
+// csvImportFields içe aktarmada CSV başlıklarının eşlenebileceği alanlar (ve kabul edilen takma adlar)
+var csvImportFields = map[string][]string{
+	"title":       {"title", "başlık", "baslik", "name"},
+	"description": {"description", "açıklama", "aciklama"},
+	"status":      {"status", "durum"},
+	"priority":    {"priority", "öncelik", "oncelik"},
+	"due_date":    {"due_date", "due", "bitiş", "bitis", "son tarih"},
+	"assignee":    {"assignee", "atanan", "assigned_to"},
+	"position":    {"position", "sıra", "sira"},
+}
+
+// maxCSVSize içe aktarılabilecek CSV dosyasının en büyük boyutu
+const maxCSVSize = 5 << 20
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// CSVRowError içe aktarmada hatalı satır (Row 1'den başlar, başlık satırı hariç)
+type CSVRowError struct {
+	Row    int      `json:"row"`
+	Errors []string `json:"errors"`
+}
+
+// CSVImportResult CSV içe aktarma raporu
+type CSVImportResult struct {
+	DryRun   bool              `json:"dry_run"`
+	Mapping  map[string]string `json:"mapping"` // CSV başlığı -> alan
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	Failed   []CSVRowError     `json:"failed"`
+}
+
+// ExportTasksCSV GetTasks ile aynı filtrelerle görevleri CSV olarak indirir.
+// ?columns=id,title,status ile sütunlar seçilebilir; ?delimiter=; Excel (TR) için noktalı virgül kullanır.
+func ExportTasksCSV(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	columns := defaultCSVColumns
+	if raw := r.URL.Query().Get("columns"); raw != "" {
+		columns = nil
+		for _, c := range strings.Split(raw, ",") {
+			c = strings.TrimSpace(c)
+			if _, ok := csvColumns[c]; !ok {
+				http.Error(w, "Geçersiz sütun: "+c, http.StatusBadRequest)
+				return
+			}
+			columns = append(columns, c)
+		}
+	}
+
+	delimiter, ok := csvDelimiter(r.URL.Query().Get("delimiter"))
+	if !ok {
+		http.Error(w, "Geçersiz ayraç (virgül, noktalı virgül veya tab)", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := loadTasks(token, r.URL.Query())
+	if _, ok := err.(requestError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		fmt.Println("ExportTasksCSV Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := "tasks.csv"
+	if boardID := r.URL.Query().Get("board_id"); boardID != "" {
+		filename = fmt.Sprintf("tasks-%s.csv", boardID)
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	cw.Write(columns)
+	row := make([]string, len(columns))
+	for _, t := range tasks {
+		for i, c := range columns {
+			row[i] = escapeCSVFormula(csvColumns[c](t))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+}
+
+// csvFormulaPrefixes Excel/Sheets'in hücreyi formül olarak çalıştırdığı ilk karakterler
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVFormula formül gibi başlayan hücrelerin başına ' ekler (CSV/formula injection).
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVFormula dışa aktarmada eklenen ' önekini kaldırır.
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// csvDelimiter ayraç parametresini çözer (boşsa virgül).
+func csvDelimiter(value string) (rune, bool) {
+	switch value {
+	case "", ",", "comma":
+		return ',', true
+	case ";", "semicolon":
+		return ';', true
+	case "\t", "tab":
+		return '\t', true
+	}
+	return 0, false
+}
+
+// ImportTasksCSV CSV dosyasındaki satırları board_id panosuna görev olarak ekler.
+// Gövde ham CSV (text/csv) veya multipart "file" alanı olabilir. Başlıklar alan adlarına
+// (ve Türkçe karşılıklarına) otomatik eşlenir; ?mapping={"Görev":"title"} ile elle eşlenebilir.
+// Hatalı satırlar atlanıp raporlanır; ?dry_run=true hiçbir şey eklemeden raporu döndürür.
+func ImportTasksCSV(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "board_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	body, mappingRaw, err := readCSVUpload(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var mapping map[string]string
+	if mappingRaw != "" {
+		if err := json.Unmarshal([]byte(mappingRaw), &mapping); err != nil {
+			http.Error(w, "Geçersiz mapping (JSON nesnesi olmalı)", http.StatusBadRequest)
+			return
+		}
+		for header, field := range mapping {
+			if _, ok := csvImportFields[field]; !ok && field != "" {
+				http.Error(w, fmt.Sprintf("Geçersiz alan %q (%s)", field, header), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	records, err := parseCSV(body)
+	if err != nil {
+		http.Error(w, "CSV okunamadı: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(records) == 0 {
+		http.Error(w, "CSV boş", http.StatusBadRequest)
+		return
+	}
+
+	columns, resolved := mapCSVHeader(records[0], mapping)
+	if _, ok := columns["title"]; !ok {
+		http.Error(w, "title sütunu bulunamadı; mapping ile belirtin", http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := boardWorkflow(token, access.BoardID)
+	if err != nil {
+		fmt.Println("ImportTasksCSV Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var users map[string]string
+	if _, ok := columns["assignee"]; ok {
+		if users, err = boardUsersByEmail(token, access.BoardID); err != nil {
+			fmt.Println("ImportTasksCSV Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result := CSVImportResult{
+		DryRun:  r.URL.Query().Get("dry_run") == "true",
+		Mapping: resolved,
+		Total:   len(records) - 1,
+		Failed:  []CSVRowError{},
+	}
+
+	var tasks []Task
+	for i, record := range records[1:] {
+		task, errs := csvRowToTask(record, columns, workflow, users)
+		if len(errs) > 0 {
+			result.Failed = append(result.Failed, CSVRowError{Row: i + 1, Errors: errs})
+			continue
+		}
+		task.BoardID = access.BoardID
+		if _, ok := columns["position"]; !ok {
+			task.Position = i
+		}
+		tasks = append(tasks, task)
+	}
+
+	if !result.DryRun {
+		wipOverrides, ok := enforceWIPByStatus(w, token, access, workflow, tasks, wipOverrideRequested(r))
+		if !ok {
+			return
+		}
+		for start := 0; start < len(tasks); start += cloneBatchSize {
+			end := start + cloneBatchSize
+			if end > len(tasks) {
+				end = len(tasks)
+			}
+			if _, err := performSupabaseRequest("POST", "tasks?select=id", token, tasks[start:end]); err != nil {
+				fmt.Println("ImportTasksCSV Hatası:", err)
+				http.Error(w, fmt.Sprintf("%d görev eklendikten sonra hata: %v", result.Imported, err), http.StatusInternalServerError)
+				return
+			}
+			result.Imported += end - start
+		}
+		for _, v := range wipOverrides {
+			recordWIPOverride(token, access, v, nil)
+		}
+		if result.Imported > 0 {
+			GlobalHub.Publish(access.BoardID, "tasks_imported", map[string]interface{}{
+				"count":       result.Imported,
+				"imported_by": access.UserID,
+			})
+		}
+	} else {
+		result.Imported = len(tasks)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// readCSVUpload CSV içeriğini ham gövdeden veya multipart "file" alanından okur.
+// Multipart formda "mapping" alanı da verilebilir; yoksa query'deki mapping kullanılır.
+func readCSVUpload(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCSVSize)
+	mapping := r.URL.Query().Get("mapping")
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxCSVSize); err != nil {
+			return nil, "", fmt.Errorf("Geçersiz form: %v", err)
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("file alanı gerekli")
+		}
+		defer file.Close()
+		if m := r.FormValue("mapping"); m != "" {
+			mapping = m
+		}
+		body, err := io.ReadAll(file)
+		return body, mapping, err
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("Gövde okunamadı: %v", err)
+	}
+	return body, mapping, nil
+}
+
+// parseCSV BOM'u atar ve ayracı (virgül, noktalı virgül, tab) başlık satırından tahmin eder.
+func parseCSV(body []byte) ([][]string, error) {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+
+	header, _ := bufio.NewReader(bytes.NewReader(body)).ReadString('\n')
+	comma := ','
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		comma = ';'
+	}
+	if strings.Count(header, "\t") > strings.Count(header, string(comma)) {
+		comma = '\t'
+	}
+
+	cr := csv.NewReader(bytes.NewReader(body))
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return cr.ReadAll()
+}
+
+// mapCSVHeader başlıkları alanlara eşler; alan -> sütun indeksi ve başlık -> alan döndürür.
+// Elle verilen eşleme önceliklidir, boş alan ("") sütunu yok sayar.
+func mapCSVHeader(header []string, mapping map[string]string) (map[string]int, map[string]string) {
+	columns := make(map[string]int)
+	resolved := make(map[string]string)
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		field, ok := mapping[h]
+		if !ok {
+			field = csvFieldFor(h)
+		}
+		if field == "" {
+			continue
+		}
+		if _, dup := columns[field]; dup {
+			continue
+		}
+		columns[field] = i
+		resolved[h] = field
+	}
+	return columns, resolved
+}
+
+func csvFieldFor(header string) string {
+	h := strings.ToLower(header)
+	for field, aliases := range csvImportFields {
+		for _, a := range aliases {
+			if h == a {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// csvRowToTask satırı doğrulayıp göreve çevirir; satırdaki tüm hataları birlikte döndürür.
+func csvRowToTask(record []string, columns map[string]int, workflow Workflow, users map[string]string) (Task, []string) {
+	get := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return unescapeCSVFormula(strings.TrimSpace(record[i]))
+	}
+
+	var task Task
+	var errs []string
+
+	task.Title = get("title")
+	if task.Title == "" {
+		errs = append(errs, "başlık boş")
+	}
+
+	if d := get("description"); d != "" {
+		task.Description = &d
+	}
+
+	task.Status = get("status")
+	if task.Status == "" {
+		task.Status = firstStatus(workflow)
+	} else if key, ok := workflowStatusKey(workflow, task.Status); ok {
+		task.Status = key
+	} else {
+		errs = append(errs, fmt.Sprintf("tanımsız durum %q", task.Status))
+	}
+
+	if priority, ok := normalizePriority(get("priority")); ok {
+		task.Priority = priority
+	} else {
+		errs = append(errs, fmt.Sprintf("geçersiz öncelik %q (High/Yüksek, Medium/Orta, Low/Düşük)", get("priority")))
+	}
+
+	if due := get("due_date"); due != "" {
+		if normalized, ok := normalizeDate(due); ok {
+			task.DueDate = &normalized
+		} else {
+			errs = append(errs, fmt.Sprintf("geçersiz tarih %q", due))
+		}
+	}
+
+	if email := strings.ToLower(get("assignee")); email != "" {
+		if id, ok := users[email]; ok {
+			task.AssignedTo = &id
+		} else {
+			errs = append(errs, fmt.Sprintf("atanan kişi panonun üyesi değil: %s", email))
+		}
+	}
+
+	if pos := get("position"); pos != "" {
+		n, err := strconv.Atoi(pos)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("geçersiz sıra %q", pos))
+		}
+		task.Position = n
+	}
+
+	return task, errs
+}
+
+// normalizeDate YYYY-MM-DD, DD.MM.YYYY, DD/MM/YYYY ve RFC3339 tarihlerini YYYY-MM-DD'ye çevirir.
+func normalizeDate(value string) (string, bool) {
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "02/01/2006", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02"), true
+		}
+	}
+	return "", false
+}
+
+// workflowStatusKey durum anahtarını veya sütun başlığını (büyük/küçük harf duyarsız) anahtara çevirir.
+func workflowStatusKey(wf Workflow, value string) (string, bool) {
+	for _, s := range wf.Statuses {
+		if strings.EqualFold(s.Key, value) || strings.EqualFold(s.Title, value) {
+			return s.Key, true
+		}
+	}
+	return "", false
+}
+
+// firstStatus workflow'un ilk sütunu (Order'a göre)
+func firstStatus(wf Workflow) string {
+	first := ""
+	minOrder := 0
+	for i, s := range wf.Statuses {
+		if i == 0 || s.Order < minOrder {
+			first, minOrder = s.Key, s.Order
+		}
+	}
+	return first
+}
+
+// boardWorkflow panonun (gerekirse pano tipinden türetilmiş) sütunlarını yükler.
+func boardWorkflow(token, boardID string) (Workflow, error) {
+	board, err := loadBoard(token, boardID)
+	if err != nil {
+		return Workflow{}, err
+	}
+	return board.effectiveWorkflow(), nil
+}
@@ -0,0 +1,396 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DependencyRequest görev bağımlılığı ekleme/kaldırma isteği. task_id ile birlikte blocked_by
+// (task_id'yi engelleyen görev) veya blocks (task_id'nin engellediği görev) verilir.
+type DependencyRequest struct {
+	TaskID    string `json:"task_id"`
+	BlockedBy string `json:"blocked_by,omitempty"`
+	Blocks    string `json:"blocks,omitempty"`
+}
+
+// link isteği engelleyen -> engellenen çiftine çevirir.
+func (req DependencyRequest) link() (blocker, blocked string, err error) {
+	switch {
+	case req.TaskID == "":
+		return "", "", requestError("task_id gerekli")
+	case (req.BlockedBy == "") == (req.Blocks == ""):
+		return "", "", requestError("blocked_by veya blocks alanlarından biri gerekli")
+	case req.BlockedBy != "":
+		blocker, blocked = req.BlockedBy, req.TaskID
+	default:
+		blocker, blocked = req.TaskID, req.Blocks
+	}
+	if blocker == blocked {
+		return "", "", requestError("Görev kendisini engelleyemez")
+	}
+	return blocker, blocked, nil
+}
+
+// DependencyTask bağımlılığın diğer ucundaki görev. Okuma yetkisi olmayan panolardaki
+// görevlerin sadece ID'si döner (hidden=true).
+type DependencyTask struct {
+	TaskID    string `json:"task_id"`
+	BoardID   string `json:"board_id,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Open      bool   `json:"open"` // görev tamamlanma kategorisinde değil
+	Hidden    bool   `json:"hidden,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// TaskDependencies bir görevin bağımlılıkları
+type TaskDependencies struct {
+	TaskID    string           `json:"task_id"`
+	Blocked   bool             `json:"blocked"` // açık engelleyicisi var
+	BlockedBy []DependencyTask `json:"blocked_by"`
+	Blocks    []DependencyTask `json:"blocks"`
+}
+
+// openBlocker tamamlanmamış engelleyici görev (başlık okuma yetkisi yoksa boş)
+type openBlocker struct {
+	ID      string  `json:"id"`
+	BoardID string  `json:"board_id"`
+	Title   *string `json:"title"`
+	Status  string  `json:"status"`
+}
+
+// openBlockers görevin tamamlanmamış engelleyicilerini döndürür; erişilemeyen panolardakiler de sayılır.
+func openBlockers(token, taskID string) ([]openBlocker, error) {
+	resp, err := performSupabaseRequest("POST", "rpc/open_blockers", token, map[string]string{"p_task": taskID})
+	if err != nil {
+		return nil, err
+	}
+	var blockers []openBlocker
+	if err := json.Unmarshal(resp, &blockers); err != nil {
+		return nil, err
+	}
+	return blockers, nil
+}
+
+// dependencyViolation tamamlanma sütununa taşınmak istenen ama açık engelleyicisi olan görevler
+type dependencyViolation struct {
+	Status   string                   `json:"status"`
+	Blockers map[string][]openBlocker `json:"blockers"` // görev ID -> açık engelleyiciler
+}
+
+func (v *dependencyViolation) Error() string {
+	if len(v.Blockers) == 1 {
+		for _, blockers := range v.Blockers {
+			return fmt.Sprintf("Görevin %d açık engelleyicisi tamamlanmadan %q sütununa taşınamaz", len(blockers), v.Status)
+		}
+	}
+	return fmt.Sprintf("%d görev açık engelleyicileri tamamlanmadan %q sütununa taşınamaz", len(v.Blockers), v.Status)
+}
+
+// checkDependencies görevler status sütununa girdiğinde açık engelleyici kalıp kalmadığını kontrol eder.
+// Sadece tamamlanma kategorisindeki sütunlar engellenir.
+func checkDependencies(token string, wf Workflow, status string, taskIDs []string) (*dependencyViolation, error) {
+	if wf.Category(status) != CategoryDone {
+		return nil, nil
+	}
+	var violation *dependencyViolation
+	for _, id := range taskIDs {
+		blockers, err := openBlockers(token, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(blockers) == 0 {
+			continue
+		}
+		if violation == nil {
+			violation = &dependencyViolation{Status: status, Blockers: make(map[string][]openBlocker)}
+		}
+		violation.Blockers[id] = blockers
+	}
+	return violation, nil
+}
+
+// enforceDependencies engelleyicileri kontrol eder. İhlal varsa ve override istenmemişse 409 (engelleyicilerle) yazar.
+// Override pano sahibi ve yöneticilerine açıktır; kabul edilen ihlal yazma başarılı olduktan sonra
+// recordDependencyOverride ile duyurulmak üzere döndürülür. ok=false ise yanıt yazılmıştır.
+func enforceDependencies(w http.ResponseWriter, token string, access *BoardAccess, wf Workflow, status string, taskIDs []string, override bool) (*dependencyViolation, bool) {
+	violation, err := checkDependencies(token, wf, status, taskIDs)
+	if err != nil {
+		fmt.Println("Bağımlılık Kontrol Hatası:", err)
+		http.Error(w, "Görev bağımlılıkları kontrol edilemedi", http.StatusInternalServerError)
+		return nil, false
+	}
+	if violation == nil {
+		return nil, true
+	}
+
+	if override {
+		if !RoleAllows(access.Role, PermManageBoard) {
+			http.Error(w, "Bağımlılık engelini sadece pano sahibi veya yöneticisi aşabilir", http.StatusForbidden)
+			return nil, false
+		}
+		return violation, true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":        violation.Error(),
+		"dependencies": violation,
+	})
+	return nil, false
+}
+
+// recordDependencyOverride engelleyicisi açıkken tamamlanan görevleri panoya duyurur.
+func recordDependencyOverride(access *BoardAccess, v *dependencyViolation) {
+	if v == nil {
+		return
+	}
+	taskIDs := make([]string, 0, len(v.Blockers))
+	for id := range v.Blockers {
+		taskIDs = append(taskIDs, id)
+	}
+	GlobalHub.Publish(access.BoardID, "dependency_overridden", map[string]interface{}{
+		"status":   v.Status,
+		"task_ids": taskIDs,
+		"blockers": v.Blockers,
+		"by":       access.UserID,
+	})
+}
+
+// dependencyOverrideRequested query'de override_dependencies=true verilip verilmediği
+func dependencyOverrideRequested(r *http.Request) bool {
+	return r.URL.Query().Get("override_dependencies") == "true"
+}
+
+// loadDependencyTasks görevin bir yöndeki bağımlılıklarını yükler. column görevin yer aldığı kolon
+// (blocked_id: engelleyicileri, blocker_id: engellediklerini getirir).
+func loadDependencyTasks(token, taskID, column string, workflows map[string]Workflow) ([]DependencyTask, error) {
+	other := "blocker_id"
+	if column == "blocker_id" {
+		other = "blocked_id"
+	}
+	endpoint := fmt.Sprintf("task_dependencies?%s=eq.%s&select=%s,created_at,task:tasks!%s(id,board_id,title,status)&order=created_at.asc",
+		column, taskID, other, other)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+
+	result := make([]DependencyTask, 0, len(rows))
+	for _, row := range rows {
+		var dep DependencyTask
+		json.Unmarshal(row[other], &dep.TaskID)
+		json.Unmarshal(row["created_at"], &dep.CreatedAt)
+
+		var task *Task
+		json.Unmarshal(row["task"], &task)
+		if task == nil {
+			// RLS: diğer görevin panosunu okuyamıyoruz
+			dep.Hidden = true
+			result = append(result, dep)
+			continue
+		}
+		dep.BoardID, dep.Title, dep.Status = task.BoardID, task.Title, task.Status
+
+		wf, ok := workflows[task.BoardID]
+		if !ok {
+			if wf, err = boardWorkflow(token, task.BoardID); err != nil {
+				return nil, err
+			}
+			workflows[task.BoardID] = wf
+		}
+		dep.Open = wf.Category(task.Status) != CategoryDone
+		result = append(result, dep)
+	}
+	return result, nil
+}
+
+// GetTaskDependencies görevi engelleyen ve görevin engellediği görevleri döndürür (?task_id=).
+func GetTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	taskID := r.URL.Query().Get("task_id")
+	workflows := make(map[string]Workflow)
+
+	blockedBy, err := loadDependencyTasks(token, taskID, "blocked_id", workflows)
+	if err != nil {
+		fmt.Println("GetTaskDependencies Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	blocks, err := loadDependencyTasks(token, taskID, "blocker_id", workflows)
+	if err != nil {
+		fmt.Println("GetTaskDependencies Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Okunamayan panolardaki engelleyicilerin açık olup olmadığını sadece open_blockers bilir
+	open, err := openBlockers(token, taskID)
+	if err != nil {
+		fmt.Println("GetTaskDependencies Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	openIDs := make(map[string]bool, len(open))
+	for _, b := range open {
+		openIDs[b.ID] = true
+	}
+	for i := range blockedBy {
+		blockedBy[i].Open = openIDs[blockedBy[i].TaskID]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TaskDependencies{
+		TaskID:    taskID,
+		Blocked:   len(open) > 0,
+		BlockedBy: blockedBy,
+		Blocks:    blocks,
+	})
+}
+
+// CreateTaskDependency iki görev arasında engelleme ilişkisi kurar. Görevler farklı panolarda olabilir;
+// engellenen görevin panosunda düzenleme (BoardFromBlockedTaskBody), engelleyen görevin panosunda
+// en azından okuma yetkisi gerekir. Döngü oluşturan ilişkiler 409 döner.
+func CreateTaskDependency(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req DependencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	blocker, blocked, err := req.link()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// access engellenen görevin panosu; engelleyen görev okunabilmeli
+	other, err := lookupTaskBoard(token, blocker)
+	if err == errBoardNotFound {
+		http.Error(w, "Engelleyen görev bulunamadı", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		fmt.Println("CreateTaskDependency Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	row := map[string]string{
+		"blocker_id": blocker,
+		"blocked_id": blocked,
+		"created_by": access.UserID,
+	}
+	resp, err := performSupabaseRequest("POST", "task_dependencies", token, row)
+	if err != nil {
+		writeDependencyError(w, err)
+		return
+	}
+
+	event := map[string]interface{}{
+		"blocker_id": blocker,
+		"blocked_id": blocked,
+		"created_by": access.UserID,
+	}
+	GlobalHub.Publish(access.BoardID, "dependency_added", event)
+	if other.BoardID != access.BoardID {
+		GlobalHub.Publish(other.BoardID, "dependency_added", event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// writeDependencyError tekrar eden ve döngü oluşturan bağımlılıkları 409'a çevirir.
+func writeDependencyError(w http.ResponseWriter, err error) {
+	switch {
+	case supabaseStatus(err) == http.StatusConflict:
+		http.Error(w, "Bu bağımlılık zaten var", http.StatusConflict)
+	case strings.Contains(err.Error(), "dependency_cycle"):
+		http.Error(w, "Bu bağımlılık bir döngü oluşturur: engelleyici görev zaten bu göreve bağlı", http.StatusConflict)
+	case strings.Contains(err.Error(), "board is archived"):
+		http.Error(w, "Arşivlenmiş panodaki görevlere bağımlılık eklenemez", http.StatusConflict)
+	default:
+		fmt.Println("CreateTaskDependency Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeleteTaskDependency engelleme ilişkisini kaldırır (?task_id= ile blocked_by= veya blocks=).
+func DeleteTaskDependency(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	req := DependencyRequest{TaskID: query.Get("task_id"), BlockedBy: query.Get("blocked_by"), Blocks: query.Get("blocks")}
+	blocker, blocked, err := req.link()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("task_dependencies?blocker_id=eq.%s&blocked_id=eq.%s", blocker, blocked)
+	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("DeleteTaskDependency Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var deleted []json.RawMessage
+	if err := json.Unmarshal(resp, &deleted); err != nil || len(deleted) == 0 {
+		http.Error(w, "Bağımlılık bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	event := map[string]interface{}{
+		"blocker_id": blocker,
+		"blocked_id": blocked,
+		"deleted_by": access.UserID,
+	}
+	GlobalHub.Publish(access.BoardID, "dependency_removed", event)
+	otherID := blocker
+	if otherID == req.TaskID {
+		otherID = blocked
+	}
+	if other, err := lookupTaskBoard(token, otherID); err == nil && other.BoardID != access.BoardID {
+		GlobalHub.Publish(other.BoardID, "dependency_removed", event)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
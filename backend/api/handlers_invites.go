@@ -0,0 +1,226 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// BoardInvite süreli, kullanım sınırlı ve rol taşıyan davet linki
+type BoardInvite struct {
+	ID        string  `json:"id,omitempty"`
+	BoardID   string  `json:"board_id"`
+	Code      string  `json:"code,omitempty"`
+	Role      string  `json:"role"`
+	MaxUses   *int    `json:"max_uses,omitempty"`
+	UseCount  int     `json:"use_count"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+	RevokedAt *string `json:"revoked_at,omitempty"`
+	CreatedBy string  `json:"created_by,omitempty"`
+	CreatedAt string  `json:"created_at,omitempty"`
+}
+
+// CreateInviteRequest davet oluşturma isteği
+type CreateInviteRequest struct {
+	BoardID        string `json:"board_id"`
+	Role           string `json:"role"`             // varsayılan: member
+	MaxUses        *int   `json:"max_uses"`         // nil: sınırsız
+	ExpiresInHours int    `json:"expires_in_hours"` // 0: varsayılan süre
+}
+
+const (
+	defaultInviteLifetime = 7 * 24 * time.Hour
+	maxInviteLifetime     = 90 * 24 * time.Hour
+	inviteCodeLength      = 10
+	boardCodeLength       = 8
+)
+
+// inviteAlphabet karıştırılabilecek karakterleri (0/O, 1/I/L) içermez
+const inviteAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// generateInviteCode kriptografik olarak rastgele bir davet kodu üretir.
+func generateInviteCode(length int) (string, error) {
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := make([]byte, length)
+	for i, b := range buf {
+		code[i] = inviteAlphabet[int(b)%len(inviteAlphabet)]
+	}
+	return string(code), nil
+}
+
+// CreateBoardInvite panoya yeni bir davet linki oluşturur.
+func CreateBoardInvite(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.Role == "" {
+		req.Role = RoleMember
+	}
+	if !ValidMemberRole(req.Role) {
+		http.Error(w, "Geçersiz rol (admin, member, viewer)", http.StatusBadRequest)
+		return
+	}
+	if req.Role == RoleAdmin && access.Role != RoleOwner {
+		http.Error(w, "Admin daveti sadece pano sahibi oluşturabilir", http.StatusForbidden)
+		return
+	}
+	if req.MaxUses != nil && *req.MaxUses <= 0 {
+		http.Error(w, "max_uses pozitif olmalı", http.StatusBadRequest)
+		return
+	}
+
+	lifetime := defaultInviteLifetime
+	if req.ExpiresInHours > 0 {
+		lifetime = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if lifetime > maxInviteLifetime {
+		http.Error(w, "Davet süresi en fazla 90 gün olabilir", http.StatusBadRequest)
+		return
+	}
+
+	code, err := generateInviteCode(inviteCodeLength)
+	if err != nil {
+		http.Error(w, "Davet kodu üretilemedi", http.StatusInternalServerError)
+		return
+	}
+
+	invite := BoardInvite{
+		BoardID: access.BoardID,
+		Code:    code,
+		Role:    req.Role,
+		MaxUses: req.MaxUses,
+	}
+	expiresAt := time.Now().Add(lifetime).UTC().Format(time.RFC3339)
+	invite.ExpiresAt = &expiresAt
+
+	resp, err := performSupabaseRequest("POST", "board_invites", token, invite)
+	if err != nil {
+		fmt.Println("CreateBoardInvite Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// GetBoardInvites panonun davet linklerini listeler.
+func GetBoardInvites(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_invites?board_id=eq.%s&select=*&order=created_at.desc", access.BoardID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetBoardInvites Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// RevokeBoardInvite davet linkini iptal eder (üyelikler etkilenmez).
+func RevokeBoardInvite(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	inviteID := r.URL.Query().Get("id")
+	if inviteID == "" {
+		http.Error(w, "ID parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_invites?id=eq.%s&board_id=eq.%s&revoked_at=is.null", inviteID, access.BoardID)
+	body := map[string]string{"revoked_at": time.Now().UTC().Format(time.RFC3339)}
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, body)
+	if err != nil {
+		fmt.Println("RevokeBoardInvite Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var revoked []BoardInvite
+	if err := json.Unmarshal(resp, &revoked); err != nil || len(revoked) == 0 {
+		http.Error(w, "Davet bulunamadı veya zaten iptal edilmiş", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Davet iptal edildi", "details": string(resp)})
+}
+
+// RotateBoardInviteCode panonun varsayılan davet kodunu yeniler; eski kod artık çalışmaz.
+func RotateBoardInviteCode(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	code, err := generateInviteCode(boardCodeLength)
+	if err != nil {
+		http.Error(w, "Davet kodu üretilemedi", http.StatusInternalServerError)
+		return
+	}
+
+	endpoint := fmt.Sprintf("boards?id=eq.%s", access.BoardID)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"invite_code": code})
+	if err != nil {
+		fmt.Println("RotateBoardInviteCode Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
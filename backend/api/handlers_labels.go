@@ -0,0 +1,505 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxLabelNameLength = 50
+	defaultLabelColor  = "#6b7280"
+)
+
+// Etiket filtresi eşleşme kipleri (GetTasks ?labels_match=)
+const (
+	LabelsMatchAny = "any"
+	LabelsMatchAll = "all"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Label panoya ait etiket. Görevlere gömülü gelirken sadece id, name ve color dolu olur.
+type Label struct {
+	ID        string `json:"id"`
+	BoardID   string `json:"board_id,omitempty"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// LabelRequest etiket oluşturma/güncelleme isteği (güncellemede sadece gönderilen alanlar değişir)
+type LabelRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// TaskLabelsRequest görev etiketleri isteği. PUT'ta label_ids görevin tüm etiketleridir,
+// POST'ta label_id tek bir etiket ekler.
+type TaskLabelsRequest struct {
+	TaskID   string   `json:"task_id"`
+	LabelID  string   `json:"label_id,omitempty"`
+	LabelIDs []string `json:"label_ids,omitempty"`
+}
+
+// labelFilter GetTasks etiket filtresi; değerler etiket ID'si veya adı olabilir
+type labelFilter struct {
+	values []string
+	all    bool
+}
+
+// parseLabelFilter ?labels=bug,frontend&labels_match=any|all parametrelerini okur.
+func parseLabelFilter(query url.Values) (*labelFilter, error) {
+	raw := query.Get("labels")
+	if raw == "" {
+		return nil, nil
+	}
+	filter := &labelFilter{}
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			filter.values = append(filter.values, v)
+		}
+	}
+	if len(filter.values) == 0 {
+		return nil, nil
+	}
+	switch query.Get("labels_match") {
+	case "", LabelsMatchAny:
+	case LabelsMatchAll:
+		filter.all = true
+	default:
+		return nil, requestError("labels_match any veya all olmalı")
+	}
+	return filter, nil
+}
+
+// matches görevin etiketlerinin filtreye uyup uymadığı
+func (f *labelFilter) matches(t Task) bool {
+	found := 0
+	for _, v := range f.values {
+		for _, l := range t.Labels {
+			if strings.ToLower(l.ID) == v || strings.ToLower(l.Name) == v {
+				found++
+				break
+			}
+		}
+		if found > 0 && !f.all {
+			return true
+		}
+	}
+	return f.all && found == len(f.values)
+}
+
+// filterTasksByLabels filtreye uyan görevleri sırasını koruyarak döndürür.
+func filterTasksByLabels(tasks []Task, filter *labelFilter) []Task {
+	if filter == nil {
+		return tasks
+	}
+	result := make([]Task, 0, len(tasks))
+	for _, t := range tasks {
+		if filter.matches(t) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// validateLabelName etiket adını doğrular ve kırpılmış halini döndürür.
+func validateLabelName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("Etiket adı boş olamaz")
+	}
+	if len([]rune(name)) > maxLabelNameLength {
+		return "", fmt.Errorf("Etiket adı en fazla %d karakter olabilir", maxLabelNameLength)
+	}
+	return name, nil
+}
+
+// validateLabelColor rengi doğrular (boşsa varsayılan renk).
+func validateLabelColor(color string) (string, error) {
+	color = strings.TrimSpace(color)
+	if color == "" {
+		return defaultLabelColor, nil
+	}
+	if !labelColorPattern.MatchString(color) {
+		return "", fmt.Errorf("Etiket rengi #rgb veya #rrggbb formatında olmalı")
+	}
+	return strings.ToLower(color), nil
+}
+
+// boardLabelIDs panodaki etiket ID'leri
+func boardLabelIDs(token, boardID string) (map[string]bool, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("board_labels?board_id=eq.%s&select=id", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var labels []Label
+	if err := json.Unmarshal(resp, &labels); err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		ids[l.ID] = true
+	}
+	return ids, nil
+}
+
+// writeLabelError etiket yazma hatasını döndürür (aynı isim 409).
+func writeLabelError(w http.ResponseWriter, handler string, err error) {
+	if supabaseStatus(err) == http.StatusConflict {
+		http.Error(w, "Bu panoda aynı isimde bir etiket zaten var", http.StatusConflict)
+		return
+	}
+	fmt.Println(handler+" Hatası:", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// GetBoardLabels panonun etiketlerini isme göre sıralı döndürür.
+func GetBoardLabels(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_labels?board_id=eq.%s&select=id,board_id,name,color,created_at&order=name.asc", access.BoardID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetBoardLabels Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// CreateBoardLabel panoya yeni etiket ekler.
+func CreateBoardLabel(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	var name, color string
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Color != nil {
+		color = *req.Color
+	}
+	name, err := validateLabelName(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if color, err = validateLabelColor(color); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	label := map[string]string{"board_id": access.BoardID, "name": name, "color": color}
+	resp, err := performSupabaseRequest("POST", "board_labels?select=id,board_id,name,color,created_at", token, label)
+	if err != nil {
+		writeLabelError(w, "CreateBoardLabel", err)
+		return
+	}
+	var created []Label
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "labels_updated", map[string]interface{}{
+		"created":    created[0],
+		"updated_by": access.UserID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created[0])
+}
+
+// UpdateBoardLabel etiketin adını ve/veya rengini değiştirir (?id=).
+func UpdateBoardLabel(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	labelID := r.URL.Query().Get("id")
+	if labelID == "" {
+		http.Error(w, "ID parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.Name == nil && req.Color == nil {
+		http.Error(w, "Değiştirilecek alan yok", http.StatusBadRequest)
+		return
+	}
+
+	update := make(map[string]string)
+	if req.Name != nil {
+		name, err := validateLabelName(*req.Name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		update["name"] = name
+	}
+	if req.Color != nil {
+		color, err := validateLabelColor(*req.Color)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		update["color"] = color
+	}
+
+	endpoint := fmt.Sprintf("board_labels?id=eq.%s&board_id=eq.%s&select=id,board_id,name,color,created_at", labelID, access.BoardID)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, update)
+	if err != nil {
+		writeLabelError(w, "UpdateBoardLabel", err)
+		return
+	}
+	var updated []Label
+	if err := json.Unmarshal(resp, &updated); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(updated) == 0 {
+		http.Error(w, "Etiket bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "labels_updated", map[string]interface{}{
+		"updated":    updated[0],
+		"updated_by": access.UserID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated[0])
+}
+
+// DeleteBoardLabel etiketi siler (?id=); görevlerden de kaldırılır.
+func DeleteBoardLabel(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	labelID := r.URL.Query().Get("id")
+	if labelID == "" {
+		http.Error(w, "ID parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_labels?id=eq.%s&board_id=eq.%s", labelID, access.BoardID)
+	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("DeleteBoardLabel Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var deleted []Label
+	if err := json.Unmarshal(resp, &deleted); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(deleted) == 0 {
+		http.Error(w, "Etiket bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "labels_updated", map[string]interface{}{
+		"deleted":    labelID,
+		"updated_by": access.UserID,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetTaskLabels görevin etiketlerini verilen listeyle değiştirir (PUT) veya tek etiket ekler (POST).
+func SetTaskLabels(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req TaskLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+
+	var labelIDs []string
+	if r.Method == http.MethodPost {
+		if req.LabelID == "" {
+			http.Error(w, "label_id gerekli", http.StatusBadRequest)
+			return
+		}
+		labelIDs = []string{req.LabelID}
+	} else {
+		if req.LabelIDs == nil {
+			http.Error(w, "label_ids gerekli (etiketleri kaldırmak için boş liste)", http.StatusBadRequest)
+			return
+		}
+		seen := make(map[string]bool, len(req.LabelIDs))
+		for _, id := range req.LabelIDs {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				labelIDs = append(labelIDs, id)
+			}
+		}
+	}
+
+	if len(labelIDs) > 0 {
+		valid, err := boardLabelIDs(token, access.BoardID)
+		if err != nil {
+			fmt.Println("SetTaskLabels Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, id := range labelIDs {
+			if !valid[id] {
+				http.Error(w, "Etiket bu panoya ait değil: "+id, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	var err error
+	if r.Method == http.MethodPost {
+		_, err = performSupabaseRequest("POST", "task_labels", token,
+			map[string]string{"task_id": req.TaskID, "label_id": labelIDs[0]})
+		if supabaseStatus(err) == http.StatusConflict {
+			err = nil // zaten ekli
+		}
+	} else {
+		if labelIDs == nil {
+			labelIDs = []string{}
+		}
+		_, err = performSupabaseRequest("POST", "rpc/set_task_labels", token,
+			map[string]interface{}{"p_task": req.TaskID, "p_labels": labelIDs})
+	}
+	if err != nil {
+		fmt.Println("SetTaskLabels Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeTaskLabels(w, token, access, req.TaskID)
+}
+
+// RemoveTaskLabel görevden bir etiketi kaldırır (?task_id=&label_id=).
+func RemoveTaskLabel(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	taskID := r.URL.Query().Get("task_id")
+	labelID := r.URL.Query().Get("label_id")
+	if labelID == "" {
+		http.Error(w, "label_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("task_labels?task_id=eq.%s&label_id=eq.%s", taskID, labelID)
+	if _, err := performSupabaseRequest("DELETE", endpoint, token, nil); err != nil {
+		fmt.Println("RemoveTaskLabel Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeTaskLabels(w, token, access, taskID)
+}
+
+// writeTaskLabels görevin güncel etiketlerini döndürür ve panoya bildirir.
+func writeTaskLabels(w http.ResponseWriter, token string, access *BoardAccess, taskID string) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("tasks?id=eq.%s&select=id,labels:board_labels(id,name,color)", taskID), token, nil)
+	if err != nil {
+		fmt.Println("TaskLabels Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil || len(tasks) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	labels := tasks[0].Labels
+	if labels == nil {
+		labels = []Label{}
+	}
+
+	GlobalHub.Publish(access.BoardID, "task_labels_updated", map[string]interface{}{
+		"task_id":    taskID,
+		"labels":     labels,
+		"updated_by": access.UserID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"task_id": taskID, "labels": labels})
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Görev atamaları için politikalar (ayrılan kullanıcıya atanmış görevler)
+const (
+	AssignmentUnassign = "unassign" // atamayı kaldır
+	AssignmentReassign = "reassign" // pano sahibine (devirde yeni sahibe) ata
+	AssignmentKeep     = "keep"     // dokunma (sadece sahiplik devrinde)
+)
+
+// TransferOwnershipRequest sahiplik devri isteği
+type TransferOwnershipRequest struct {
+	BoardID     string `json:"board_id"`
+	UserID      string `json:"user_id"`
+	Assignments string `json:"assignments"` // keep (varsayılan), unassign, reassign
+}
+
+// MemberChangeResult üyelik değişikliği RPC sonuçları
+type MemberChangeResult struct {
+	RemovedUser     string `json:"removed_user,omitempty"`
+	PreviousOwner   string `json:"previous_owner,omitempty"`
+	NewOwner        string `json:"new_owner,omitempty"`
+	ReassignedTasks int    `json:"reassigned_tasks"`
+}
+
+// assignmentPolicy query'den politikayı okur; boşsa varsayılanı döner.
+func assignmentPolicy(value, fallback string, allowKeep bool) (string, bool) {
+	if value == "" {
+		return fallback, true
+	}
+	switch value {
+	case AssignmentUnassign, AssignmentReassign:
+		return value, true
+	case AssignmentKeep:
+		return value, allowKeep
+	}
+	return "", false
+}
+
+// writeRPCError RPC hatalarını HTTP durum koduna çevirir.
+func writeRPCError(w http.ResponseWriter, name string, err error) {
+	fmt.Printf("%s Hatası: %v\n", name, err)
+	if strings.Contains(err.Error(), "workspace_access") {
+		http.Error(w, "Kullanıcının bu panoya erişimi çalışma alanı üyeliğinden geliyor; erişimi kaldırmak için çalışma alanından çıkarılmalı", http.StatusConflict)
+		return
+	}
+	switch supabaseStatus(err) {
+	case http.StatusNotFound:
+		http.Error(w, "Üye bulunamadı", http.StatusNotFound)
+	case http.StatusForbidden, http.StatusUnauthorized:
+		http.Error(w, "Bu işlem için yetkiniz yok", http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// removeMember ortak RPC çağrısı (çıkarma ve ayrılma)
+func removeMember(token, boardID, userID, policy string) (*MemberChangeResult, error) {
+	body := map[string]string{"p_board": boardID, "p_user": userID, "p_policy": policy}
+	resp, err := performSupabaseRequest("POST", "rpc/remove_board_member", token, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []MemberChangeResult
+	if err := json.Unmarshal(resp, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, &SupabaseError{StatusCode: http.StatusNotFound, Body: "member not found"}
+	}
+	return &results[0], nil
+}
+
+// RemoveBoardMember bir üyeyi panodan çıkarır, atamalarını politikaya göre günceller
+// ve açık gerçek zamanlı bağlantılarını keser. Erişimi panonun çalışma alanından gelen kullanıcılar
+// panodan çıkarılamaz (409); çalışma alanından çıkarılmaları gerekir.
+func RemoveBoardMember(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+	if userID == access.UserID {
+		http.Error(w, "Kendinizi çıkarmak için /boards/leave kullanın", http.StatusBadRequest)
+		return
+	}
+
+	policy, ok := assignmentPolicy(r.URL.Query().Get("assignments"), AssignmentUnassign, false)
+	if !ok {
+		http.Error(w, "Geçersiz atama politikası (unassign, reassign)", http.StatusBadRequest)
+		return
+	}
+
+	result, err := removeMember(token, access.BoardID, userID, policy)
+	if err != nil {
+		writeRPCError(w, "RemoveBoardMember", err)
+		return
+	}
+
+	GlobalHub.DisconnectBoard(access.BoardID, userID, "", "removed from board")
+	GlobalHub.Publish(access.BoardID, "member_removed", map[string]interface{}{
+		"user_id":          userID,
+		"removed_by":       access.UserID,
+		"assignments":      policy,
+		"reassigned_tasks": result.ReassignedTasks,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// LeaveBoard çağıranın panodan ayrılmasını sağlar. Sahip önce sahipliği devretmelidir; erişimi
+// çalışma alanından gelenler çalışma alanından ayrılmalıdır (409).
+func LeaveBoard(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+	if access.Role == RoleOwner {
+		http.Error(w, "Pano sahibi ayrılamaz; önce sahipliği devredin", http.StatusConflict)
+		return
+	}
+
+	policy, ok := assignmentPolicy(r.URL.Query().Get("assignments"), AssignmentUnassign, false)
+	if !ok {
+		http.Error(w, "Geçersiz atama politikası (unassign, reassign)", http.StatusBadRequest)
+		return
+	}
+
+	result, err := removeMember(token, access.BoardID, access.UserID, policy)
+	if err != nil {
+		writeRPCError(w, "LeaveBoard", err)
+		return
+	}
+
+	GlobalHub.DisconnectBoard(access.BoardID, access.UserID, "", "left board")
+	GlobalHub.Publish(access.BoardID, "member_left", map[string]interface{}{
+		"user_id":          access.UserID,
+		"assignments":      policy,
+		"reassigned_tasks": result.ReassignedTasks,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// TransferBoardOwnership panonun sahipliğini başka bir üyeye devreder.
+// Eski sahip panoda admin olarak kalır. Eski sahibin kişisel çalışma alanındaki pano yeni sahibin
+// kişisel çalışma alanına taşınır.
+func TransferBoardOwnership(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+	if access.Role != RoleOwner {
+		http.Error(w, "Sahipliği sadece pano sahibi devredebilir", http.StatusForbidden)
+		return
+	}
+
+	var req TransferOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" || req.UserID == access.UserID {
+		http.Error(w, "Geçerli bir user_id gerekli", http.StatusBadRequest)
+		return
+	}
+
+	policy, ok := assignmentPolicy(req.Assignments, AssignmentKeep, true)
+	if !ok {
+		http.Error(w, "Geçersiz atama politikası (keep, unassign, reassign)", http.StatusBadRequest)
+		return
+	}
+
+	body := map[string]string{"p_board": access.BoardID, "p_new_owner": req.UserID, "p_policy": policy}
+	resp, err := performSupabaseRequest("POST", "rpc/transfer_board_ownership", token, body)
+	if err != nil {
+		writeRPCError(w, "TransferBoardOwnership", err)
+		return
+	}
+
+	var results []MemberChangeResult
+	if err := json.Unmarshal(resp, &results); err != nil || len(results) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "ownership_transferred", map[string]interface{}{
+		"previous_owner":   results[0].PreviousOwner,
+		"new_owner":        results[0].NewOwner,
+		"assignments":      policy,
+		"reassigned_tasks": results[0].ReassignedTasks,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results[0])
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Düz metin biçimleri
+const (
+	FormatTodoTxt  = "todotxt"
+	FormatMarkdown = "markdown"
+)
+
+// PlainTextImportResult todo.txt / Markdown içe aktarma raporu
+type PlainTextImportResult struct {
+	DryRun   bool             `json:"dry_run"`
+	Imported int              `json:"imported"`
+	Subtasks int              `json:"subtasks"`
+	Failed   []PlainTextError `json:"failed"`
+	Tasks    []Task           `json:"tasks,omitempty"` // dry_run'da eklenecek görevler
+}
+
+// ExportTasksTodoTxt bir panonun (board_id) veya çağırana atanmış görevlerin (mine=true) todo.txt çıktısı.
+func ExportTasksTodoTxt(w http.ResponseWriter, r *http.Request) {
+	exportPlainText(w, r, FormatTodoTxt)
+}
+
+// ExportTasksMarkdown bir panonun (board_id) veya çağırana atanmış görevlerin (mine=true) Markdown checklist'i.
+func ExportTasksMarkdown(w http.ResponseWriter, r *http.Request) {
+	exportPlainText(w, r, FormatMarkdown)
+}
+
+func exportPlainText(w http.ResponseWriter, r *http.Request, format string) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+
+	query := url.Values{}
+	title := "Görevlerim"
+	filename := "my-tasks"
+	switch {
+	case r.URL.Query().Get("board_id") != "":
+		query.Set("board_id", r.URL.Query().Get("board_id"))
+		filename = "board-" + query.Get("board_id")
+	case r.URL.Query().Get("mine") == "true":
+		query.Set("assigned_to", principal.UserID)
+	default:
+		http.Error(w, "board_id veya mine=true gerekli", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := loadTasks(token, query)
+	if _, ok := err.(requestError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		fmt.Println("ExportTasks Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, t := range tasks {
+		if !seen[t.BoardID] {
+			seen[t.BoardID] = true
+			ids = append(ids, t.BoardID)
+		}
+	}
+	if id := query.Get("board_id"); id != "" && !seen[id] {
+		ids = append(ids, id)
+	}
+	boards, err := loadPlainBoards(token, ids)
+	if err != nil {
+		fmt.Println("ExportTasks Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if id := query.Get("board_id"); id != "" {
+		title = boards[id].Title
+	}
+
+	switch format {
+	case FormatTodoTxt:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.todo.txt"`, filename))
+		io.WriteString(w, encodeTodoTxt(tasks, boards))
+	case FormatMarkdown:
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.md"`, filename))
+		io.WriteString(w, encodeMarkdown(title, tasks, boards))
+	}
+}
+
+// loadPlainBoards panoların başlık ve sütunlarını yükler.
+func loadPlainBoards(token string, ids []string) (map[string]plainBoard, error) {
+	boards := make(map[string]plainBoard, len(ids))
+	if len(ids) == 0 {
+		return boards, nil
+	}
+
+	endpoint := fmt.Sprintf("boards?id=in.(%s)&select=id,title,type,workflow", strings.Join(ids, ","))
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []Board
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	for _, b := range rows {
+		wf := Workflow{}
+		if b.Workflow != nil {
+			wf = *b.Workflow
+		}
+		boards[b.ID] = plainBoard{ID: b.ID, Title: b.Title, Workflow: effectiveWorkflow(b.Type, wf)}
+	}
+	return boards, nil
+}
+
+// ImportTasksTodoTxt todo.txt içeriğini (ham gövde) board_id panosuna görev ve alt görev olarak ekler.
+func ImportTasksTodoTxt(w http.ResponseWriter, r *http.Request) {
+	importPlainText(w, r, FormatTodoTxt)
+}
+
+// ImportTasksMarkdown Markdown checklist'i (ham gövde) board_id panosuna görev ve alt görev olarak ekler.
+func ImportTasksMarkdown(w http.ResponseWriter, r *http.Request) {
+	importPlainText(w, r, FormatMarkdown)
+}
+
+// importPlainText okunamayan satırları atlayıp raporlar; ?dry_run=true hiçbir şey eklemez.
+func importPlainText(w http.ResponseWriter, r *http.Request, format string) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "board_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCSVSize))
+	if err != nil {
+		http.Error(w, "Gövde okunamadı: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	boards, err := loadPlainBoards(token, []string{access.BoardID})
+	if err != nil {
+		fmt.Println("ImportTasks Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	board, ok := boards[access.BoardID]
+	if !ok {
+		http.Error(w, "Pano bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	var tasks []Task
+	var failed []PlainTextError
+	switch format {
+	case FormatTodoTxt:
+		tasks, failed = decodeTodoTxt(string(body), board.Workflow, board.Title)
+	case FormatMarkdown:
+		tasks, failed = decodeMarkdown(string(body), board.Workflow)
+	}
+
+	result := PlainTextImportResult{
+		DryRun: r.URL.Query().Get("dry_run") == "true",
+		Failed: failed,
+	}
+	if result.Failed == nil {
+		result.Failed = []PlainTextError{}
+	}
+
+	if result.DryRun {
+		result.Imported = len(tasks)
+		for _, t := range tasks {
+			result.Subtasks += len(t.Subtasks)
+		}
+		result.Tasks = tasks
+	} else if len(tasks) > 0 {
+		wipOverrides, ok := enforceWIPByStatus(w, token, access, board.Workflow, tasks, wipOverrideRequested(r))
+		if !ok {
+			return
+		}
+		inserted, err := insertTasks(token, access.BoardID, tasks, nil)
+		if err != nil {
+			fmt.Println("ImportTasks Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, v := range wipOverrides {
+			recordWIPOverride(token, access, v, nil)
+		}
+		result.Imported = inserted.Tasks
+		result.Subtasks = inserted.Subtasks
+		GlobalHub.Publish(access.BoardID, "tasks_imported", map[string]interface{}{
+			"source":      format,
+			"count":       inserted.Tasks,
+			"imported_by": access.UserID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
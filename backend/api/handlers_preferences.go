@@ -0,0 +1,287 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Bildirim seviyeleri (board_preferences.notification_level)
+const (
+	NotifyAll      = "all"
+	NotifyMentions = "mentions"
+	NotifyNone     = "none"
+)
+
+// taskSortOrders panoda görevler için seçilebilecek varsayılan sıralamalar
+var taskSortOrders = []string{"priority", "due_date", "position", "title", "created_at"}
+
+// BoardPreferences kullanıcının bir pano için kişisel tercihleri (cihazlar arası senkron)
+type BoardPreferences struct {
+	BoardID           string  `json:"board_id"`
+	Pinned            bool    `json:"pinned"`
+	SortOrder         *int    `json:"sort_order"`
+	Hidden            bool    `json:"hidden"`
+	DefaultView       *string `json:"default_view"`
+	DefaultSort       *string `json:"default_sort"`
+	NotificationLevel string  `json:"notification_level"`
+	UpdatedAt         string  `json:"updated_at,omitempty"`
+}
+
+// BoardOrderRequest panoların elle sıralanması
+type BoardOrderRequest struct {
+	BoardIDs []string `json:"board_ids"`
+}
+
+// defaultBoardPreferences kaydı olmayan panolar için varsayılanlar
+func defaultBoardPreferences(boardID string) BoardPreferences {
+	return BoardPreferences{BoardID: boardID, NotificationLevel: NotifyAll}
+}
+
+// loadBoardPreferences kullanıcının tüm pano tercihlerini pano ID'sine göre döndürür.
+func loadBoardPreferences(token, userID string) (map[string]BoardPreferences, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("board_preferences?user_id=eq.%s&select=*", userID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []BoardPreferences
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	prefs := make(map[string]BoardPreferences, len(rows))
+	for _, p := range rows {
+		prefs[p.BoardID] = p
+	}
+	return prefs, nil
+}
+
+// applyBoardPreferences panolara kullanıcının tercihlerini ekler ("preferences" alanı), gizlenenleri
+// (includeHidden değilse) çıkarır ve sıralar: sabitlenenler önce, sonra elle verilen sıra, sonra
+// gelen sıra (created_at desc).
+func applyBoardPreferences(boards []json.RawMessage, prefs map[string]BoardPreferences, includeHidden bool) ([]json.RawMessage, error) {
+	type entry struct {
+		raw   json.RawMessage
+		prefs BoardPreferences
+	}
+	entries := make([]entry, 0, len(boards))
+	for _, raw := range boards {
+		var board map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &board); err != nil {
+			return nil, err
+		}
+		var id string
+		json.Unmarshal(board["id"], &id)
+
+		p, ok := prefs[id]
+		if !ok {
+			p = defaultBoardPreferences(id)
+		}
+		if p.Hidden && !includeHidden {
+			continue
+		}
+
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		board["preferences"] = encoded
+		out, err := json.Marshal(board)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{raw: out, prefs: p})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].prefs, entries[j].prefs
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if (a.SortOrder == nil) != (b.SortOrder == nil) {
+			return a.SortOrder != nil
+		}
+		if a.SortOrder != nil && *a.SortOrder != *b.SortOrder {
+			return *a.SortOrder < *b.SortOrder
+		}
+		return false
+	})
+
+	result := make([]json.RawMessage, len(entries))
+	for i, e := range entries {
+		result[i] = e.raw
+	}
+	return result, nil
+}
+
+// GetBoardPreferences kullanıcının panodaki tercihlerini getirir (kayıt yoksa varsayılanlar).
+func GetBoardPreferences(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_preferences?user_id=eq.%s&board_id=eq.%s&select=*", access.UserID, access.BoardID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetBoardPreferences Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var rows []BoardPreferences
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	prefs := defaultBoardPreferences(access.BoardID)
+	if len(rows) > 0 {
+		prefs = rows[0]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// UpdateBoardPreferences kullanıcının panodaki tercihlerini günceller. Sadece gönderilen alanlar
+// değişir; sort_order, default_view ve default_sort null ile temizlenir.
+func UpdateBoardPreferences(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if err := validatePreferencesPatch(patch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body := map[string]interface{}{"p_board": access.BoardID, "p_prefs": patch}
+	resp, err := performSupabaseRequest("POST", "rpc/set_board_preferences", token, body)
+	if err != nil {
+		fmt.Println("UpdateBoardPreferences Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var rows []BoardPreferences
+	if err := json.Unmarshal(resp, &rows); err != nil || len(rows) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rows[0])
+}
+
+// validatePreferencesPatch tercih güncellemesindeki alanları ve değerleri doğrular.
+func validatePreferencesPatch(patch map[string]json.RawMessage) error {
+	if len(patch) == 0 {
+		return fmt.Errorf("Değiştirilecek alan yok")
+	}
+	for key, raw := range patch {
+		isNull := string(raw) == "null"
+		switch key {
+		case "pinned", "hidden":
+			var v bool
+			if err := json.Unmarshal(raw, &v); err != nil || isNull {
+				return fmt.Errorf("%s true veya false olmalı", key)
+			}
+		case "sort_order":
+			var v int
+			if !isNull {
+				if err := json.Unmarshal(raw, &v); err != nil || v < 0 {
+					return fmt.Errorf("sort_order negatif olmayan bir sayı olmalı")
+				}
+			}
+		case "default_view":
+			var v string
+			if !isNull && (json.Unmarshal(raw, &v) != nil || !containsString(boardViews, v)) {
+				return fmt.Errorf("Geçersiz varsayılan görünüm (%s)", strings.Join(boardViews, ", "))
+			}
+		case "default_sort":
+			var v string
+			if !isNull && (json.Unmarshal(raw, &v) != nil || !containsString(taskSortOrders, v)) {
+				return fmt.Errorf("Geçersiz varsayılan sıralama (%s)", strings.Join(taskSortOrders, ", "))
+			}
+		case "notification_level":
+			var v string
+			if json.Unmarshal(raw, &v) != nil || (v != NotifyAll && v != NotifyMentions && v != NotifyNone) {
+				return fmt.Errorf("notification_level all, mentions veya none olmalı")
+			}
+		default:
+			return fmt.Errorf("Bilinmeyen alan: %s", key)
+		}
+	}
+	return nil
+}
+
+// UpdateBoardOrder panoların kişisel sırasını verilen sıraya göre ayarlar.
+func UpdateBoardOrder(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	var req BoardOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if len(req.BoardIDs) == 0 {
+		http.Error(w, "board_ids gerekli", http.StatusBadRequest)
+		return
+	}
+	seen := make(map[string]bool, len(req.BoardIDs))
+	for _, id := range req.BoardIDs {
+		if id == "" || seen[id] {
+			http.Error(w, "board_ids boş veya tekrar eden ID içeremez", http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+	}
+
+	// Erişilemeyen panolar board_preferences RLS'ine takılır ve tüm istek geri alınır
+	resp, err := performSupabaseRequest("POST", "rpc/set_board_order", token, map[string]interface{}{"p_boards": req.BoardIDs})
+	if err != nil {
+		fmt.Println("UpdateBoardOrder Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"updated": json.RawMessage(resp)})
+}
+
+// containsString değerin listede olup olmadığı
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,434 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-panel/backend/rrule"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPreviewCount = 5
+	maxPreviewCount     = 50
+	maxSkipCount        = 50
+)
+
+// RecurrenceRequest görevi tekrarlayan yapma/kuralını değiştirme isteği.
+// Start (DTSTART) RFC 3339 veya saat dilimindeki yerel "2006-01-02T15:04" / "2006-01-02" olabilir;
+// verilmezse yeni serilerde şimdiki zaman, mevcut serilerde görevin tekrar zamanı kullanılır.
+type RecurrenceRequest struct {
+	TaskID   string `json:"task_id"`
+	Rule     string `json:"rule"`
+	Timezone string `json:"timezone"`
+	Start    string `json:"start,omitempty"`
+}
+
+// RecurrenceActionRequest seriyi atlatma/durdurma isteği
+type RecurrenceActionRequest struct {
+	TaskID string `json:"task_id"`
+	Count  int    `json:"count,omitempty"` // skip: atlanacak tekrar sayısı (varsayılan 1)
+}
+
+// RecurrenceResponse seri ve sıradaki tekrarları
+type RecurrenceResponse struct {
+	Recurrence *TaskRecurrence `json:"recurrence"`
+	Upcoming   []time.Time     `json:"upcoming"`
+	Skipped    []time.Time     `json:"skipped,omitempty"`
+}
+
+// parseRecurrenceStart başlangıcı RFC 3339 veya loc'taki yerel tarih/saat olarak okur.
+func parseRecurrenceStart(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, requestError(fmt.Sprintf("Geçersiz başlangıç %q (RFC 3339, 2006-01-02T15:04 veya 2006-01-02)", value))
+}
+
+// previewCount count parametresini okur (varsayılan 5, en fazla 50).
+func previewCount(value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return defaultPreviewCount
+	}
+	if n > maxPreviewCount {
+		return maxPreviewCount
+	}
+	return n
+}
+
+// taskRecurrence görevin serisini ve görevin temsil ettiği tekrar zamanını yükler.
+func taskRecurrence(token, taskID string) (*TaskRecurrence, *Task, error) {
+	endpoint := fmt.Sprintf("tasks?id=eq.%s&select=id,board_id,recurrence_id,occurrence_at", taskID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		return nil, nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, nil, errBoardNotFound
+	}
+	if tasks[0].RecurrenceID == nil {
+		return nil, &tasks[0], errRecurrenceNotFound
+	}
+	rec, err := loadRecurrence(token, *tasks[0].RecurrenceID)
+	return rec, &tasks[0], err
+}
+
+// upcomingOccurrences serinin henüz oluşturulmamış sıradaki tekrarları (next_at dahil)
+func upcomingOccurrences(rec *TaskRecurrence, n int) ([]time.Time, error) {
+	upcoming := []time.Time{}
+	if !rec.active() {
+		return upcoming, nil
+	}
+	schedule, err := rec.schedule()
+	if err != nil {
+		return nil, err
+	}
+	next, err := time.Parse(time.RFC3339, *rec.NextAt)
+	if err != nil {
+		return nil, err
+	}
+	return append(upcoming, schedule.After(next.Add(-time.Second), n)...), nil
+}
+
+// writeRecurrence seriyi sıradaki tekrarlarıyla yazar.
+func writeRecurrence(w http.ResponseWriter, status int, rec *TaskRecurrence, n int, skipped []time.Time) {
+	upcoming, err := upcomingOccurrences(rec, n)
+	if err != nil {
+		http.Error(w, "Tekrar kuralı okunamadı: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(RecurrenceResponse{Recurrence: rec, Upcoming: upcoming, Skipped: skipped})
+}
+
+// writeRecurrenceError seri bulunamadı hatalarını 404'e çevirir.
+func writeRecurrenceError(w http.ResponseWriter, name string, err error) {
+	switch err {
+	case errRecurrenceNotFound, errBoardNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		fmt.Println(name+" Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GetTaskRecurrence görevin serisini ve sıradaki tekrarları döndürür (?task_id=&count=).
+func GetTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	rec, _, err := taskRecurrence(token, r.URL.Query().Get("task_id"))
+	if err != nil {
+		writeRecurrenceError(w, "GetTaskRecurrence", err)
+		return
+	}
+	writeRecurrence(w, http.StatusOK, rec, previewCount(r.URL.Query().Get("count")), nil)
+}
+
+// PreviewRecurrence bir kuralın tekrarlarını kaydetmeden hesaplar (?rule=&timezone=&start=&count=).
+func PreviewRecurrence(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	loc, err := rrule.LoadLocation(query.Get("timezone"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start := time.Now()
+	if value := query.Get("start"); value != "" {
+		if start, err = parseRecurrenceStart(value, loc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	schedule, err := rrule.New(query.Get("rule"), query.Get("timezone"), start)
+	if err != nil {
+		http.Error(w, "Geçersiz tekrar kuralı: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	occurrences := []time.Time{}
+	occurrences = append(occurrences, schedule.After(schedule.Start.Add(-time.Second), previewCount(query.Get("count")))...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rule":        schedule.Rule.String(),
+		"timezone":    schedule.Start.Location().String(),
+		"start":       schedule.Start,
+		"occurrences": occurrences,
+	})
+}
+
+// SetTaskRecurrence görevi tekrarlayan yapar veya serisinin kuralını değiştirir. Yeni seride görev
+// ilk tekrarı temsil eder; mevcut seride kural görevin tekrar zamanından itibaren uygulanır ve
+// durdurulmuş seri yeniden başlar.
+func SetTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req RecurrenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	loc, err := rrule.LoadLocation(req.Timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rec, task, err := taskRecurrence(token, req.TaskID)
+	if err != nil && err != errRecurrenceNotFound {
+		writeRecurrenceError(w, "SetTaskRecurrence", err)
+		return
+	}
+
+	start := time.Now()
+	switch {
+	case req.Start != "":
+		if start, err = parseRecurrenceStart(req.Start, loc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case task.OccurrenceAt != nil:
+		if t, err := time.Parse(time.RFC3339, *task.OccurrenceAt); err == nil {
+			start = t
+		}
+	}
+	schedule, err := rrule.New(req.Rule, loc.String(), start)
+	if err != nil {
+		http.Error(w, "Geçersiz tekrar kuralı: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	first, ok := schedule.First()
+	if !ok {
+		http.Error(w, "Kural başlangıçtan sonra hiç tekrar üretmiyor", http.StatusBadRequest)
+		return
+	}
+
+	// Görevin temsil ettiği tekrar: mevcut seride kendi zamanı, yeni seride kuralın ilk tekrarı
+	occurrence := first
+	if rec != nil && task.OccurrenceAt != nil && req.Start == "" {
+		if t, err := time.Parse(time.RFC3339, *task.OccurrenceAt); err == nil {
+			occurrence = t
+		}
+	}
+	var nextAt interface{}
+	if next, ok := schedule.Next(occurrence); ok {
+		nextAt = formatTimestamp(next)
+	}
+
+	fields := map[string]interface{}{
+		"rule":            schedule.Rule.String(),
+		"timezone":        loc.String(),
+		"dtstart":         formatTimestamp(schedule.Start),
+		"next_at":         nextAt,
+		"current_task_id": req.TaskID,
+		"stopped_at":      nil,
+		"updated_at":      formatTimestamp(time.Now()),
+	}
+	status := http.StatusOK
+	var resp []byte
+	if rec == nil {
+		fields["board_id"] = access.BoardID
+		fields["created_by"] = access.UserID
+		resp, err = performSupabaseRequest("POST", "task_recurrences", token, fields)
+		status = http.StatusCreated
+	} else {
+		if rec.CurrentTaskID != nil && *rec.CurrentTaskID != req.TaskID {
+			http.Error(w, "Kural serinin son görevi üzerinden değiştirilebilir", http.StatusConflict)
+			return
+		}
+		resp, err = performSupabaseRequest("PATCH", fmt.Sprintf("task_recurrences?id=eq.%s", rec.ID), token, fields)
+	}
+	if err != nil {
+		fmt.Println("SetTaskRecurrence Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var saved []TaskRecurrence
+	if err := json.Unmarshal(resp, &saved); err != nil || len(saved) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	occurrenceAt := formatTimestamp(occurrence)
+	update := map[string]interface{}{"recurrence_id": saved[0].ID, "occurrence_at": occurrenceAt}
+	if _, err := performSupabaseRequest("PATCH", fmt.Sprintf("tasks?id=eq.%s", req.TaskID), token, update); err != nil {
+		fmt.Println("SetTaskRecurrence Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "recurrence_updated", map[string]interface{}{
+		"task_id":    req.TaskID,
+		"recurrence": saved[0],
+		"updated_by": access.UserID,
+	})
+	writeRecurrence(w, status, &saved[0], defaultPreviewCount, nil)
+}
+
+// SkipTaskRecurrence serinin henüz oluşturulmamış sıradaki count tekrarını atlar.
+// Oluşturulmuş örnekler etkilenmez; onları silmek veya tamamlamak gerekir.
+func SkipTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req RecurrenceActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	if req.Count > maxSkipCount {
+		http.Error(w, fmt.Sprintf("Tek seferde en fazla %d tekrar atlanabilir", maxSkipCount), http.StatusBadRequest)
+		return
+	}
+
+	rec, _, err := taskRecurrence(token, req.TaskID)
+	if err != nil {
+		writeRecurrenceError(w, "SkipTaskRecurrence", err)
+		return
+	}
+	if !rec.active() {
+		http.Error(w, "Serinin atlanacak tekrarı yok", http.StatusConflict)
+		return
+	}
+
+	// Atlananlar next_at dahil sıradaki tekrarlar; yeni next_at onlardan sonraki ilk tekrar
+	upcoming, err := upcomingOccurrences(rec, req.Count+1)
+	if err != nil {
+		writeRecurrenceError(w, "SkipTaskRecurrence", err)
+		return
+	}
+	skipped := upcoming
+	var nextAt interface{}
+	if len(upcoming) > req.Count {
+		skipped = upcoming[:req.Count]
+		nextAt = formatTimestamp(upcoming[req.Count])
+	}
+
+	endpoint := fmt.Sprintf("task_recurrences?id=eq.%s&next_at=eq.%s", rec.ID, url.QueryEscape(*rec.NextAt))
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]interface{}{
+		"next_at":       nextAt,
+		"skipped_count": rec.SkippedCount + len(skipped),
+		"updated_at":    formatTimestamp(time.Now()),
+	})
+	if err != nil {
+		fmt.Println("SkipTaskRecurrence Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var saved []TaskRecurrence
+	if err := json.Unmarshal(resp, &saved); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(saved) == 0 {
+		// Bu arada üretici tekrarı oluşturdu
+		http.Error(w, "Seri bu sırada ilerledi, tekrar deneyin", http.StatusConflict)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "recurrence_skipped", map[string]interface{}{
+		"task_id":       req.TaskID,
+		"recurrence_id": rec.ID,
+		"skipped":       skipped,
+		"next_at":       nextAt,
+		"skipped_by":    access.UserID,
+	})
+	writeRecurrence(w, http.StatusOK, &saved[0], defaultPreviewCount, skipped)
+}
+
+// StopTaskRecurrence seriyi durdurur; yeni tekrar oluşturulmaz, mevcut görevler kalır.
+func StopTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Görevin panosu bulunamadı", http.StatusBadRequest)
+		return
+	}
+
+	var req RecurrenceActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+
+	rec, _, err := taskRecurrence(token, req.TaskID)
+	if err != nil {
+		writeRecurrenceError(w, "StopTaskRecurrence", err)
+		return
+	}
+	if rec.StoppedAt != nil {
+		writeRecurrence(w, http.StatusOK, rec, 0, nil)
+		return
+	}
+
+	resp, err := performSupabaseRequest("PATCH", fmt.Sprintf("task_recurrences?id=eq.%s", rec.ID), token, map[string]interface{}{
+		"stopped_at": formatTimestamp(time.Now()),
+		"next_at":    nil,
+		"updated_at": formatTimestamp(time.Now()),
+	})
+	if err != nil {
+		fmt.Println("StopTaskRecurrence Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var saved []TaskRecurrence
+	if err := json.Unmarshal(resp, &saved); err != nil || len(saved) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	GlobalHub.Publish(access.BoardID, "recurrence_stopped", map[string]interface{}{
+		"task_id":       req.TaskID,
+		"recurrence_id": rec.ID,
+		"stopped_by":    access.UserID,
+	})
+	writeRecurrence(w, http.StatusOK, &saved[0], 0, nil)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetBoardSessions panodaki aktif gerçek zamanlı (WebSocket) oturumları listeler.
+func GetBoardSessions(w http.ResponseWriter, r *http.Request) {
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GlobalHub.BoardSessions(access.BoardID))
+}
+
+// DisconnectBoardSessions panodaki oturumları zorla kapatır.
+// session_id verilirse tek oturum, user_id verilirse kullanıcının tüm oturumları kapanır.
+func DisconnectBoardSessions(w http.ResponseWriter, r *http.Request) {
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	userID := r.URL.Query().Get("user_id")
+	if sessionID == "" && userID == "" {
+		http.Error(w, "session_id veya user_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	// Adminler pano sahibinin bağlantısını kesemez
+	if access.Role != RoleOwner {
+		for _, s := range GlobalHub.sessions.collect(access.BoardID, userID, sessionID) {
+			if s.role() == RoleOwner {
+				http.Error(w, "Pano sahibinin oturumu kapatılamaz", http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	closed := GlobalHub.DisconnectBoard(access.BoardID, userID, sessionID, "disconnected by board admin")
+	if closed == 0 {
+		http.Error(w, "Aktif oturum bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Oturumlar kapatıldı", "closed": closed})
+}
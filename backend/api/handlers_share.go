@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-panel/backend/auth"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShareLink panonun herkese açık, salt okunur paylaşım linki (hash asla dönmez).
+type ShareLink struct {
+	ID         string  `json:"id"`
+	BoardID    string  `json:"board_id"`
+	Name       *string `json:"name,omitempty"`
+	Prefix     string  `json:"prefix"`
+	CreatedBy  string  `json:"created_by"`
+	ExpiresAt  *string `json:"expires_at,omitempty"`
+	RevokedAt  *string `json:"revoked_at,omitempty"`
+	LastUsedAt *string `json:"last_used_at,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// CreateShareLinkRequest paylaşım linki oluşturma isteği
+type CreateShareLinkRequest struct {
+	Name           string `json:"name"`
+	ExpiresInHours int    `json:"expires_in_hours"` // 0: süresiz
+}
+
+const (
+	maxShareLinkLifetime = 365 * 24 * time.Hour
+	shareLinkColumns     = "id,board_id,name,prefix,created_by,expires_at,revoked_at,last_used_at,created_at"
+)
+
+// Herkese açık rotaların limitleri: IP başına istek sayısı ve geçersiz token denemeleri (tahmine karşı)
+var (
+	publicRequests     = newRateLimiter(120, time.Minute)
+	shareTokenFailures = newRateLimiter(20, 15*time.Minute)
+)
+
+// PublicRateLimit kimlik doğrulaması olmayan rotalar için IP başına istek limiti uygular.
+func PublicRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicRequests.Hit("ip:" + clientIP(r)) {
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "Çok fazla istek, lütfen daha sonra tekrar deneyin", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CreateShareLink pano için yeni bir paylaşım linki oluşturur. Düz token sadece bu yanıtta döner.
+func CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresInHours < 0 || time.Duration(req.ExpiresInHours)*time.Hour > maxShareLinkLifetime {
+		http.Error(w, "Paylaşım linki süresi 0 (süresiz) ile 365 gün arasında olmalı", http.StatusBadRequest)
+		return
+	}
+
+	plain, hash, err := auth.GenerateShareToken()
+	if err != nil {
+		http.Error(w, "Paylaşım linki üretilemedi", http.StatusInternalServerError)
+		return
+	}
+
+	link := map[string]interface{}{
+		"board_id":   access.BoardID,
+		"token_hash": hash,
+		"prefix":     plain[:len(auth.SharePrefix)+4],
+		"created_by": access.UserID,
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		link["name"] = name
+	}
+	if req.ExpiresInHours > 0 {
+		link["expires_at"] = time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour).UTC().Format(time.RFC3339)
+	}
+
+	resp, err := performSupabaseRequest("POST", "board_share_links?select="+shareLinkColumns, token, link)
+	if err != nil {
+		fmt.Println("CreateShareLink Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var created []ShareLink
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"link":  created[0],
+		"token": plain,
+		"path":  "/api/public/boards/" + plain,
+	})
+}
+
+// GetShareLinks panonun paylaşım linklerini listeler (iptal edilenler dahil).
+func GetShareLinks(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_share_links?board_id=eq.%s&select=%s&order=created_at.desc", access.BoardID, shareLinkColumns)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetShareLinks Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// RevokeShareLink paylaşım linkini iptal eder (?id=). Link hemen çalışmaz hale gelir.
+func RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Pano ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	linkID := r.URL.Query().Get("id")
+	if linkID == "" {
+		http.Error(w, "ID parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_share_links?id=eq.%s&board_id=eq.%s&revoked_at=is.null&select=%s", linkID, access.BoardID, shareLinkColumns)
+	revokedAt := time.Now().UTC().Format(time.RFC3339)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"revoked_at": revokedAt})
+	if err != nil {
+		fmt.Println("RevokeShareLink Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var revoked []ShareLink
+	if err := json.Unmarshal(resp, &revoked); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(revoked) == 0 {
+		http.Error(w, "Link bulunamadı veya zaten iptal edilmiş", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revoked[0])
+}
+
+// GetSharedBoard paylaşım linkiyle panoyu, görevlerini ve alt görevlerini oturumsuz döndürür.
+// E-postalar, kullanıcı ID'leri ve sohbet dönmez; hepsi shared_board RPC'sinde süzülür.
+func GetSharedBoard(w http.ResponseWriter, r *http.Request) {
+	shareToken := chi.URLParam(r, "token")
+	ipKey := "ip:" + clientIP(r)
+
+	if blocked, retry := shareTokenFailures.Blocked(ipKey); blocked {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(retry.Seconds())+1))
+		http.Error(w, "Çok fazla başarısız deneme, lütfen daha sonra tekrar deneyin", http.StatusTooManyRequests)
+		return
+	}
+	if !strings.HasPrefix(shareToken, auth.SharePrefix) {
+		shareTokenFailures.Hit(ipKey)
+		http.Error(w, "Paylaşım linki geçersiz veya süresi dolmuş", http.StatusNotFound)
+		return
+	}
+
+	body := map[string]string{"p_token_hash": auth.HashPAT(shareToken)}
+	resp, err := performSupabaseRequest("POST", "rpc/shared_board", os.Getenv("SUPABASE_KEY"), body)
+	if err != nil {
+		fmt.Println("GetSharedBoard Hatası:", err)
+		http.Error(w, "Pano yüklenemedi", http.StatusInternalServerError)
+		return
+	}
+	if trimmed := strings.TrimSpace(string(resp)); trimmed == "" || trimmed == "null" {
+		shareTokenFailures.Hit(ipKey)
+		http.Error(w, "Paylaşım linki geçersiz veya süresi dolmuş", http.StatusNotFound)
+		return
+	}
+
+	// Link iptal edilebildiği için ara katmanlarda saklanmamalı
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
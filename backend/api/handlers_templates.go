@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// SaveTemplateRequest mevcut bir panoyu şablon olarak kaydetme isteği
+type SaveTemplateRequest struct {
+	BoardID      string `json:"board_id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	IncludeTasks bool   `json:"include_tasks"`
+}
+
+// customTemplateRow board_templates tablosu satırı
+type customTemplateRow struct {
+	ID          string             `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Definition  TemplateDefinition `json:"definition"`
+}
+
+func (row customTemplateRow) template() BoardTemplate {
+	return BoardTemplate{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: row.Description,
+		Custom:      true,
+		Definition:  row.Definition,
+	}
+}
+
+// loadCustomTemplate kullanıcının kayıtlı şablonunu getirir (RLS: sadece kendi şablonları).
+func loadCustomTemplate(token, templateID string) (*BoardTemplate, error) {
+	endpoint := fmt.Sprintf("board_templates?id=eq.%s&select=id,name,description,definition", templateID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []customTemplateRow
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errBoardNotFound
+	}
+	template := rows[0].template()
+	return &template, nil
+}
+
+// GetBoardTemplates hazır şablonları ve kullanıcının kayıtlı şablonlarını listeler.
+func GetBoardTemplates(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	resp, err := performSupabaseRequest("GET", "board_templates?select=id,name,description,definition&order=created_at.desc", token, nil)
+	if err != nil {
+		fmt.Println("GetBoardTemplates Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var rows []customTemplateRow
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	templates := make([]BoardTemplate, 0, len(builtinTemplates)+len(rows))
+	templates = append(templates, builtinTemplates...)
+	for _, row := range rows {
+		templates = append(templates, row.template())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// SaveBoardAsTemplate panonun sütunlarını, ayarlarını, etiketlerini ve
+// (istenirse) görevlerini kullanıcının özel şablonu olarak kaydeder.
+func SaveBoardAsTemplate(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req SaveTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		http.Error(w, "Şablon adı gerekli", http.StatusBadRequest)
+		return
+	}
+
+	definition, err := boardDefinition(token, access.BoardID, req.IncludeTasks)
+	if err != nil {
+		fmt.Println("SaveBoardAsTemplate Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := definition.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	row := customTemplateRow{Name: req.Name, Description: req.Description, Definition: *definition}
+	resp, err := performSupabaseRequest("POST", "board_templates", token, row)
+	if err != nil {
+		fmt.Println("SaveBoardAsTemplate Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// DeleteBoardTemplate kullanıcının özel şablonunu siler.
+func DeleteBoardTemplate(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	templateID := r.URL.Query().Get("id")
+	if templateID == "" {
+		http.Error(w, "ID parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("board_templates?id=eq.%s", templateID)
+	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("DeleteBoardTemplate Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Şablon silindi", "details": string(resp)})
+}
+
+// boardDefinition panonun mevcut yapısından bir şablon tanımı çıkarır.
+func boardDefinition(token, boardID string, includeTasks bool) (*TemplateDefinition, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("boards?id=eq.%s&select=type,workflow,settings", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var boards []struct {
+		Type     string        `json:"type"`
+		Workflow Workflow      `json:"workflow"`
+		Settings BoardSettings `json:"settings"`
+	}
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		return nil, errBoardNotFound
+	}
+
+	definition := &TemplateDefinition{
+		Workflow: effectiveWorkflow(boards[0].Type, boards[0].Workflow),
+		Settings: boards[0].Settings,
+	}
+	// Varsayılan atanan kişi panoya özgüdür, şablona taşınmaz
+	definition.Settings.DefaultAssignee = ""
+
+	resp, err = performSupabaseRequest("GET", fmt.Sprintf("board_labels?board_id=eq.%s&select=name,color&order=name.asc", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(resp, &definition.Labels); err != nil {
+		return nil, err
+	}
+
+	if !includeTasks {
+		return definition, nil
+	}
+
+	resp, err = performSupabaseRequest("GET", fmt.Sprintf("tasks?board_id=eq.%s&select=title,description,status,priority,position,subtasks(title,position)&order=position.asc", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		return nil, err
+	}
+
+	for _, t := range tasks {
+		tt := TemplateTask{Title: t.Title, Status: t.Status, Priority: t.Priority}
+		if t.Description != nil {
+			tt.Description = *t.Description
+		}
+		sort.SliceStable(t.Subtasks, func(i, j int) bool { return t.Subtasks[i].Position < t.Subtasks[j].Position })
+		for _, st := range t.Subtasks {
+			tt.Subtasks = append(tt.Subtasks, st.Title)
+		}
+		definition.Tasks = append(definition.Tasks, tt)
+	}
+	return definition, nil
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-panel/backend/auth"
+	"net/http"
+	"time"
+)
+
+// PersonalAccessToken listelemede dönen token bilgisi (hash asla dönmez).
+type PersonalAccessToken struct {
+	ID         string   `json:"id,omitempty"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at,omitempty"`
+	LastUsedAt *string  `json:"last_used_at,omitempty"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
+
+// CreateTokenRequest yeni PAT isteği
+type CreateTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0: süresiz
+}
+
+// maxTokenLifetimeDays PAT için izin verilen en uzun süre
+const maxTokenLifetimeDays = 365
+
+func validScope(scope string) bool {
+	for _, s := range ValidScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePersonalAccessToken yeni bir kişisel erişim token'ı üretir.
+// Düz token sadece bu yanıtta döner; veritabanında SHA-256 hash'i saklanır.
+func CreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	var req CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		http.Error(w, "Token adı gerekli", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		http.Error(w, "En az bir kapsam (scope) gerekli", http.StatusBadRequest)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !validScope(scope) {
+			http.Error(w, fmt.Sprintf("Geçersiz kapsam: %s", scope), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxTokenLifetimeDays {
+		http.Error(w, fmt.Sprintf("expires_in_days 0 ile %d arasında olmalı", maxTokenLifetimeDays), http.StatusBadRequest)
+		return
+	}
+
+	plain, hash, err := auth.GeneratePAT()
+	if err != nil {
+		fmt.Println("CreatePersonalAccessToken Hatası:", err)
+		http.Error(w, "Token üretilemedi", http.StatusInternalServerError)
+		return
+	}
+
+	row := map[string]interface{}{
+		"name":       req.Name,
+		"token_hash": hash,
+		"prefix":     plain[:len(auth.PATPrefix)+4],
+		"scopes":     req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		row["expires_at"] = time.Now().AddDate(0, 0, req.ExpiresInDays).UTC().Format(time.RFC3339)
+	}
+
+	resp, err := performSupabaseRequest("POST", "personal_access_tokens?select=id,name,prefix,scopes,expires_at,created_at", token, row)
+	if err != nil {
+		fmt.Println("CreatePersonalAccessToken Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var created []PersonalAccessToken
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":   plain,
+		"details": created[0],
+	})
+}
+
+// ListPersonalAccessTokens kullanıcının token'larını listeler.
+func ListPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	// RLS: kullanıcı sadece kendi token'larını görür
+	endpoint := "personal_access_tokens?select=id,name,prefix,scopes,expires_at,last_used_at,revoked_at,created_at&order=created_at.desc"
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("ListPersonalAccessTokens Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// RevokePersonalAccessToken token'ı iptal eder (kayıt denetim için saklanır).
+func RevokePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	tokenID := r.URL.Query().Get("id")
+	if tokenID == "" {
+		http.Error(w, "ID parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("personal_access_tokens?id=eq.%s&revoked_at=is.null&select=id", tokenID)
+	body := map[string]string{"revoked_at": time.Now().UTC().Format(time.RFC3339)}
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, body)
+	if err != nil {
+		fmt.Println("RevokePersonalAccessToken Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var revoked []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp, &revoked); err != nil || len(revoked) == 0 {
+		http.Error(w, "Token bulunamadı veya zaten iptal edilmiş", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Token iptal edildi", "details": string(resp)})
+}
@@ -0,0 +1,722 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Trello etiketlerinin nasıl aktarılacağı
+const (
+	TrelloLabelsAsPriority = "priority" // etiket adı/rengi önceliğe çevrilir (varsayılan)
+	TrelloLabelsAsLabels   = "labels"   // panoya etiket olarak eklenir ve kartın görevine bağlanır
+)
+
+// trelloPos Trello "pos" alanı; dışa aktarımlarda sayı, API'de bazen "top"/"bottom" olabilir.
+type trelloPos float64
+
+func (p *trelloPos) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err == nil {
+		*p = trelloPos(f)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "top":
+		*p = 0
+	case "bottom":
+		*p = 1 << 30
+	default:
+		f, _ := strconv.ParseFloat(s, 64)
+		*p = trelloPos(f)
+	}
+	return nil
+}
+
+// TrelloBoard Trello'nun "Export as JSON" çıktısının kullanılan kısmı
+type TrelloBoard struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Lists      []trelloList      `json:"lists"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+	Labels     []trelloLabel     `json:"labels"`
+	Members    []trelloMember    `json:"members"`
+}
+
+type trelloList struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Closed bool      `json:"closed"`
+	Pos    trelloPos `json:"pos"`
+}
+
+type trelloCard struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Desc      string    `json:"desc"`
+	IDList    string    `json:"idList"`
+	Closed    bool      `json:"closed"`
+	Due       *string   `json:"due"`
+	Pos       trelloPos `json:"pos"`
+	IDLabels  []string  `json:"idLabels"`
+	IDMembers []string  `json:"idMembers"`
+}
+
+type trelloChecklist struct {
+	ID         string            `json:"id"`
+	IDCard     string            `json:"idCard"`
+	Name       string            `json:"name"`
+	Pos        trelloPos         `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+type trelloCheckItem struct {
+	ID    string    `json:"id"`
+	Name  string    `json:"name"`
+	State string    `json:"state"` // complete, incomplete
+	Pos   trelloPos `json:"pos"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type trelloMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+	Email    string `json:"email,omitempty"` // Trello genelde vermez; member_emails ile tamamlanır
+}
+
+// TrelloImportRequest Trello içe aktarma isteği.
+// BoardID verilmezse aynı Trello panosundan daha önce oluşturulmuş pano aranır, yoksa yeni pano açılır.
+type TrelloImportRequest struct {
+	Trello          TrelloBoard       `json:"trello"`
+	BoardID         string            `json:"board_id,omitempty"`
+	Title           string            `json:"title,omitempty"`
+	LabelMode       string            `json:"label_mode,omitempty"`
+	MemberEmails    map[string]string `json:"member_emails,omitempty"` // Trello kullanıcı adı veya ID -> e-posta
+	IncludeArchived bool              `json:"include_archived"`
+	Preview         bool              `json:"preview"`
+}
+
+// TrelloListMapping bir Trello listesinin eşlendiği sütun
+type TrelloListMapping struct {
+	List     string `json:"list"`
+	Status   string `json:"status"`
+	Category string `json:"category"`
+	New      bool   `json:"new"` // panoya yeni sütun olarak eklenecek
+}
+
+// TrelloImportReport önizleme ve içe aktarma sonucu
+type TrelloImportReport struct {
+	Preview          bool                `json:"preview"`
+	BoardID          string              `json:"board_id,omitempty"`
+	BoardTitle       string              `json:"board_title"`
+	NewBoard         bool                `json:"new_board"`
+	Lists            []TrelloListMapping `json:"lists"`
+	Labels           map[string]string   `json:"labels"` // Trello etiketi -> öncelik veya pano etiketi
+	CardsToCreate    int                 `json:"cards_to_create"`
+	CardsToUpdate    int                 `json:"cards_to_update"`
+	CardsSkipped     int                 `json:"cards_skipped"`
+	Subtasks         int                 `json:"subtasks"`
+	MembersToInvite  []string            `json:"members_to_invite"` // kayıtlı ama panoda olmayanlar; eklenmez, davet edilebilir
+	UnresolvedMember []string            `json:"unresolved_members"`
+	Warnings         []string            `json:"warnings"`
+	Result           *ExternalUpsert     `json:"result,omitempty"`
+}
+
+// ExternalUpsert upsert_external_tasks RPC sonucu
+type ExternalUpsert struct {
+	Created         int `json:"created"`
+	Updated         int `json:"updated"`
+	SubtasksCreated int `json:"subtasks_created"`
+	SubtasksUpdated int `json:"subtasks_updated"`
+}
+
+// externalTask upsert_external_tasks RPC'sine giden görev
+type externalTask struct {
+	ExternalRef string            `json:"external_ref"`
+	Title       string            `json:"title"`
+	Description *string           `json:"description"`
+	Status      string            `json:"status"`
+	Priority    string            `json:"priority,omitempty"`
+	DueDate     *string           `json:"due_date"`
+	Position    int               `json:"position"`
+	AssignedTo  *string           `json:"assigned_to"`
+	Labels      []string          `json:"labels,omitempty"` // pano etiketi isimleri
+	Subtasks    []externalSubtask `json:"subtasks"`
+}
+
+type externalSubtask struct {
+	ExternalRef string `json:"external_ref"`
+	Title       string `json:"title"`
+	IsCompleted bool   `json:"is_completed"`
+	Position    int    `json:"position"`
+}
+
+// trelloLabelColors Trello renk adlarının pano etiketi karşılıkları
+var trelloLabelColors = map[string]string{
+	"green":  "#22c55e",
+	"yellow": "#eab308",
+	"orange": "#f97316",
+	"red":    "#ef4444",
+	"purple": "#a855f7",
+	"blue":   "#3b82f6",
+	"sky":    "#0ea5e9",
+	"lime":   "#84cc16",
+	"pink":   "#ec4899",
+	"black":  "#374151",
+}
+
+func trelloRef(id string) string { return "trello:" + id }
+
+// trelloListCategory liste adından sütun kategorisini tahmin eder.
+func trelloListCategory(name string, index int) string {
+	n := strings.ToLower(name)
+	for _, w := range []string{"done", "tamam", "bitti", "complete", "closed", "kapandı"} {
+		if strings.Contains(n, w) {
+			return CategoryDone
+		}
+	}
+	for _, w := range []string{"doing", "progress", "yapılıyor", "review", "kontrol", "test"} {
+		if strings.Contains(n, w) {
+			return CategoryInProgress
+		}
+	}
+	if index == 0 {
+		return CategoryTodo
+	}
+	for _, w := range []string{"todo", "to do", "backlog", "yapılacak", "fikir", "idea"} {
+		if strings.Contains(n, w) {
+			return CategoryTodo
+		}
+	}
+	return CategoryInProgress
+}
+
+// trelloLabelPriority etiketi önceliğe çevirir: önce adına (High, Yüksek ...), sonra rengine bakar.
+func trelloLabelPriority(l trelloLabel) string {
+	if p, ok := normalizePriority(l.Name); ok && p != "" {
+		return p
+	}
+	switch l.Color {
+	case "red":
+		return PriorityHigh
+	case "orange", "yellow":
+		return PriorityMedium
+	case "green", "blue", "sky", "lime":
+		return PriorityLow
+	}
+	return ""
+}
+
+// ImportTrelloBoard Trello JSON dışa aktarımını panoya aktarır: listeler sütunlara, kartlar görevlere,
+// checklist maddeleri alt görevlere dönüşür. Kart ve maddeler Trello ID'leriyle işaretlendiği için
+// tekrar çalıştırmak kopya oluşturmaz, mevcut kayıtları günceller. preview=true hiçbir şey yazmaz.
+func ImportTrelloBoard(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+
+	var req TrelloImportRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxArchiveSize)).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz Trello JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Trello.ID == "" || len(req.Trello.Lists) == 0 {
+		http.Error(w, "Trello panosu boş veya geçersiz (id ve lists gerekli)", http.StatusBadRequest)
+		return
+	}
+	switch req.LabelMode {
+	case "":
+		req.LabelMode = TrelloLabelsAsPriority
+	case TrelloLabelsAsPriority, TrelloLabelsAsLabels:
+	default:
+		http.Error(w, "Geçersiz label_mode (priority, labels)", http.StatusBadRequest)
+		return
+	}
+
+	report := &TrelloImportReport{
+		Preview:          req.Preview,
+		Labels:           map[string]string{},
+		MembersToInvite:  []string{},
+		UnresolvedMember: []string{},
+		Warnings:         []string{},
+	}
+
+	// Hedef pano: verilen, daha önce içe aktarılmış veya yeni
+	target, role, err := trelloTargetBoard(token, principal.UserID, &req)
+	if err != nil {
+		if _, ok := err.(requestError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Println("ImportTrelloBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if target != nil && !RoleAllows(role, PermEditTasks) {
+		http.Error(w, "Bu panoya görev ekleme yetkiniz yok", http.StatusForbidden)
+		return
+	}
+	if target != nil && target.ArchivedAt != nil {
+		http.Error(w, "Pano arşivlenmiş, salt okunur", http.StatusConflict)
+		return
+	}
+
+	workflow := Workflow{}
+	existingRefs := map[string]string{} // external_ref -> mevcut sütun
+	existingLabels := map[string]bool{}
+	var members map[string]string
+	if target != nil {
+		report.BoardID = target.ID
+		report.BoardTitle = target.Title
+		if target.Workflow != nil {
+			workflow = *target.Workflow
+		}
+		workflow = effectiveWorkflow(target.Type, workflow)
+		if existingRefs, err = boardExternalRefs(token, target.ID); err == nil {
+			existingLabels, err = boardLabelNames(token, target.ID)
+		}
+		if err == nil {
+			members, err = boardUsersByEmail(token, target.ID)
+		}
+		if err != nil {
+			fmt.Println("ImportTrelloBoard Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		report.NewBoard = true
+		report.BoardTitle = req.Title
+		if report.BoardTitle == "" {
+			report.BoardTitle = req.Trello.Name
+		}
+		members = map[string]string{strings.ToLower(principal.Email): principal.UserID}
+	}
+
+	// Listeler -> sütunlar
+	lists := make([]trelloList, 0, len(req.Trello.Lists))
+	for _, l := range req.Trello.Lists {
+		if !l.Closed || req.IncludeArchived {
+			lists = append(lists, l)
+		}
+	}
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+
+	listStatus := make(map[string]string, len(lists))
+	for i, l := range lists {
+		name := strings.TrimSpace(l.Name)
+		if name == "" {
+			name = "Liste " + strconv.Itoa(i+1)
+		}
+		if key, ok := workflowStatusKey(workflow, name); ok {
+			listStatus[l.ID] = key
+			report.Lists = append(report.Lists, TrelloListMapping{List: l.Name, Status: key, Category: workflow.Category(key)})
+			continue
+		}
+		key := name
+		for n := 2; workflow.Has(key); n++ {
+			key = fmt.Sprintf("%s (%d)", name, n)
+		}
+		status := BoardStatus{Key: key, Title: name, Order: len(workflow.Statuses), Category: trelloListCategory(name, i)}
+		workflow.Statuses = append(workflow.Statuses, status)
+		listStatus[l.ID] = key
+		report.Lists = append(report.Lists, TrelloListMapping{List: l.Name, Status: key, Category: status.Category, New: true})
+	}
+
+	// Etiketler
+	labelPriority := make(map[string]string)
+	labelNames := make(map[string]string) // Trello etiket ID -> pano etiketi
+	var newLabels []map[string]string
+	for _, l := range req.Trello.Labels {
+		display := l.Name
+		if display == "" {
+			display = l.Color
+		}
+		if req.LabelMode == TrelloLabelsAsPriority {
+			if p := trelloLabelPriority(l); p != "" {
+				labelPriority[l.ID] = p
+				report.Labels[display] = p
+			}
+			continue
+		}
+		if display == "" {
+			continue
+		}
+		labelNames[l.ID] = display
+		if existingLabels[display] {
+			report.Labels[display] = display
+			continue
+		}
+		color, ok := trelloLabelColors[l.Color]
+		if !ok {
+			color = "#6b7280"
+		}
+		existingLabels[display] = true
+		newLabels = append(newLabels, map[string]string{"name": display, "color": color})
+		report.Labels[display] = display
+	}
+
+	// Üyeler e-posta ile pano üyelerine eşlenir; panoda olmayanlar onaysız eklenmez
+	trelloUsers, err := resolveTrelloMembers(token, req, members, report)
+	if err != nil {
+		fmt.Println("ImportTrelloBoard Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(report.MembersToInvite) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d kişi panonun üyesi değil; eklenmeyecek, atamaları boş kalacak. Davet linkiyle (/api/boards/invites) katılabilirler", len(report.MembersToInvite)))
+	}
+
+	tasks := buildTrelloTasks(req, listStatus, labelPriority, labelNames, trelloUsers, report)
+	for _, t := range tasks {
+		if _, ok := existingRefs[t.ExternalRef]; ok {
+			report.CardsToUpdate++
+		} else {
+			report.CardsToCreate++
+		}
+		report.Subtasks += len(t.Subtasks)
+	}
+
+	if req.Preview {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	// Mevcut panoda sütununa yeni giren (eklenen veya taşınan) kartlar WIP limitlerine uymalı
+	var access *BoardAccess
+	var wipOverrides []*wipViolation
+	if target != nil {
+		access = &BoardAccess{BoardID: target.ID, UserID: principal.UserID, Role: role}
+		var entering []Task
+		for _, t := range tasks {
+			if status, ok := existingRefs[t.ExternalRef]; !ok || status != t.Status {
+				entering = append(entering, Task{Status: t.Status, AssignedTo: t.AssignedTo})
+			}
+		}
+		if wipOverrides, ok = enforceWIPByStatus(w, token, access, workflow, entering, wipOverrideRequested(r)); !ok {
+			return
+		}
+	}
+
+	// Yazma: pano, sütunlar, etiketler, görevler
+	boardID := ""
+	if target == nil {
+		created, err := createTrelloBoard(token, report.BoardTitle, req.Trello.ID, workflow)
+		if err != nil {
+			fmt.Println("ImportTrelloBoard Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		boardID = created.ID
+		report.BoardID = boardID
+	} else {
+		boardID = target.ID
+		if hasNewStatus(report.Lists) {
+			if _, err := performSupabaseRequest("PATCH", fmt.Sprintf("boards?id=eq.%s", boardID), token, map[string]interface{}{"workflow": workflow}); err != nil {
+				writeTrelloError(w, err)
+				return
+			}
+		}
+	}
+
+	if err := writeTrelloContent(token, boardID, newLabels, tasks, report); err != nil {
+		if report.NewBoard {
+			performSupabaseRequest("DELETE", fmt.Sprintf("boards?id=eq.%s", boardID), token, nil)
+		}
+		writeTrelloError(w, err)
+		return
+	}
+
+	GlobalHub.Publish(boardID, "tasks_imported", map[string]interface{}{
+		"source":      "trello",
+		"created":     report.Result.Created,
+		"updated":     report.Result.Updated,
+		"imported_by": principal.UserID,
+	})
+	for _, v := range wipOverrides {
+		recordWIPOverride(token, access, v, nil)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.NewBoard {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+func writeTrelloError(w http.ResponseWriter, err error) {
+	fmt.Println("ImportTrelloBoard Hatası:", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func hasNewStatus(lists []TrelloListMapping) bool {
+	for _, l := range lists {
+		if l.New {
+			return true
+		}
+	}
+	return false
+}
+
+// trelloTargetBoard hedef panoyu ve çağıranın rolünü bulur; yeni pano açılacaksa nil döner.
+func trelloTargetBoard(token, userID string, req *TrelloImportRequest) (*Board, string, error) {
+	endpoint := ""
+	if req.BoardID != "" {
+		endpoint = fmt.Sprintf("boards?id=eq.%s&select=*", req.BoardID)
+	} else {
+		endpoint = fmt.Sprintf("boards?external_ref=eq.%s&select=*&order=created_at.asc", url.QueryEscape(trelloRef(req.Trello.ID)))
+	}
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	var boards []Board
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		return nil, "", err
+	}
+	if len(boards) == 0 {
+		if req.BoardID != "" {
+			return nil, "", requestError("Pano bulunamadı")
+		}
+		return nil, "", nil
+	}
+
+	role, err := boardRole(token, boards[0].ID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return &boards[0], role, nil
+}
+
+// boardExternalRefs panoda daha önce içe aktarılmış görevlerin referansları ve sütunları
+func boardExternalRefs(token, boardID string) (map[string]string, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("tasks?board_id=eq.%s&external_ref=not.is.null&select=external_ref,status", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ExternalRef string `json:"external_ref"`
+		Status      string `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	refs := make(map[string]string, len(rows))
+	for _, r := range rows {
+		refs[r.ExternalRef] = r.Status
+	}
+	return refs, nil
+}
+
+// boardLabelNames panodaki etiket isimleri
+func boardLabelNames(token, boardID string) (map[string]bool, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("board_labels?board_id=eq.%s&select=name", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var labels []TemplateLabel
+	if err := json.Unmarshal(resp, &labels); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		names[l.Name] = true
+	}
+	return names, nil
+}
+
+// resolveTrelloMembers Trello üyelerini e-posta ile pano üyelerine eşler (Trello üye ID -> kullanıcı ID).
+// Kayıtlı ama panoda olmayan kullanıcılar eşlenmez, report.MembersToInvite'ta listelenir.
+func resolveTrelloMembers(token string, req TrelloImportRequest, boardUsers map[string]string, report *TrelloImportReport) (map[string]string, error) {
+	emails := make(map[string]string) // Trello üye ID -> e-posta
+	var lookup []string
+	for _, m := range req.Trello.Members {
+		email := m.Email
+		if e, ok := req.MemberEmails[m.Username]; ok {
+			email = e
+		} else if e, ok := req.MemberEmails[m.ID]; ok {
+			email = e
+		}
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			report.UnresolvedMember = append(report.UnresolvedMember, m.Username)
+			continue
+		}
+		emails[m.ID] = email
+		if _, ok := boardUsers[email]; !ok {
+			lookup = append(lookup, email)
+		}
+	}
+
+	found, err := lookupUsersByEmail(token, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]string)
+	invited := make(map[string]bool)
+	for trelloID, email := range emails {
+		if id, ok := boardUsers[email]; ok {
+			users[trelloID] = id
+			continue
+		}
+		if _, ok := found[email]; !ok {
+			report.UnresolvedMember = append(report.UnresolvedMember, email)
+			continue
+		}
+		if !invited[email] {
+			invited[email] = true
+			report.MembersToInvite = append(report.MembersToInvite, email)
+		}
+	}
+	sort.Strings(report.MembersToInvite)
+	sort.Strings(report.UnresolvedMember)
+	return users, nil
+}
+
+// buildTrelloTasks kartları (liste içi sıralarıyla) görevlere, checklist maddelerini alt görevlere çevirir.
+// Kart etiketleri label_mode'a göre önceliğe (labelPriority) veya pano etiketlerine (labelNames) dönüşür.
+func buildTrelloTasks(req TrelloImportRequest, listStatus, labelPriority, labelNames, users map[string]string, report *TrelloImportReport) []externalTask {
+	checklists := make(map[string][]trelloChecklist)
+	for _, c := range req.Trello.Checklists {
+		checklists[c.IDCard] = append(checklists[c.IDCard], c)
+	}
+
+	cards := make([]trelloCard, 0, len(req.Trello.Cards))
+	for _, c := range req.Trello.Cards {
+		if _, ok := listStatus[c.IDList]; !ok || (c.Closed && !req.IncludeArchived) {
+			report.CardsSkipped++
+			continue
+		}
+		cards = append(cards, c)
+	}
+	sort.SliceStable(cards, func(i, j int) bool { return cards[i].Pos < cards[j].Pos })
+
+	positions := make(map[string]int)
+	tasks := make([]externalTask, 0, len(cards))
+	for _, c := range cards {
+		task := externalTask{
+			ExternalRef: trelloRef(c.ID),
+			Title:       c.Name,
+			Status:      listStatus[c.IDList],
+			Position:    positions[c.IDList],
+			Subtasks:    []externalSubtask{},
+		}
+		positions[c.IDList]++
+		if c.Desc != "" {
+			desc := c.Desc
+			task.Description = &desc
+		}
+		if c.Due != nil && *c.Due != "" {
+			if due, ok := normalizeDate(*c.Due); ok {
+				task.DueDate = &due
+			}
+		}
+		for _, id := range c.IDLabels {
+			if p := labelPriority[id]; priorityWeight(p) > priorityWeight(task.Priority) {
+				task.Priority = p
+			}
+			if name, ok := labelNames[id]; ok && !containsString(task.Labels, name) {
+				task.Labels = append(task.Labels, name)
+			}
+		}
+		for _, id := range c.IDMembers {
+			if uid, ok := users[id]; ok {
+				task.AssignedTo = &uid
+				break
+			}
+		}
+
+		lists := checklists[c.ID]
+		sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+		for _, cl := range lists {
+			items := cl.CheckItems
+			sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+			for _, item := range items {
+				title := item.Name
+				if len(lists) > 1 {
+					title = cl.Name + ": " + title
+				}
+				task.Subtasks = append(task.Subtasks, externalSubtask{
+					ExternalRef: trelloRef(item.ID),
+					Title:       title,
+					IsCompleted: item.State == "complete",
+					Position:    len(task.Subtasks),
+				})
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// createTrelloBoard Trello panosu için yeni pano oluşturur (Trello ID'si external_ref olarak saklanır).
+func createTrelloBoard(token, title, trelloID string, workflow Workflow) (*Board, error) {
+	settings := BoardSettings{DefaultView: "board", Priorities: defaultPriorities}
+	body := map[string]interface{}{
+		"title":        title,
+		"type":         "custom",
+		"workflow":     workflow,
+		"settings":     settings,
+		"external_ref": trelloRef(trelloID),
+	}
+	resp, err := performSupabaseRequest("POST", "boards", token, body)
+	if err != nil {
+		return nil, err
+	}
+	var created []Board
+	if err := json.Unmarshal(resp, &created); err != nil || len(created) == 0 {
+		return nil, fmt.Errorf("yeni pano oluşturulamadı")
+	}
+	return &created[0], nil
+}
+
+// writeTrelloContent etiketleri ve görevleri (tek transaction'lık RPC ile) yazar.
+func writeTrelloContent(token, boardID string, labels []map[string]string, tasks []externalTask, report *TrelloImportReport) error {
+	if len(labels) > 0 {
+		for _, l := range labels {
+			l["board_id"] = boardID
+		}
+		if _, err := performSupabaseRequest("POST", "board_labels", token, labels); err != nil {
+			return err
+		}
+	}
+
+	resp, err := performSupabaseRequest("POST", "rpc/upsert_external_tasks", token, map[string]interface{}{
+		"p_board": boardID,
+		"p_tasks": tasks,
+	})
+	if err != nil {
+		return err
+	}
+	var result ExternalUpsert
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return err
+	}
+	report.Result = &result
+	return nil
+}
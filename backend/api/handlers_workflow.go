@@ -0,0 +1,257 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// statusMoveError workflow'un izin vermediği sütun geçişi
+type statusMoveError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *statusMoveError) Error() string {
+	allowed := "yok"
+	if len(e.Allowed) > 0 {
+		allowed = strings.Join(e.Allowed, ", ")
+	}
+	return fmt.Sprintf("%q sütunundan %q sütununa geçişe izin verilmiyor (izin verilenler: %s)", e.From, e.To, allowed)
+}
+
+// validateStatusMove hedef sütunu (anahtar veya başlık) çözer ve from -> to geçişini kontrol eder.
+// from boşsa (yeni görev) sadece sütunun varlığına bakılır.
+func validateStatusMove(wf Workflow, from, to string) (string, error) {
+	key, ok := workflowStatusKey(wf, to)
+	if !ok {
+		keys := make([]string, 0, len(wf.Statuses))
+		for _, s := range wf.Statuses {
+			keys = append(keys, s.Key)
+		}
+		return "", requestError(fmt.Sprintf("Geçersiz durum %q; bu panonun sütunları: %s", to, strings.Join(keys, ", ")))
+	}
+	if !wf.Allows(from, key) {
+		return "", &statusMoveError{From: from, To: key, Allowed: wf.Transitions[from]}
+	}
+	return key, nil
+}
+
+// writeStatusError durum doğrulama hatalarını HTTP yanıtına çevirir.
+func writeStatusError(w http.ResponseWriter, name string, err error) {
+	switch err.(type) {
+	case requestError:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case *statusMoveError:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		fmt.Printf("%s Hatası: %v\n", name, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// UpdateWorkflowRequest pano sütunlarını değiştirme isteği.
+// Remap, kaldırılan sütunlardaki görevlerin taşınacağı sütunları verir (eski -> yeni).
+type UpdateWorkflowRequest struct {
+	Workflow Workflow          `json:"workflow"`
+	Remap    map[string]string `json:"remap,omitempty"`
+}
+
+// WorkflowResponse panonun sütunları ve sütun başına görev sayısı
+type WorkflowResponse struct {
+	Workflow Workflow       `json:"workflow"`
+	Counts   map[string]int `json:"counts"`
+}
+
+// statusCounts panodaki görevlerin sütun başına sayısı
+func statusCounts(token, boardID string) (map[string]int, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("tasks?board_id=eq.%s&select=status", boardID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, r := range rows {
+		counts[r.Status]++
+	}
+	return counts, nil
+}
+
+// GetBoardWorkflow panonun sütunlarını, geçiş kurallarını ve sütun başına görev sayısını döndürür.
+func GetBoardWorkflow(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := boardWorkflow(token, access.BoardID)
+	if err != nil {
+		fmt.Println("GetBoardWorkflow Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	counts, err := statusCounts(token, access.BoardID)
+	if err != nil {
+		fmt.Println("GetBoardWorkflow Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WorkflowResponse{Workflow: workflow, Counts: counts})
+}
+
+// UpdateBoardWorkflow panonun sütunlarını (sıra, kategori, renk) ve geçiş kurallarını değiştirir.
+// Kaldırılan bir sütunda görev varsa remap ile taşınacağı sütun verilmelidir, aksi halde 409 döner.
+// Taşınan görevler hedef sütunun WIP limitini aşarsa da 409 döner (sahip ?override_wip=true ile aşabilir).
+func UpdateBoardWorkflow(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Board ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateWorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if err := req.Workflow.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Sıra, dizideki Order değerlerine göre 0'dan yeniden numaralanır
+	statuses := req.Workflow.Statuses
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Order < statuses[j].Order })
+	for i := range statuses {
+		statuses[i].Order = i
+	}
+
+	counts, err := statusCounts(token, access.BoardID)
+	if err != nil {
+		fmt.Println("UpdateBoardWorkflow Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	orphaned := make(map[string]int)
+	for status, n := range counts {
+		if req.Workflow.Has(status) {
+			continue
+		}
+		target, ok := req.Remap[status]
+		if !ok {
+			orphaned[status] = n
+			continue
+		}
+		if !req.Workflow.Has(target) {
+			http.Error(w, fmt.Sprintf("remap hedefi tanımsız sütun: %s", target), http.StatusBadRequest)
+			return
+		}
+	}
+	if len(orphaned) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    "Kaldırılan sütunlarda görev var; remap ile taşınacakları sütunu belirtin",
+			"orphaned": orphaned,
+		})
+		return
+	}
+
+	// Taşınan görevler hedef sütunların (yeni) WIP limitlerine uymalı (sahip ?override_wip=true ile aşabilir)
+	var remapped []Task
+	for from, to := range req.Remap {
+		if counts[from] == 0 || req.Workflow.Has(from) {
+			continue
+		}
+		endpoint := fmt.Sprintf("tasks?board_id=eq.%s&status=eq.%s&select=id,assigned_to", access.BoardID, url.QueryEscape(from))
+		resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+		if err != nil {
+			fmt.Println("UpdateBoardWorkflow Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var tasks []Task
+		if err := json.Unmarshal(resp, &tasks); err != nil {
+			http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+			return
+		}
+		for _, t := range tasks {
+			t.Status = to
+			remapped = append(remapped, t)
+		}
+	}
+	wipOverrides, ok := enforceWIPByStatus(w, token, access, req.Workflow, remapped, wipOverrideRequested(r))
+	if !ok {
+		return
+	}
+
+	// Önce sütunlar yazılır ki taşınan görevler yeni sütunlara geçebilsin
+	endpoint := fmt.Sprintf("boards?id=eq.%s", access.BoardID)
+	if _, err := performSupabaseRequest("PATCH", endpoint, token, map[string]interface{}{"workflow": req.Workflow}); err != nil {
+		fmt.Println("UpdateBoardWorkflow Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	moved := make(map[string]int)
+	for from, to := range req.Remap {
+		if counts[from] == 0 || req.Workflow.Has(from) {
+			continue
+		}
+		endpoint := fmt.Sprintf("tasks?board_id=eq.%s&status=eq.%s", access.BoardID, url.QueryEscape(from))
+		if _, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"status": to}); err != nil {
+			fmt.Println("UpdateBoardWorkflow Hatası:", err)
+			http.Error(w, fmt.Sprintf("Sütunlar kaydedildi ancak %q görevleri taşınamadı: %v", from, err), http.StatusInternalServerError)
+			return
+		}
+		moved[from] = counts[from]
+	}
+	for _, v := range wipOverrides {
+		var ids []string
+		for _, t := range remapped {
+			if t.Status == v.Status {
+				ids = append(ids, t.ID)
+			}
+		}
+		recordWIPOverride(token, access, v, ids)
+	}
+
+	GlobalHub.Publish(access.BoardID, "workflow_updated", map[string]interface{}{
+		"workflow":   req.Workflow,
+		"moved":      moved,
+		"updated_by": access.UserID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workflow": req.Workflow,
+		"moved":    moved,
+	})
+}
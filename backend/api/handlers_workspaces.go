@@ -0,0 +1,614 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// roleRank rollerin gücü (pano ve çalışma alanı rolleri aynı adları kullanır)
+var roleRank = map[string]int{
+	RoleOwner:  4,
+	RoleAdmin:  3,
+	RoleMember: 2,
+	RoleViewer: 1,
+}
+
+// Workspace panoların üstündeki çalışma alanı (organizasyon)
+type Workspace struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	OwnerID   string `json:"owner_id,omitempty"`
+	Personal  bool   `json:"personal"`
+	CreatedAt string `json:"created_at,omitempty"`
+	Role      string `json:"role,omitempty"` // çağıranın rolü (sadece listede)
+}
+
+// WorkspaceMember çalışma alanı üyeliği
+type WorkspaceMember struct {
+	UserID  string   `json:"user_id"`
+	Role    string   `json:"role"`
+	Profile *Profile `json:"profiles,omitempty"`
+}
+
+// WorkspaceRequest çalışma alanı oluşturma/yeniden adlandırma isteği
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// WorkspaceMemberRequest üye ekleme (email) veya rol değiştirme (user_id) isteği
+type WorkspaceMemberRequest struct {
+	Email  string `json:"email,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	Role   string `json:"role"`
+}
+
+// WorkspaceBoards GetBoards?group_by=workspace yanıtındaki bir grup
+type WorkspaceBoards struct {
+	Workspace *Workspace        `json:"workspace"` // erişilemeyen çalışma alanındaki panolar (doğrudan üyelik) için nil
+	Boards    []json.RawMessage `json:"boards"`
+}
+
+// validWorkspaceRole çalışma alanı üyeliğine yazılabilecek roller
+func validWorkspaceRole(role string) bool {
+	return role == RoleOwner || role == RoleAdmin || role == RoleMember || role == RoleViewer
+}
+
+// workspaceBoardRole çalışma alanı rolünün o alandaki panolarda karşılığı.
+// Çalışma alanı sahipleri panolarda admin olur; pano sahipliği ayrıdır.
+func workspaceBoardRole(role string) string {
+	if role == RoleOwner {
+		return RoleAdmin
+	}
+	return role
+}
+
+// workspaceRole kullanıcının çalışma alanındaki rolünü döndürür (üye değilse boş).
+func workspaceRole(token, workspaceID, userID string) (string, error) {
+	endpoint := fmt.Sprintf("workspace_members?workspace_id=eq.%s&user_id=eq.%s&select=role", workspaceID, userID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return "", err
+	}
+	var members []struct {
+		Role string `json:"role"`
+	}
+	if err := json.Unmarshal(resp, &members); err != nil {
+		return "", err
+	}
+	if len(members) == 0 {
+		return "", nil
+	}
+	return members[0].Role, nil
+}
+
+// WorkspaceAccess RequireWorkspaceRole'ün çözdüğü çalışma alanı ve rol.
+type WorkspaceAccess struct {
+	WorkspaceID string
+	UserID      string
+	Role        string
+}
+
+const workspaceAccessKey contextKey = "workspaceAccess"
+
+// WorkspaceAccessFromContext RequireWorkspaceRole'ün eklediği erişim bilgisini döndürür.
+func WorkspaceAccessFromContext(ctx context.Context) (*WorkspaceAccess, bool) {
+	a, ok := ctx.Value(workspaceAccessKey).(*WorkspaceAccess)
+	return a, ok
+}
+
+// RequireWorkspaceRole URL'deki {id} çalışma alanında en az minRole rolünü zorunlu kılar.
+// Okuma (viewer) read:tasks, diğerleri admin:board kapsamı ister.
+func RequireWorkspaceRole(minRole string) func(http.Handler) http.Handler {
+	scope := ScopeAdminBoard
+	if minRole == RoleViewer {
+		scope = ScopeReadTasks
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+				return
+			}
+			if !principal.HasScope(scope) {
+				http.Error(w, fmt.Sprintf("Token kapsamı yetersiz (gerekli: %s)", scope), http.StatusForbidden)
+				return
+			}
+
+			workspaceID := chi.URLParam(r, "id")
+			if workspaceID == "" {
+				http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+				return
+			}
+
+			role, err := workspaceRole(bearerToken(r), workspaceID, principal.UserID)
+			if err != nil {
+				fmt.Println("Rol Yükleme Hatası:", err)
+				http.Error(w, "Yetki kontrolü yapılamadı", http.StatusInternalServerError)
+				return
+			}
+			if role == "" {
+				http.Error(w, "Bu çalışma alanına erişim yetkiniz yok", http.StatusForbidden)
+				return
+			}
+			if roleRank[role] < roleRank[minRole] {
+				http.Error(w, fmt.Sprintf("Bu işlem için yetkiniz yok (rol: %s)", role), http.StatusForbidden)
+				return
+			}
+
+			access := &WorkspaceAccess{WorkspaceID: workspaceID, UserID: principal.UserID, Role: role}
+			ctx := context.WithValue(r.Context(), workspaceAccessKey, access)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// loadWorkspaces kullanıcının üyesi olduğu çalışma alanlarını rolüyle birlikte getirir
+// (kişisel alan önce, sonra ada göre).
+func loadWorkspaces(token, userID string) ([]Workspace, error) {
+	endpoint := fmt.Sprintf("workspace_members?user_id=eq.%s&select=role,workspaces(*)", userID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Role      string     `json:"role"`
+		Workspace *Workspace `json:"workspaces"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]Workspace, 0, len(rows))
+	for _, row := range rows {
+		if row.Workspace == nil {
+			continue
+		}
+		ws := *row.Workspace
+		ws.Role = row.Role
+		workspaces = append(workspaces, ws)
+	}
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		if workspaces[i].Personal != workspaces[j].Personal {
+			return workspaces[i].Personal
+		}
+		return strings.ToLower(workspaces[i].Name) < strings.ToLower(workspaces[j].Name)
+	})
+	return workspaces, nil
+}
+
+// groupBoardsByWorkspace panoları çalışma alanlarının sırasıyla gruplar. Çağıranın üyesi olmadığı
+// çalışma alanındaki panolar (pano daveti ile erişilenler) Workspace'i nil olan son gruba düşer.
+func groupBoardsByWorkspace(boards []json.RawMessage, workspaces []Workspace) ([]WorkspaceBoards, error) {
+	groups := make([]WorkspaceBoards, 0, len(workspaces)+1)
+	index := make(map[string]int, len(workspaces))
+	for i := range workspaces {
+		index[workspaces[i].ID] = len(groups)
+		groups = append(groups, WorkspaceBoards{Workspace: &workspaces[i], Boards: []json.RawMessage{}})
+	}
+
+	var shared []json.RawMessage
+	for _, raw := range boards {
+		var b struct {
+			WorkspaceID *string `json:"workspace_id"`
+		}
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, err
+		}
+		if b.WorkspaceID != nil {
+			if i, ok := index[*b.WorkspaceID]; ok {
+				groups[i].Boards = append(groups[i].Boards, raw)
+				continue
+			}
+		}
+		shared = append(shared, raw)
+	}
+	if len(shared) > 0 {
+		groups = append(groups, WorkspaceBoards{Boards: shared})
+	}
+	return groups, nil
+}
+
+// GetWorkspaces kullanıcının çalışma alanlarını getirir.
+func GetWorkspaces(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+
+	workspaces, err := loadWorkspaces(token, principal.UserID)
+	if err != nil {
+		fmt.Println("GetWorkspaces Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workspaces)
+}
+
+// CreateWorkspace yeni bir çalışma alanı oluşturur; oluşturan sahibi olur.
+func CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	var req WorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		http.Error(w, "Çalışma alanı adı gerekli", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := performSupabaseRequest("POST", "rpc/create_workspace", token, map[string]string{"p_name": name})
+	if err != nil {
+		fmt.Println("CreateWorkspace Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// UpdateWorkspace çalışma alanını yeniden adlandırır.
+func UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := WorkspaceAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req WorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		http.Error(w, "Çalışma alanı adı gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("workspaces?id=eq.%s", access.WorkspaceID)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"name": name})
+	if err != nil {
+		fmt.Println("UpdateWorkspace Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// DeleteWorkspace boş bir çalışma alanını siler. Kişisel alan ve panosu olan alanlar silinemez.
+func DeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := WorkspaceAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("workspaces?id=eq.%s&select=personal", access.WorkspaceID), token, nil)
+	if err != nil {
+		fmt.Println("DeleteWorkspace Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var workspaces []Workspace
+	if err := json.Unmarshal(resp, &workspaces); err != nil || len(workspaces) == 0 {
+		http.Error(w, "Çalışma alanı bulunamadı", http.StatusNotFound)
+		return
+	}
+	if workspaces[0].Personal {
+		http.Error(w, "Kişisel çalışma alanı silinemez", http.StatusConflict)
+		return
+	}
+
+	// Panolar başka bir alana taşınmadan (veya silinmeden) çalışma alanı silinemez
+	resp, err = performSupabaseRequest("GET", fmt.Sprintf("boards?workspace_id=eq.%s&select=id&limit=1", access.WorkspaceID), token, nil)
+	if err != nil {
+		fmt.Println("DeleteWorkspace Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var boards []Board
+	if err := json.Unmarshal(resp, &boards); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(boards) > 0 {
+		http.Error(w, "Çalışma alanında pano var; önce panoları taşıyın veya silin", http.StatusConflict)
+		return
+	}
+
+	resp, err = performSupabaseRequest("DELETE", fmt.Sprintf("workspaces?id=eq.%s", access.WorkspaceID), token, nil)
+	if err != nil {
+		fmt.Println("DeleteWorkspace Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Çalışma alanı silindi", "details": string(resp)})
+}
+
+// GetWorkspaceMembers çalışma alanı üyelerini getirir.
+func GetWorkspaceMembers(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := WorkspaceAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	endpoint := fmt.Sprintf("workspace_members?workspace_id=eq.%s&select=user_id,role,profiles(email)&order=created_at.asc", access.WorkspaceID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("GetWorkspaceMembers Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// AddWorkspaceMember kullanıcıyı e-posta ile çalışma alanına ekler; alanın tüm panolarına erişir.
+// Sahip ve admin rolünü sadece sahipler verebilir.
+func AddWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := WorkspaceAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req WorkspaceMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if req.Role == "" {
+		req.Role = RoleMember
+	}
+	if email == "" || !validWorkspaceRole(req.Role) {
+		http.Error(w, "Geçerli bir email ve rol (owner, admin, member, viewer) gerekli", http.StatusBadRequest)
+		return
+	}
+	if roleRank[req.Role] >= roleRank[RoleAdmin] && access.Role != RoleOwner {
+		http.Error(w, "Admin ve sahip rolünü sadece çalışma alanı sahibi verebilir", http.StatusForbidden)
+		return
+	}
+
+	users, err := lookupUsersByEmail(token, []string{email})
+	if err != nil {
+		fmt.Println("AddWorkspaceMember Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	userID, ok := users[email]
+	if !ok {
+		http.Error(w, "Bu e-posta ile kayıtlı kullanıcı bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	current, err := workspaceRole(token, access.WorkspaceID, userID)
+	if err != nil {
+		fmt.Println("AddWorkspaceMember Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if current != "" {
+		http.Error(w, "Kullanıcı zaten çalışma alanının üyesi", http.StatusConflict)
+		return
+	}
+
+	member := map[string]string{"workspace_id": access.WorkspaceID, "user_id": userID, "role": req.Role}
+	resp, err := performSupabaseRequest("POST", "workspace_members", token, member)
+	if err != nil {
+		fmt.Println("AddWorkspaceMember Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// UpdateWorkspaceMemberRole bir üyenin çalışma alanındaki rolünü değiştirir.
+// Sahip/admin rolleri sadece sahipler tarafından değiştirilebilir; son sahip düşürülemez.
+func UpdateWorkspaceMemberRole(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := WorkspaceAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req WorkspaceMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" || !validWorkspaceRole(req.Role) {
+		http.Error(w, "Geçerli bir user_id ve rol (owner, admin, member, viewer) gerekli", http.StatusBadRequest)
+		return
+	}
+
+	current, err := workspaceRole(token, access.WorkspaceID, req.UserID)
+	if err != nil {
+		fmt.Println("UpdateWorkspaceMemberRole Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if current == "" {
+		http.Error(w, "Kullanıcı bu çalışma alanının üyesi değil", http.StatusNotFound)
+		return
+	}
+	if access.Role != RoleOwner && (roleRank[current] >= roleRank[RoleAdmin] || roleRank[req.Role] >= roleRank[RoleAdmin]) {
+		http.Error(w, "Admin ve sahip rollerini sadece çalışma alanı sahibi değiştirebilir", http.StatusForbidden)
+		return
+	}
+	if current == RoleOwner && req.Role != RoleOwner {
+		others, err := otherOwnersExist(token, access.WorkspaceID)
+		if err != nil {
+			fmt.Println("UpdateWorkspaceMemberRole Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !others {
+			http.Error(w, "Çalışma alanının son sahibi kaldırılamaz; önce başka bir üyeyi sahip yapın", http.StatusConflict)
+			return
+		}
+	}
+
+	endpoint := fmt.Sprintf("workspace_members?workspace_id=eq.%s&user_id=eq.%s", access.WorkspaceID, req.UserID)
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"role": req.Role})
+	if err != nil {
+		fmt.Println("UpdateWorkspaceMemberRole Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// RemoveWorkspaceMember üyeyi çalışma alanından çıkarır (?user_id=). Herkes kendini çıkarabilir
+// (alandan ayrılma); başkalarını adminler, adminleri ve sahipleri sadece sahipler çıkarabilir.
+func RemoveWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := WorkspaceAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Çalışma alanı ID gerekli", http.StatusBadRequest)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "user_id parametresi gerekli", http.StatusBadRequest)
+		return
+	}
+
+	current, err := workspaceRole(token, access.WorkspaceID, userID)
+	if err != nil {
+		fmt.Println("RemoveWorkspaceMember Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if current == "" {
+		http.Error(w, "Kullanıcı bu çalışma alanının üyesi değil", http.StatusNotFound)
+		return
+	}
+	if userID != access.UserID {
+		if roleRank[access.Role] < roleRank[RoleAdmin] {
+			http.Error(w, "Üye çıkarma yetkiniz yok", http.StatusForbidden)
+			return
+		}
+		if roleRank[current] >= roleRank[RoleAdmin] && access.Role != RoleOwner {
+			http.Error(w, "Admin ve sahipleri sadece çalışma alanı sahibi çıkarabilir", http.StatusForbidden)
+			return
+		}
+	}
+	if current == RoleOwner {
+		others, err := otherOwnersExist(token, access.WorkspaceID)
+		if err != nil {
+			fmt.Println("RemoveWorkspaceMember Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !others {
+			http.Error(w, "Çalışma alanının son sahibi kaldırılamaz; önce başka bir üyeyi sahip yapın", http.StatusConflict)
+			return
+		}
+	}
+
+	endpoint := fmt.Sprintf("workspace_members?workspace_id=eq.%s&user_id=eq.%s", access.WorkspaceID, userID)
+	resp, err := performSupabaseRequest("DELETE", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("RemoveWorkspaceMember Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Üye çalışma alanından çıkarıldı", "details": string(resp)})
+}
+
+// otherOwnersExist bir sahibin rolü düşürülmeden/çıkarılmadan önce başka bir sahip kaldığını doğrular.
+func otherOwnersExist(token, workspaceID string) (bool, error) {
+	endpoint := fmt.Sprintf("workspace_members?workspace_id=eq.%s&role=eq.%s&select=user_id", workspaceID, RoleOwner)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return false, err
+	}
+	var owners []WorkspaceMember
+	if err := json.Unmarshal(resp, &owners); err != nil {
+		return false, err
+	}
+	return len(owners) > 1, nil
+}
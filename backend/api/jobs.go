@@ -0,0 +1,167 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// İş durumları
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// jobRetention tamamlanan işlerin sorgulanabilir kaldığı süre
+const jobRetention = time.Hour
+
+// jobTokenLifetime arka plan işi için üretilen token'ın ömrü. İstek token'ı (PAT için 5 dakikalık
+// JWT) büyük kopyalama ve içe aktarmalar bitmeden dolabilir; yarım kalan pano temizlenemez.
+const jobTokenLifetime = 2 * time.Hour
+
+// jobMinTokenLifetime token üretilemiyorsa işe başlamak için istek token'ında kalması gereken süre
+const jobMinTokenLifetime = 30 * time.Minute
+
+// errJobTokenExpiring istek token'ı arka plan işi için çok kısa ömürlü
+var errJobTokenExpiring = errors.New("oturumun süresi arka plan işi için çok kısa, token'ı yenileyip tekrar deneyin")
+
+// jobToken arka plan işinin Supabase isteklerinde kullanacağı token'ı döndürür. SUPABASE_JWT_SECRET
+// varsa kullanıcı adına jobTokenLifetime ömürlü bir JWT üretilir; yoksa istek token'ı ancak en az
+// jobMinTokenLifetime daha geçerliyse kullanılır.
+func jobToken(principal *Principal, token string) (string, error) {
+	if len(getVerifier().Secret) > 0 {
+		return mintUserToken(principal.UserID, principal.Email, jobTokenLifetime)
+	}
+	if principal.ExpiresAt.IsZero() || time.Until(principal.ExpiresAt) < jobMinTokenLifetime {
+		return "", errJobTokenExpiring
+	}
+	return token, nil
+}
+
+// Job arka planda çalışan uzun bir işlem (ör. büyük pano kopyalama).
+// İşler bellekte tutulur; sunucu yeniden başlarsa kaybolur.
+type Job struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	UserID     string      `json:"-"`
+	Status     string      `json:"status"`
+	Done       int         `json:"done"`
+	Total      int         `json:"total"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+
+	mu sync.Mutex
+}
+
+// jobStore çalışan ve yakın zamanda biten işler
+type jobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+var jobs = &jobStore{jobs: make(map[string]*Job)}
+
+func (s *jobStore) create(jobType, userID string) *Job {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+
+	job := &Job{
+		ID:        hex.EncodeToString(buf),
+		Type:      jobType,
+		UserID:    userID,
+		Status:    JobRunning,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cleanup()
+	s.jobs[job.ID] = job
+	return job
+}
+
+func (s *jobStore) get(id string) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// cleanup saklama süresi dolan işleri siler (kilit altında çağrılır).
+func (s *jobStore) cleanup() {
+	for id, job := range s.jobs {
+		job.mu.Lock()
+		expired := job.FinishedAt != nil && time.Since(*job.FinishedAt) > jobRetention
+		job.mu.Unlock()
+		if expired {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// SetTotal işin toplam adım sayısını belirler.
+func (j *Job) SetTotal(total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Total = total
+}
+
+// Advance ilerlemeyi n adım artırır.
+func (j *Job) Advance(n int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Done += n
+}
+
+// Finish işi sonucu veya hatasıyla kapatır.
+func (j *Job) Finish(result interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.FinishedAt = &now
+	if err != nil {
+		j.Status = JobFailed
+		j.Error = err.Error()
+		return
+	}
+	j.Status = JobCompleted
+	j.Result = result
+	j.Done = j.Total
+}
+
+// MarshalJSON ilerleme alanlarını kilit altında okur.
+func (j *Job) MarshalJSON() ([]byte, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	type jobView Job
+	return json.Marshal((*jobView)(j))
+}
+
+// GetJob arka plan işinin durumunu ve ilerlemesini döndürür.
+func GetJob(w http.ResponseWriter, r *http.Request) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+		return
+	}
+
+	job, ok := jobs.get(chi.URLParam(r, "id"))
+	if !ok || job.UserID != principal.UserID {
+		http.Error(w, "İş bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
@@ -2,12 +2,184 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go-panel/backend/auth"
 	"go-panel/backend/db"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// Principal doğrulanmış isteğin sahibini temsil eder.
+type Principal struct {
+	UserID    string
+	Email     string
+	Role      string    // Supabase rolü (authenticated, service_role ...)
+	ExpiresAt time.Time // Token bitiş zamanı (uzaktan doğrulamada bilinmeyebilir)
+	Method    string    // "jwt", "remote" veya "pat"
+	Scopes    []string  // Sadece PAT için; nil ise oturum tüm yetkilere sahiptir
+	Token     string    // Supabase REST isteklerinde kullanılacak token (PAT için kısa ömürlü JWT)
+}
+
+// HasScope PAT kapsamlarını kontrol eder; tarayıcı oturumları kapsamla sınırlı değildir.
+func (p *Principal) HasScope(scope string) bool {
+	if p.Scopes == nil || scope == "" {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// PrincipalFromContext AuthMiddleware'in eklediği kullanıcıyı döndürür.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey).(*Principal)
+	return p, ok
+}
+
+var (
+	verifier     *auth.Verifier
+	verifierOnce sync.Once
+)
+
+func getVerifier() *auth.Verifier {
+	verifierOnce.Do(func() {
+		verifier = auth.NewVerifierFromEnv()
+	})
+	return verifier
+}
+
+// authenticate token'ı AUTH_MODE'a göre yerelde veya Supabase Auth ile doğrular.
+func authenticate(ctx context.Context, token string) (*Principal, error) {
+	if auth.IsPAT(token) {
+		return authenticatePAT(token)
+	}
+
+	mode := auth.Mode()
+	v := getVerifier()
+
+	if mode != auth.ModeRemote && v.Configured() {
+		claims, err := v.Verify(token)
+		if err == nil {
+			return &Principal{
+				UserID:    claims.Subject,
+				Email:     claims.Email,
+				Role:      claims.Role,
+				ExpiresAt: claims.Expiry(),
+				Method:    "jwt",
+				Token:     token,
+			}, nil
+		}
+		// Token geçersizse uzaktan sormanın anlamı yok; sadece anahtar yoksa düş.
+		if mode == auth.ModeLocal || !errors.Is(err, auth.ErrKeyUnavailable) {
+			return nil, err
+		}
+	} else if mode == auth.ModeLocal {
+		return nil, auth.ErrKeyUnavailable
+	}
+
+	return authenticateRemote(ctx, token)
+}
+
+// authenticateRemote kullanıcı detaylarını Supabase Auth istemcisiyle alır.
+func authenticateRemote(ctx context.Context, token string) (*Principal, error) {
+	user, err := db.Client.Auth.User(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		ExpiresAt: auth.UnverifiedExpiry(token),
+		Method:    "remote",
+		Token:     token,
+	}, nil
+}
+
+// patTokenLifetime PAT isteği için üretilen Supabase JWT'sinin ömrü
+const patTokenLifetime = 5 * time.Minute
+
+// authenticatePAT kişisel erişim token'ını hash'i üzerinden doğrular (son kullanım zamanı da güncellenir)
+// ve RLS'in çalışması için kullanıcı adına kısa ömürlü bir JWT üretir.
+func authenticatePAT(token string) (*Principal, error) {
+	v := getVerifier()
+	if len(v.Secret) == 0 {
+		return nil, errors.New("PAT doğrulaması için SUPABASE_JWT_SECRET gerekli")
+	}
+
+	body := map[string]string{"p_token_hash": auth.HashPAT(token)}
+	resp, err := performSupabaseRequest("POST", "rpc/verify_personal_access_token", os.Getenv("SUPABASE_KEY"), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		UserID    string     `json:"user_id"`
+		Email     string     `json:"email"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("geçersiz, süresi dolmuş veya iptal edilmiş PAT")
+	}
+	row := rows[0]
+
+	minted, err := mintUserToken(row.UserID, row.Email, patTokenLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		UserID: row.UserID,
+		Email:  row.Email,
+		Role:   "authenticated",
+		Method: "pat",
+		Scopes: row.Scopes,
+		Token:  minted,
+	}
+	if principal.Scopes == nil {
+		principal.Scopes = []string{}
+	}
+	if row.ExpiresAt != nil {
+		principal.ExpiresAt = *row.ExpiresAt
+	}
+	return principal, nil
+}
+
+// mintUserToken kullanıcı adına SUPABASE_JWT_SECRET ile imzalı, verilen ömürde bir Supabase JWT'si üretir.
+func mintUserToken(userID, email string, lifetime time.Duration) (string, error) {
+	v := getVerifier()
+	if len(v.Secret) == 0 {
+		return "", errors.New("token üretmek için SUPABASE_JWT_SECRET gerekli")
+	}
+
+	now := time.Now()
+	return auth.SignHS256(map[string]interface{}{
+		"sub":   userID,
+		"email": email,
+		"role":  "authenticated",
+		"aud":   v.Audience,
+		"iss":   v.Issuer,
+		"iat":   now.Unix(),
+		"exp":   now.Add(lifetime).Unix(),
+	}, v.Secret)
+}
+
 // AuthMiddleware Supabase JWT token'ını doğrular.
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -19,18 +191,21 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Token'ı Supabase Auth ile doğrula
-		// Kullanıcı detaylarını almak için Supabase Auth istemcisini kullanıyoruz.
-		ctx := context.Background()
-		user, err := db.Client.Auth.User(ctx, tokenString)
+		principal, err := authenticate(r.Context(), tokenString)
 		if err != nil {
 			fmt.Println("Yetkilendirme Hatası:", err)
 			http.Error(w, "Geçersiz Token", http.StatusUnauthorized)
 			return
 		}
 
-		// Kullanıcı ID'sini request context'e ekle
-		ctx = context.WithValue(r.Context(), "userID", user.ID)
+		// PAT ile gelindiyse handler'lar Supabase'e kullanıcı adına üretilen JWT ile gitsin
+		if principal.Token != tokenString {
+			r.Header.Set("Authorization", "Bearer "+principal.Token)
+		}
+
+		// Kullanıcı ID'sini ve Principal'ı request context'e ekle
+		ctx := context.WithValue(r.Context(), "userID", principal.UserID)
+		ctx = context.WithValue(ctx, principalKey, principal)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -0,0 +1,501 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Pano rolleri. Sahip boards.user_id üzerinden belirlenir, diğerleri board_members.role'dan gelir.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// Permission pano üzerinde yapılabilecek bir işlem.
+type Permission string
+
+const (
+	PermRead           Permission = "read"
+	PermComment        Permission = "comment"
+	PermEditTasks      Permission = "edit_tasks"
+	PermDeleteOwnTasks Permission = "delete_own_tasks"
+	PermDeleteTasks    Permission = "delete_tasks"
+	PermManageMembers  Permission = "manage_members"
+	PermManageBoard    Permission = "manage_board"
+	PermDeleteBoard    Permission = "delete_board"
+	PermShareBoard     Permission = "share_board" // herkese açık salt okunur linkler
+)
+
+// rolePermissions izin matrisi
+var rolePermissions = map[string][]Permission{
+	RoleOwner:  {PermRead, PermComment, PermEditTasks, PermDeleteOwnTasks, PermDeleteTasks, PermManageMembers, PermManageBoard, PermDeleteBoard, PermShareBoard},
+	RoleAdmin:  {PermRead, PermComment, PermEditTasks, PermDeleteOwnTasks, PermDeleteTasks, PermManageMembers, PermManageBoard},
+	RoleMember: {PermRead, PermComment, PermEditTasks, PermDeleteOwnTasks},
+	RoleViewer: {PermRead},
+}
+
+// PAT kapsamları (scopes)
+const (
+	ScopeReadTasks  = "read:tasks"
+	ScopeWriteTasks = "write:tasks"
+	ScopeChat       = "chat"
+	ScopeAdminBoard = "admin:board"
+)
+
+// ValidScopes PAT oluştururken kabul edilen kapsamlar
+var ValidScopes = []string{ScopeReadTasks, ScopeWriteTasks, ScopeChat, ScopeAdminBoard}
+
+// permissionScopes her iznin PAT ile kullanılabilmesi için gereken kapsam
+var permissionScopes = map[Permission]string{
+	PermRead:           ScopeReadTasks,
+	PermComment:        ScopeChat,
+	PermEditTasks:      ScopeWriteTasks,
+	PermDeleteOwnTasks: ScopeWriteTasks,
+	PermDeleteTasks:    ScopeWriteTasks,
+	PermManageMembers:  ScopeAdminBoard,
+	PermManageBoard:    ScopeAdminBoard,
+	PermDeleteBoard:    ScopeAdminBoard,
+	PermShareBoard:     ScopeAdminBoard,
+}
+
+// ValidMemberRole board_members.role kolonuna yazılabilecek roller (sahiplik devredilerek değişir).
+func ValidMemberRole(role string) bool {
+	return role == RoleAdmin || role == RoleMember || role == RoleViewer
+}
+
+// RoleAllows rolün verilen izne sahip olup olmadığını döndürür.
+func RoleAllows(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// BoardAccess yetkilendirme middleware'inin çözdüğü pano ve rol bilgisi.
+type BoardAccess struct {
+	BoardID     string
+	UserID      string
+	Role        string
+	TaskOwnerID string // Görev üzerinden çözüldüyse görevi oluşturan kullanıcı
+	TaskStatus  string // Görev üzerinden çözüldüyse görevin mevcut durumu
+	Archived    bool   // Pano arşivlenmiş (salt okunur)
+}
+
+// Can rol matrisine göre izni kontrol eder.
+// Üyeler, silme yetkileri olmasa da kendi oluşturdukları görevleri silebilir.
+func (a *BoardAccess) Can(perm Permission) bool {
+	if RoleAllows(a.Role, perm) {
+		return true
+	}
+	if perm == PermDeleteTasks && a.TaskOwnerID != "" && a.TaskOwnerID == a.UserID {
+		return RoleAllows(a.Role, PermDeleteOwnTasks)
+	}
+	return false
+}
+
+const boardAccessKey contextKey = "boardAccess"
+
+// BoardAccessFromContext RequireBoardPermission'ın eklediği erişim bilgisini döndürür.
+func BoardAccessFromContext(ctx context.Context) (*BoardAccess, bool) {
+	a, ok := ctx.Value(boardAccessKey).(*BoardAccess)
+	return a, ok
+}
+
+// errBoardNotFound kaynak bulunamadı veya RLS tarafından gizlendi.
+var errBoardNotFound = errors.New("kaynak bulunamadı")
+
+// requestError istemci kaynaklı (400) hatalar
+type requestError string
+
+func (e requestError) Error() string { return string(e) }
+
+// BoardTarget resolver'ın bulduğu pano (ve görev üzerinden geldiyse görev sahibi).
+type BoardTarget struct {
+	BoardID       string
+	TaskOwnerID   string
+	TaskStatus    string
+	AllowArchived bool // Rota arşivlenmiş panoda da yazabilir (ör. arşivden çıkarma)
+}
+
+// archivedPermissions arşivlenmiş panoda hâlâ izin verilen işlemler (okuma ve panoyu silme)
+var archivedPermissions = map[Permission]bool{
+	PermRead:        true,
+	PermDeleteBoard: true,
+}
+
+// BoardResolver isteğin hedeflediği panoyu bulur.
+// Boş BoardID ve nil hata, isteğin pano kapsamında olmadığını belirtir (kontrol atlanır).
+type BoardResolver func(r *http.Request, token string) (BoardTarget, error)
+
+// RequireBoardPermission çağıranın hedef panodaki rolünü yükler ve izni zorunlu kılar.
+func RequireBoardPermission(perm Permission, resolve BoardResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r)
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+				return
+			}
+
+			if !principal.HasScope(permissionScopes[perm]) {
+				http.Error(w, fmt.Sprintf("Token kapsamı yetersiz (gerekli: %s)", permissionScopes[perm]), http.StatusForbidden)
+				return
+			}
+
+			target, err := resolve(r, token)
+			if errors.Is(err, errBoardNotFound) {
+				http.Error(w, "Kaynak bulunamadı", http.StatusNotFound)
+				return
+			}
+			var reqErr requestError
+			if errors.As(err, &reqErr) {
+				http.Error(w, reqErr.Error(), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				fmt.Println("Pano Çözümleme Hatası:", err)
+				http.Error(w, "Yetki kontrolü yapılamadı", http.StatusInternalServerError)
+				return
+			}
+			if target.BoardID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			role, archived, err := boardRoleState(token, target.BoardID, principal.UserID)
+			if err != nil {
+				fmt.Println("Rol Yükleme Hatası:", err)
+				http.Error(w, "Yetki kontrolü yapılamadı", http.StatusInternalServerError)
+				return
+			}
+			if role == "" {
+				http.Error(w, "Bu panoya erişim yetkiniz yok", http.StatusForbidden)
+				return
+			}
+
+			access := &BoardAccess{
+				BoardID:     target.BoardID,
+				UserID:      principal.UserID,
+				Role:        role,
+				TaskOwnerID: target.TaskOwnerID,
+				TaskStatus:  target.TaskStatus,
+				Archived:    archived,
+			}
+			if !access.Can(perm) {
+				http.Error(w, fmt.Sprintf("Bu işlem için yetkiniz yok (rol: %s)", role), http.StatusForbidden)
+				return
+			}
+			if archived && !archivedPermissions[perm] && !target.AllowArchived {
+				http.Error(w, "Pano arşivlenmiş, salt okunur", http.StatusConflict)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), boardAccessKey, access)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope pano kapsamında olmayan rotalar için PAT kapsamını zorunlu kılar.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+				return
+			}
+			if !principal.HasScope(scope) {
+				http.Error(w, fmt.Sprintf("Token kapsamı yetersiz (gerekli: %s)", scope), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession PAT ile yapılamayacak işlemler (ör. yeni token üretmek) için tarayıcı oturumu ister.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Kullanıcı Oturumu Bulunamadı", http.StatusUnauthorized)
+			return
+		}
+		if principal.Method == "pat" {
+			http.Error(w, "Bu işlem kişisel erişim token'ı ile yapılamaz", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken Authorization başlığındaki token'ı döndürür.
+func bearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// peekBody gövdeyi okuyup handler için yerine koyar.
+func peekBody(r *http.Request, v interface{}) error {
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+	if err := json.Unmarshal(bodyBytes, v); err != nil {
+		return requestError("Geçersiz İstek Gövdesi")
+	}
+	return nil
+}
+
+// AllowArchived resolver'ın bulduğu panoda, pano arşivlenmiş olsa da işleme izin verir.
+func AllowArchived(resolve BoardResolver) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		target, err := resolve(r, token)
+		target.AllowArchived = true
+		return target, err
+	}
+}
+
+// BoardFromQuery pano ID'sini query parametresinden alır.
+func BoardFromQuery(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		boardID := r.URL.Query().Get(param)
+		if boardID == "" {
+			return BoardTarget{}, requestError(param + " parametresi gerekli")
+		}
+		return BoardTarget{BoardID: boardID}, nil
+	}
+}
+
+// BoardFromURLParam pano ID'sini rota parametresinden alır (/boards/{id}/...).
+func BoardFromURLParam(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		boardID := chi.URLParam(r, param)
+		if boardID == "" {
+			return BoardTarget{}, requestError(param + " parametresi gerekli")
+		}
+		return BoardTarget{BoardID: boardID}, nil
+	}
+}
+
+// OptionalBoardFromQuery parametre yoksa kontrolü atlar (RLS kapsamındaki listeleme istekleri için).
+func OptionalBoardFromQuery(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		return BoardTarget{BoardID: r.URL.Query().Get(param)}, nil
+	}
+}
+
+// BoardFromBody pano ID'sini JSON gövdesindeki board_id alanından alır.
+func BoardFromBody(r *http.Request, token string) (BoardTarget, error) {
+	var body struct {
+		BoardID string `json:"board_id"`
+	}
+	if err := peekBody(r, &body); err != nil {
+		return BoardTarget{}, err
+	}
+	if body.BoardID == "" {
+		return BoardTarget{}, requestError("board_id gerekli")
+	}
+	return BoardTarget{BoardID: body.BoardID}, nil
+}
+
+// BoardFromTaskQuery görev ID'sini query'den alıp görevin panosunu bulur.
+func BoardFromTaskQuery(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		taskID := r.URL.Query().Get(param)
+		if taskID == "" {
+			return BoardTarget{}, requestError(param + " parametresi gerekli")
+		}
+		return lookupTaskBoard(token, taskID)
+	}
+}
+
+// BoardFromTaskBody görev ID'sini JSON gövdesindeki id alanından alır.
+func BoardFromTaskBody(r *http.Request, token string) (BoardTarget, error) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := peekBody(r, &body); err != nil {
+		return BoardTarget{}, err
+	}
+	if body.ID == "" {
+		return BoardTarget{}, requestError("Görev ID gerekli")
+	}
+	return lookupTaskBoard(token, body.ID)
+}
+
+// BoardFromTaskIDBody görev ID'sini JSON gövdesindeki task_id alanından alır (görev etiketleri gibi alt kaynaklar).
+func BoardFromTaskIDBody(r *http.Request, token string) (BoardTarget, error) {
+	var body struct {
+		TaskID string `json:"task_id"`
+	}
+	if err := peekBody(r, &body); err != nil {
+		return BoardTarget{}, err
+	}
+	if body.TaskID == "" {
+		return BoardTarget{}, requestError("task_id gerekli")
+	}
+	return lookupTaskBoard(token, body.TaskID)
+}
+
+// BoardFromBlockedTaskBody bağımlılık isteğinde engellenen görevin panosunu bulur; bağımlılık o görevi
+// kısıtladığı için yetki o panoda aranır.
+func BoardFromBlockedTaskBody(r *http.Request, token string) (BoardTarget, error) {
+	var req DependencyRequest
+	if err := peekBody(r, &req); err != nil {
+		return BoardTarget{}, err
+	}
+	_, blocked, err := req.link()
+	if err != nil {
+		return BoardTarget{}, err
+	}
+	return lookupTaskBoard(token, blocked)
+}
+
+// BoardFromSubtaskBody alt görev gövdesinden (id veya task_id) panoyu bulur.
+func BoardFromSubtaskBody(r *http.Request, token string) (BoardTarget, error) {
+	var body struct {
+		ID     string `json:"id"`
+		TaskID string `json:"task_id"`
+	}
+	if err := peekBody(r, &body); err != nil {
+		return BoardTarget{}, err
+	}
+	if body.ID != "" {
+		return lookupSubtaskBoard(token, body.ID)
+	}
+	if body.TaskID != "" {
+		return lookupTaskBoard(token, body.TaskID)
+	}
+	return BoardTarget{}, requestError("task_id gerekli")
+}
+
+// BoardFromSubtaskQuery alt görev ID'sini query'den alıp panosunu bulur.
+func BoardFromSubtaskQuery(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		subtaskID := r.URL.Query().Get(param)
+		if subtaskID == "" {
+			return BoardTarget{}, requestError(param + " parametresi gerekli")
+		}
+		return lookupSubtaskBoard(token, subtaskID)
+	}
+}
+
+// BoardFromCommentBody yorum ID'sini JSON gövdesindeki id alanından alıp panosunu bulur.
+func BoardFromCommentBody(r *http.Request, token string) (BoardTarget, error) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := peekBody(r, &body); err != nil {
+		return BoardTarget{}, err
+	}
+	if body.ID == "" {
+		return BoardTarget{}, requestError("Yorum ID gerekli")
+	}
+	return lookupCommentBoard(token, body.ID)
+}
+
+// BoardFromCommentQuery yorum ID'sini query'den alıp panosunu bulur.
+func BoardFromCommentQuery(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		commentID := r.URL.Query().Get(param)
+		if commentID == "" {
+			return BoardTarget{}, requestError(param + " parametresi gerekli")
+		}
+		return lookupCommentBoard(token, commentID)
+	}
+}
+
+// BoardFromAttachmentQuery ek ID'sini query'den alıp panosunu bulur.
+func BoardFromAttachmentQuery(param string) BoardResolver {
+	return func(r *http.Request, token string) (BoardTarget, error) {
+		attachmentID := r.URL.Query().Get(param)
+		if attachmentID == "" {
+			return BoardTarget{}, requestError(param + " parametresi gerekli")
+		}
+		return lookupRowBoard(token, "task_attachments", attachmentID)
+	}
+}
+
+func lookupTaskBoard(token, taskID string) (BoardTarget, error) {
+	endpoint := fmt.Sprintf("tasks?id=eq.%s&select=board_id,user_id,status", taskID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return BoardTarget{}, err
+	}
+
+	var tasks []struct {
+		BoardID string `json:"board_id"`
+		UserID  string `json:"user_id"`
+		Status  string `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		return BoardTarget{}, err
+	}
+	if len(tasks) == 0 || tasks[0].BoardID == "" {
+		return BoardTarget{}, errBoardNotFound
+	}
+	return BoardTarget{BoardID: tasks[0].BoardID, TaskOwnerID: tasks[0].UserID, TaskStatus: tasks[0].Status}, nil
+}
+
+func lookupSubtaskBoard(token, subtaskID string) (BoardTarget, error) {
+	endpoint := fmt.Sprintf("subtasks?id=eq.%s&select=task_id", subtaskID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return BoardTarget{}, err
+	}
+
+	var subtasks []struct {
+		TaskID string `json:"task_id"`
+	}
+	if err := json.Unmarshal(resp, &subtasks); err != nil {
+		return BoardTarget{}, err
+	}
+	if len(subtasks) == 0 {
+		return BoardTarget{}, errBoardNotFound
+	}
+
+	target, err := lookupTaskBoard(token, subtasks[0].TaskID)
+	// Alt görevler için görev sahibi ve durum bilgisi anlamsız
+	target.TaskOwnerID = ""
+	target.TaskStatus = ""
+	return target, err
+}
+
+func lookupCommentBoard(token, commentID string) (BoardTarget, error) {
+	return lookupRowBoard(token, "task_comments", commentID)
+}
+
+// lookupRowBoard board_id kolonu taşıyan bir tablodaki satırın panosunu bulur.
+func lookupRowBoard(token, table, id string) (BoardTarget, error) {
+	endpoint := fmt.Sprintf("%s?id=eq.%s&select=board_id", table, id)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return BoardTarget{}, err
+	}
+
+	var rows []struct {
+		BoardID string `json:"board_id"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return BoardTarget{}, err
+	}
+	if len(rows) == 0 || rows[0].BoardID == "" {
+		return BoardTarget{}, errBoardNotFound
+	}
+	return BoardTarget{BoardID: rows[0].BoardID}, nil
+}
@@ -0,0 +1,430 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Düz metin görev listeleri: todo.txt (http://todotxt.org) ve Markdown checklist.
+// Dışa aktarılan dosyalar aynı panoya geri aktarıldığında aynı görev ve alt görevleri üretir.
+
+// plainBoard dışa aktarılan görevlerin panosu (başlık ve sütunlar)
+type plainBoard struct {
+	ID       string
+	Title    string
+	Workflow Workflow
+}
+
+// todo.txt öncelikleri: (A) yüksek, (B) orta, (C) düşük
+var todoPriorities = map[string]string{
+	PriorityHigh:   "A",
+	PriorityMedium: "B",
+	PriorityLow:    "C",
+}
+
+func todoPriority(letter string) string {
+	switch letter {
+	case "A":
+		return PriorityHigh
+	case "B":
+		return PriorityMedium
+	case "":
+		return ""
+	}
+	return PriorityLow // (C) ve sonrası
+}
+
+// projectTag pano başlığını todo.txt +proje etiketine çevirir (boşluklar "_" olur).
+func projectTag(title string) string {
+	return "+" + strings.Join(strings.Fields(title), "_")
+}
+
+// singleLine satır sonlarını boşluğa çevirir; satır tabanlı biçimler için.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// orderTasks görevleri pano, sütun sırası ve pozisyona göre dizer.
+func orderTasks(tasks []Task, boards map[string]plainBoard) {
+	statusOrder := func(t Task) int {
+		for _, s := range boards[t.BoardID].Workflow.Statuses {
+			if s.Key == t.Status {
+				return s.Order
+			}
+		}
+		return len(boards[t.BoardID].Workflow.Statuses)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].BoardID != tasks[j].BoardID {
+			return boards[tasks[i].BoardID].Title < boards[tasks[j].BoardID].Title
+		}
+		if oi, oj := statusOrder(tasks[i]), statusOrder(tasks[j]); oi != oj {
+			return oi < oj
+		}
+		return tasks[i].Position < tasks[j].Position
+	})
+	for _, t := range tasks {
+		sort.SliceStable(t.Subtasks, func(i, j int) bool { return t.Subtasks[i].Position < t.Subtasks[j].Position })
+	}
+}
+
+// encodeTodoTxt görevleri todo.txt satırlarına çevirir. Alt görevler, ait oldukları görevin
+// id:N etiketine parent:N ile bağlanan ayrı satırlardır.
+//
+//	x (A) Sunumu hazırla +Proje due:2026-03-01 status:Done id:1
+//	x Slaytlar +Proje parent:1
+func encodeTodoTxt(tasks []Task, boards map[string]plainBoard) string {
+	orderTasks(tasks, boards)
+
+	var b strings.Builder
+	for i, t := range tasks {
+		board := boards[t.BoardID]
+		if board.Workflow.Category(t.Status) == CategoryDone {
+			b.WriteString("x ")
+		}
+		if p, ok := normalizePriority(t.Priority); ok && p != "" {
+			fmt.Fprintf(&b, "(%s) ", todoPriorities[p])
+		}
+		b.WriteString(singleLine(t.Title))
+		if board.Title != "" {
+			b.WriteString(" " + projectTag(board.Title))
+		}
+		if t.DueDate != nil && *t.DueDate != "" {
+			if due, ok := normalizeDate(*t.DueDate); ok {
+				b.WriteString(" due:" + due)
+			}
+		}
+		fmt.Fprintf(&b, " status:%s", strings.ReplaceAll(t.Status, " ", "_"))
+		if len(t.Subtasks) > 0 {
+			fmt.Fprintf(&b, " id:%d", i+1)
+		}
+		b.WriteString("\n")
+
+		for _, st := range t.Subtasks {
+			if st.IsCompleted {
+				b.WriteString("x ")
+			}
+			b.WriteString(singleLine(st.Title))
+			if board.Title != "" {
+				b.WriteString(" " + projectTag(board.Title))
+			}
+			fmt.Fprintf(&b, " parent:%d\n", i+1)
+		}
+	}
+	return b.String()
+}
+
+// encodeMarkdown görevleri sütun başlıkları altında Markdown checklist'e çevirir.
+// Birden fazla pano varsa her pano kendi "##" başlığı altındadır; alt görevler girintilidir.
+func encodeMarkdown(title string, tasks []Task, boards map[string]plainBoard) string {
+	orderTasks(tasks, boards)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", singleLine(title))
+
+	multi := false
+	for _, t := range tasks {
+		if t.BoardID != tasks[0].BoardID {
+			multi = true
+			break
+		}
+	}
+
+	statusLevel := "##"
+	if multi {
+		statusLevel = "###"
+	}
+
+	currentBoard, currentStatus := "", ""
+	for _, t := range tasks {
+		board := boards[t.BoardID]
+		if multi && t.BoardID != currentBoard {
+			fmt.Fprintf(&b, "\n## %s\n", singleLine(board.Title))
+			currentStatus = ""
+		}
+		if t.Status != currentStatus || t.BoardID != currentBoard {
+			fmt.Fprintf(&b, "\n%s %s\n\n", statusLevel, statusHeading(board.Workflow, t.Status))
+		}
+		currentBoard, currentStatus = t.BoardID, t.Status
+
+		check := " "
+		if board.Workflow.Category(t.Status) == CategoryDone {
+			check = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] %s", check, singleLine(t.Title))
+		if p, ok := normalizePriority(t.Priority); ok && p != "" {
+			b.WriteString(" priority:" + p)
+		}
+		if t.DueDate != nil && *t.DueDate != "" {
+			if due, ok := normalizeDate(*t.DueDate); ok {
+				b.WriteString(" due:" + due)
+			}
+		}
+		b.WriteString("\n")
+
+		for _, st := range t.Subtasks {
+			check := " "
+			if st.IsCompleted {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "  - [%s] %s\n", check, singleLine(st.Title))
+		}
+	}
+	return b.String()
+}
+
+// statusHeading Markdown'da sütun başlığı; anahtar başlıktan farklıysa parantez içinde eklenir
+// ki içe aktarma aynı sütunu bulsun.
+func statusHeading(wf Workflow, status string) string {
+	for _, s := range wf.Statuses {
+		if s.Key == status {
+			if s.Title == "" || s.Title == s.Key {
+				return s.Key
+			}
+			return fmt.Sprintf("%s (%s)", s.Title, s.Key)
+		}
+	}
+	return status
+}
+
+// PlainTextError içe aktarmada okunamayan satır
+type PlainTextError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// statusFor içe aktarılan satırın sütununu belirler: açık sütun, yoksa tamamlanmışsa ilk "done"
+// sütunu, değilse ilk sütun.
+func statusFor(wf Workflow, explicit string, done bool) (string, bool) {
+	if explicit != "" {
+		return workflowStatusKey(wf, explicit)
+	}
+	if done {
+		for _, s := range wf.Statuses {
+			if s.Category == CategoryDone {
+				return s.Key, true
+			}
+		}
+	}
+	return firstStatus(wf), true
+}
+
+// decodeTodoTxt todo.txt içeriğini görevlere çevirir. +proje ve @bağlam etiketleri başlıktan
+// çıkarılmaz (sadece panonun kendi +etiketi atılır); due:, status:, id:, parent: okunur.
+func decodeTodoTxt(content string, wf Workflow, boardTitle string) ([]Task, []PlainTextError) {
+	var tasks []Task
+	var errs []PlainTextError
+	byID := make(map[string]int) // id:N -> tasks indeksi
+	ownTag := projectTag(boardTitle)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		done := false
+		if strings.HasPrefix(line, "x ") {
+			done = true
+			line = strings.TrimSpace(line[2:])
+		}
+
+		fields := strings.Fields(line)
+		skipDates := func() {
+			// Tamamlanma ve oluşturulma tarihleri (YYYY-MM-DD) atlanır
+			for len(fields) > 0 && len(fields[0]) == 10 && validDate(fields[0]) {
+				fields = fields[1:]
+			}
+		}
+		skipDates()
+
+		priority := ""
+		if len(fields) > 0 && len(fields[0]) == 3 && fields[0][0] == '(' && fields[0][2] == ')' && fields[0][1] >= 'A' && fields[0][1] <= 'Z' {
+			priority = todoPriority(fields[0][1:2])
+			fields = fields[1:]
+			skipDates()
+		}
+		var title []string
+		var due, status, id, parent string
+		for _, f := range fields {
+			key, value, ok := strings.Cut(f, ":")
+			switch {
+			case ok && key == "due" && value != "":
+				due = value
+			case ok && key == "status" && value != "":
+				status = strings.ReplaceAll(value, "_", " ")
+			case ok && key == "id" && value != "":
+				id = value
+			case ok && key == "parent" && value != "":
+				parent = value
+			case ok && key == "pri" && len(value) == 1: // tamamlanan görevlerde öncelik pri:A olarak saklanabilir
+				priority = todoPriority(strings.ToUpper(value))
+			case f == ownTag:
+			default:
+				title = append(title, f)
+			}
+		}
+
+		text := strings.Join(title, " ")
+		if text == "" {
+			errs = append(errs, PlainTextError{Line: n, Message: "başlık boş"})
+			continue
+		}
+
+		if parent != "" {
+			idx, ok := byID[parent]
+			if !ok {
+				errs = append(errs, PlainTextError{Line: n, Message: fmt.Sprintf("parent:%s tanımlı değil (görev satırı alt görevlerden önce gelmeli)", parent)})
+				continue
+			}
+			tasks[idx].Subtasks = append(tasks[idx].Subtasks, Subtask{Title: text, IsCompleted: done, Position: len(tasks[idx].Subtasks)})
+			continue
+		}
+
+		task := Task{Title: text, Priority: priority, Position: len(tasks)}
+		// status:Todo_List gibi alt çizgili anahtarlar da eşleşsin
+		key, ok := statusFor(wf, status, done)
+		if !ok && status != "" {
+			key, ok = statusFor(wf, strings.ReplaceAll(status, " ", "_"), done)
+		}
+		if !ok {
+			errs = append(errs, PlainTextError{Line: n, Message: fmt.Sprintf("tanımsız durum %q", status)})
+			continue
+		}
+		task.Status = key
+		if due != "" {
+			normalized, ok := normalizeDate(due)
+			if !ok {
+				errs = append(errs, PlainTextError{Line: n, Message: fmt.Sprintf("geçersiz tarih %q", due)})
+				continue
+			}
+			task.DueDate = &normalized
+		}
+		if id != "" {
+			byID[id] = len(tasks)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, errs
+}
+
+// decodeMarkdown Markdown checklist'i görevlere çevirir. Başlıklar (#, ##, ###) sütun adıyla
+// eşleşirse sonraki maddelerin sütunu olur; girintili maddeler bir üstteki görevin alt görevleridir.
+func decodeMarkdown(content string, wf Workflow) ([]Task, []PlainTextError) {
+	var tasks []Task
+	var errs []PlainTextError
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		raw := strings.ReplaceAll(scanner.Text(), "\t", "    ")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+			// "Yapılacaklar (Todo)" -> önce parantez içindeki anahtar denenir
+			if open := strings.LastIndex(heading, " ("); open >= 0 && strings.HasSuffix(heading, ")") {
+				if key, ok := workflowStatusKey(wf, heading[open+2:len(heading)-1]); ok {
+					section = key
+					continue
+				}
+			}
+			if key, ok := workflowStatusKey(wf, heading); ok {
+				section = key
+			}
+			continue
+		}
+
+		done, text, ok := parseCheckbox(line)
+		if !ok {
+			continue // düz metin satırları yok sayılır
+		}
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if indent >= 2 {
+			if len(tasks) == 0 {
+				errs = append(errs, PlainTextError{Line: n, Message: "alt görevin üstünde görev yok"})
+				continue
+			}
+			parent := &tasks[len(tasks)-1]
+			parent.Subtasks = append(parent.Subtasks, Subtask{Title: text, IsCompleted: done, Position: len(parent.Subtasks)})
+			continue
+		}
+
+		var title []string
+		var priority, due string
+		for _, f := range strings.Fields(text) {
+			key, value, ok := strings.Cut(f, ":")
+			switch {
+			case ok && key == "due" && value != "":
+				due = value
+			case ok && key == "priority" && value != "":
+				priority = value
+			default:
+				title = append(title, f)
+			}
+		}
+		task := Task{Title: strings.Join(title, " "), Position: len(tasks)}
+		if task.Title == "" {
+			errs = append(errs, PlainTextError{Line: n, Message: "başlık boş"})
+			continue
+		}
+
+		if p, ok := normalizePriority(priority); ok {
+			task.Priority = p
+		} else {
+			errs = append(errs, PlainTextError{Line: n, Message: fmt.Sprintf("geçersiz öncelik %q", priority)})
+			continue
+		}
+		if due != "" {
+			normalized, ok := normalizeDate(due)
+			if !ok {
+				errs = append(errs, PlainTextError{Line: n, Message: fmt.Sprintf("geçersiz tarih %q", due)})
+				continue
+			}
+			task.DueDate = &normalized
+		}
+
+		status := section
+		if status == "" || (done && wf.Category(status) != CategoryDone) {
+			status, _ = statusFor(wf, "", done)
+		}
+		task.Status = status
+		tasks = append(tasks, task)
+	}
+	return tasks, errs
+}
+
+// parseCheckbox "- [ ] metin", "* [x] metin" veya "1. [ ] metin" satırlarını çözer.
+func parseCheckbox(line string) (bool, string, bool) {
+	switch {
+	case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "), strings.HasPrefix(line, "+ "):
+		line = line[2:]
+	default:
+		dot := strings.Index(line, ". ")
+		if dot <= 0 {
+			return false, "", false
+		}
+		if _, err := strconv.Atoi(line[:dot]); err != nil {
+			return false, "", false
+		}
+		line = line[dot+2:]
+	}
+	if len(line) < 4 || line[0] != '[' || line[2] != ']' || line[3] != ' ' {
+		return false, "", false
+	}
+	switch line[1] {
+	case ' ':
+		return false, strings.TrimSpace(line[4:]), true
+	case 'x', 'X':
+		return true, strings.TrimSpace(line[4:]), true
+	}
+	return false, "", false
+}
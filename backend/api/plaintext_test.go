@@ -0,0 +1,172 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testWorkflow boşluklu sütun anahtarları da içeren bir pano akışı
+func testWorkflow() Workflow {
+	return Workflow{Statuses: []BoardStatus{
+		{Key: "Todo", Title: "Yapılacaklar", Order: 0, Category: CategoryTodo},
+		{Key: "In Review", Title: "Kod İnceleme", Order: 1, Category: CategoryInProgress},
+		{Key: "waiting_on_customer", Title: "Müşteri Bekleniyor", Order: 2, Category: CategoryInProgress},
+		{Key: "Done", Title: "Done", Order: 3, Category: CategoryDone},
+	}}
+}
+
+func strPtr(s string) *string { return &s }
+
+// testTasks alt görevli, öncelikli, bitiş tarihli ve farklı sütunlardaki görevler
+func testTasks(boardID string) []Task {
+	return []Task{
+		{
+			Title: "Sunumu hazırla", Status: "Todo", Priority: PriorityHigh, DueDate: strPtr("2026-03-01"),
+			Position: 0, BoardID: boardID,
+			Subtasks: []Subtask{
+				{Title: "Slaytlar", IsCompleted: true, Position: 0},
+				{Title: "Prova @ofis", IsCompleted: false, Position: 1},
+			},
+		},
+		{Title: "API dokümanı", Status: "In Review", Priority: PriorityMedium, Position: 1, BoardID: boardID},
+		{Title: "Fatura onayı +finans", Status: "waiting_on_customer", Priority: PriorityLow, Position: 2, BoardID: boardID},
+		{Title: "Sürüm notları", Status: "Done", DueDate: strPtr("2026-02-14"), Position: 3, BoardID: boardID},
+		{Title: "Öncelik yok", Status: "Todo", Position: 4, BoardID: boardID},
+	}
+}
+
+// plainView karşılaştırmada kullanılan, düz metinde taşınan alanlar
+type plainView struct {
+	Title    string
+	Status   string
+	Priority string
+	DueDate  string
+	Subtasks []Subtask
+}
+
+func viewOf(tasks []Task) []plainView {
+	views := make([]plainView, 0, len(tasks))
+	for _, t := range tasks {
+		var subtasks []Subtask
+		for i, st := range t.Subtasks {
+			subtasks = append(subtasks, Subtask{Title: st.Title, IsCompleted: st.IsCompleted, Position: i})
+		}
+		views = append(views, plainView{
+			Title:    t.Title,
+			Status:   t.Status,
+			Priority: t.Priority,
+			DueDate:  stringValue(t.DueDate),
+			Subtasks: subtasks,
+		})
+	}
+	return views
+}
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	board := plainBoard{ID: "b1", Title: "Pazarlama Planı", Workflow: testWorkflow()}
+	boards := map[string]plainBoard{board.ID: board}
+	tasks := testTasks(board.ID)
+
+	encoded := encodeTodoTxt(tasks, boards)
+	for _, want := range []string{"(A) Sunumu hazırla", "(B) API dokümanı", "(C) Fatura onayı", "+Pazarlama_Planı", "due:2026-03-01", "status:In_Review", "x Sürüm notları"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("çıktıda %q yok:\n%s", want, encoded)
+		}
+	}
+
+	decoded, errs := decodeTodoTxt(encoded, board.Workflow, board.Title)
+	if len(errs) > 0 {
+		t.Fatalf("beklenmeyen hatalar: %+v\n%s", errs, encoded)
+	}
+	if got, want := viewOf(decoded), viewOf(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("todo.txt gidiş-dönüş farklı\n got: %+v\nwant: %+v\n%s", got, want, encoded)
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	board := plainBoard{ID: "b1", Title: "Pazarlama Planı", Workflow: testWorkflow()}
+	boards := map[string]plainBoard{board.ID: board}
+	tasks := testTasks(board.ID)
+
+	encoded := encodeMarkdown(board.Title, tasks, boards)
+	for _, want := range []string{"## Kod İnceleme (In Review)", "## Done\n", "- [x] Sürüm notları", "  - [x] Slaytlar", "priority:High", "due:2026-03-01"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("çıktıda %q yok:\n%s", want, encoded)
+		}
+	}
+
+	decoded, errs := decodeMarkdown(encoded, board.Workflow)
+	if len(errs) > 0 {
+		t.Fatalf("beklenmeyen hatalar: %+v\n%s", errs, encoded)
+	}
+	if got, want := viewOf(decoded), viewOf(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("Markdown gidiş-dönüş farklı\n got: %+v\nwant: %+v\n%s", got, want, encoded)
+	}
+}
+
+// mineBoards mine=true dışa aktarımı gibi iki panodan görevler
+func mineBoards() (map[string]plainBoard, []Task) {
+	boards := map[string]plainBoard{
+		"a": {ID: "a", Title: "Alfa Projesi", Workflow: testWorkflow()},
+		"b": {ID: "b", Title: "Beta", Workflow: testWorkflow()},
+	}
+	tasks := []Task{
+		{Title: "Beta görevi", Status: "Done", Priority: PriorityLow, Position: 0, BoardID: "b",
+			Subtasks: []Subtask{{Title: "Beta alt", IsCompleted: true}}},
+		{Title: "Alfa görevi", Status: "In Review", Priority: PriorityHigh, DueDate: strPtr("2026-05-05"), Position: 0, BoardID: "a",
+			Subtasks: []Subtask{{Title: "Alfa alt", Position: 0}, {Title: "Alfa alt 2", IsCompleted: true, Position: 1}}},
+		{Title: "Alfa ikinci", Status: "Todo", Position: 1, BoardID: "a"},
+	}
+	return boards, tasks
+}
+
+func TestTodoTxtRoundTripMultiBoard(t *testing.T) {
+	boards, tasks := mineBoards()
+	encoded := encodeTodoTxt(tasks, boards)
+
+	// Her pano kendi +etiketiyle ayrılıp kendi panosuna geri aktarıldığında aynı görevleri üretir
+	for _, board := range boards {
+		tag := projectTag(board.Title)
+		var lines []string
+		for _, line := range strings.Split(encoded, "\n") {
+			if strings.Contains(line, tag) {
+				lines = append(lines, line)
+			}
+		}
+
+		var want []Task
+		for _, task := range tasks {
+			if task.BoardID == board.ID {
+				want = append(want, task)
+			}
+		}
+
+		decoded, errs := decodeTodoTxt(strings.Join(lines, "\n"), board.Workflow, board.Title)
+		if len(errs) > 0 {
+			t.Fatalf("%s: beklenmeyen hatalar: %+v\n%s", board.Title, errs, encoded)
+		}
+		if got := viewOf(decoded); !reflect.DeepEqual(got, viewOf(want)) {
+			t.Errorf("%s: gidiş-dönüş farklı\n got: %+v\nwant: %+v\n%s", board.Title, got, viewOf(want), encoded)
+		}
+	}
+}
+
+func TestMarkdownRoundTripMultiBoard(t *testing.T) {
+	boards, tasks := mineBoards()
+	encoded := encodeMarkdown("Bana atananlar", tasks, boards)
+	for _, want := range []string{"## Alfa Projesi", "## Beta", "### Kod İnceleme (In Review)"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("çıktıda %q yok:\n%s", want, encoded)
+		}
+	}
+
+	// Pano başlıkları sütun adı olmadığı için atlanır; sütunlar ve alt görevler korunur
+	decoded, errs := decodeMarkdown(encoded, testWorkflow())
+	if len(errs) > 0 {
+		t.Fatalf("beklenmeyen hatalar: %+v\n%s", errs, encoded)
+	}
+	if got, want := viewOf(decoded), viewOf(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("Markdown gidiş-dönüş farklı\n got: %+v\nwant: %+v\n%s", got, want, encoded)
+	}
+}
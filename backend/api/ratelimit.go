@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter anahtar başına sabit pencereli sayaç tutar (tek instance, bellek içi).
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*rateEntry
+}
+
+type rateEntry struct {
+	count int
+	start time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		entries: make(map[string]*rateEntry),
+	}
+}
+
+// Blocked anahtarın bu pencerede limiti aşıp aşmadığını döndürür; kalan bekleme süresini de verir.
+func (l *rateLimiter) Blocked(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return false, 0
+	}
+	elapsed := time.Since(e.start)
+	if elapsed >= l.window {
+		delete(l.entries, key)
+		return false, 0
+	}
+	if e.count >= l.limit {
+		return true, l.window - elapsed
+	}
+	return false, 0
+}
+
+// Hit anahtar için bir olay kaydeder ve limitin aşılıp aşılmadığını döndürür.
+func (l *rateLimiter) Hit(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	e, ok := l.entries[key]
+	if !ok || now.Sub(e.start) >= l.window {
+		e = &rateEntry{start: now}
+		l.entries[key] = e
+	}
+	e.count++
+	return e.count > l.limit
+}
+
+// Reset başarılı bir işlemden sonra anahtarın sayacını sıfırlar.
+func (l *rateLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// cleanup harita büyümesin diye süresi dolan kayıtları siler (kilit altında çağrılır).
+func (l *rateLimiter) cleanup(now time.Time) {
+	if len(l.entries) < 1024 {
+		return
+	}
+	for k, e := range l.entries {
+		if now.Sub(e.start) >= l.window {
+			delete(l.entries, k)
+		}
+	}
+}
+
+// clientIP isteğin IP adresini döndürür (port olmadan).
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,346 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-panel/backend/rrule"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TaskRecurrence tekrarlayan görev serisi. NextAt henüz oluşturulmamış bir sonraki tekrarın zamanıdır;
+// kural bittiğinde veya seri durdurulduğunda boştur.
+type TaskRecurrence struct {
+	ID            string  `json:"id,omitempty"`
+	BoardID       string  `json:"board_id,omitempty"`
+	Rule          string  `json:"rule"`
+	Timezone      string  `json:"timezone"`
+	DTStart       string  `json:"dtstart,omitempty"`
+	NextAt        *string `json:"next_at"`
+	CurrentTaskID *string `json:"current_task_id,omitempty"`
+	InstanceCount int     `json:"instance_count,omitempty"`
+	SkippedCount  int     `json:"skipped_count,omitempty"`
+	CreatedBy     string  `json:"created_by,omitempty"`
+	StoppedAt     *string `json:"stopped_at,omitempty"`
+}
+
+// errRecurrenceNotFound görev bir seriye ait değil.
+var errRecurrenceNotFound = errors.New("görev tekrarlayan bir seriye ait değil")
+
+// recurrenceBatchSize üreticinin bir turda işlediği en fazla seri
+const recurrenceBatchSize = 100
+
+// schedule serinin kuralını DTSTART ve saat dilimiyle birlikte yükler.
+func (rec *TaskRecurrence) schedule() (*rrule.Schedule, error) {
+	start, err := time.Parse(time.RFC3339, rec.DTStart)
+	if err != nil {
+		return nil, fmt.Errorf("geçersiz dtstart: %w", err)
+	}
+	return rrule.New(rec.Rule, rec.Timezone, start)
+}
+
+// active seri yeni tekrar üretebilir mi
+func (rec *TaskRecurrence) active() bool {
+	return rec.StoppedAt == nil && rec.NextAt != nil
+}
+
+// formatTimestamp zamanı PostgREST'e yazılacak biçime çevirir.
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// loadRecurrence seriyi ID'siyle yükler.
+func loadRecurrence(token, recurrenceID string) (*TaskRecurrence, error) {
+	resp, err := performSupabaseRequest("GET", fmt.Sprintf("task_recurrences?id=eq.%s&select=*", recurrenceID), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	var list []TaskRecurrence
+	if err := json.Unmarshal(resp, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, errRecurrenceNotFound
+	}
+	return &list[0], nil
+}
+
+// recurrenceTemplate bir sonraki tekrarın kopyalanacağı görev: serinin son örneği, silinmişse
+// en geç tarihli örnek. Alt görevler ve etiket ID'leriyle yüklenir.
+func recurrenceTemplate(token string, rec *TaskRecurrence) (*Task, error) {
+	const columns = "select=*,subtasks(*),labels:board_labels(id)"
+	endpoints := []string{}
+	if rec.CurrentTaskID != nil {
+		endpoints = append(endpoints, fmt.Sprintf("tasks?id=eq.%s&%s", *rec.CurrentTaskID, columns))
+	}
+	endpoints = append(endpoints, fmt.Sprintf("tasks?recurrence_id=eq.%s&%s&order=occurrence_at.desc.nullslast&limit=1", rec.ID, columns))
+
+	for _, endpoint := range endpoints {
+		resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+		if err != nil {
+			return nil, err
+		}
+		var tasks []Task
+		if err := json.Unmarshal(resp, &tasks); err != nil {
+			return nil, err
+		}
+		if len(tasks) > 0 {
+			return &tasks[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// generateNextInstance serinin next_at tekrarı için yeni görev oluşturur: başlık, açıklama, öncelik,
+// atanan kişi ve etiketler son örnekten kopyalanır, alt görevler tamamlanmamış olarak sıfırlanır,
+// görev panonun ilk sütununa girer ve bitiş tarihi tekrarın (serinin saat dilimindeki) günüdür.
+// Aynı tekrar iki kez oluşturulamaz (tasks_recurrence_occurrence_key); yarışı kaybeden taraf
+// mevcut örneği kullanır. Seri yoksa veya durmuşsa nil döner.
+func generateNextInstance(token string, rec *TaskRecurrence) (*Task, error) {
+	if !rec.active() {
+		return nil, nil
+	}
+	schedule, err := rec.schedule()
+	if err != nil {
+		return nil, err
+	}
+	occurrence, err := time.Parse(time.RFC3339, *rec.NextAt)
+	if err != nil {
+		return nil, fmt.Errorf("geçersiz next_at: %w", err)
+	}
+	occurrence = occurrence.In(schedule.Start.Location())
+
+	template, err := recurrenceTemplate(token, rec)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		// Serinin bütün görevleri silinmiş; kopyalanacak bir şey kalmadı
+		_, err := performSupabaseRequest("PATCH", fmt.Sprintf("task_recurrences?id=eq.%s", rec.ID), token,
+			map[string]interface{}{"stopped_at": formatTimestamp(time.Now()), "next_at": nil})
+		return nil, err
+	}
+
+	workflow, err := boardWorkflow(token, rec.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	due := occurrence.Format("2006-01-02")
+	occurrenceAt := formatTimestamp(occurrence)
+	task := Task{
+		Title:        template.Title,
+		Description:  template.Description,
+		Status:       firstStatus(workflow),
+		Priority:     template.Priority,
+		DueDate:      &due,
+		UserID:       rec.CreatedBy,
+		BoardID:      rec.BoardID,
+		AssignedTo:   template.AssignedTo,
+		RecurrenceID: &rec.ID,
+		OccurrenceAt: &occurrenceAt,
+	}
+
+	var created *Task
+	resp, err := performSupabaseRequest("POST", "tasks", token, task)
+	switch {
+	case supabaseStatus(err) == http.StatusConflict:
+		// Tamamlama ve zamanlayıcı aynı anda üretti; diğerinin oluşturduğu örnek kullanılır
+		endpoint := fmt.Sprintf("tasks?recurrence_id=eq.%s&occurrence_at=eq.%s&select=*", rec.ID, url.QueryEscape(occurrenceAt))
+		if resp, err = performSupabaseRequest("GET", endpoint, token, nil); err != nil {
+			return nil, err
+		}
+		var existing []Task
+		if err := json.Unmarshal(resp, &existing); err != nil || len(existing) == 0 {
+			return nil, fmt.Errorf("tekrar zaten oluşturulmuş ama okunamadı")
+		}
+		created = &existing[0]
+	case err != nil:
+		return nil, err
+	default:
+		var inserted []Task
+		if err := json.Unmarshal(resp, &inserted); err != nil || len(inserted) == 0 {
+			return nil, fmt.Errorf("oluşturulan görev okunamadı")
+		}
+		created = &inserted[0]
+
+		if len(template.Subtasks) > 0 {
+			subtasks := make([]Subtask, 0, len(template.Subtasks))
+			for _, s := range template.Subtasks {
+				subtasks = append(subtasks, Subtask{TaskID: created.ID, Title: s.Title, Position: s.Position})
+			}
+			if _, err := performSupabaseRequest("POST", "subtasks", token, subtasks); err != nil {
+				fmt.Println("Tekrar Alt Görev Hatası:", err)
+			}
+		}
+		if len(template.Labels) > 0 {
+			links := make([]map[string]string, 0, len(template.Labels))
+			for _, l := range template.Labels {
+				links = append(links, map[string]string{"task_id": created.ID, "label_id": l.ID})
+			}
+			if _, err := performSupabaseRequest("POST", "task_labels", token, links); err != nil {
+				fmt.Println("Tekrar Etiket Hatası:", err)
+			}
+		}
+	}
+
+	// Seriyi ilerlet; next_at koşulu iki üreticinin seriyi iki kez ilerletmesini engeller
+	update := map[string]interface{}{
+		"current_task_id": created.ID,
+		"next_at":         nil,
+		"instance_count":  rec.InstanceCount + 1,
+		"updated_at":      formatTimestamp(time.Now()),
+	}
+	if next, ok := schedule.Next(occurrence); ok {
+		update["next_at"] = formatTimestamp(next)
+	}
+	endpoint := fmt.Sprintf("task_recurrences?id=eq.%s&next_at=eq.%s", rec.ID, url.QueryEscape(*rec.NextAt))
+	if _, err := performSupabaseRequest("PATCH", endpoint, token, update); err != nil {
+		return nil, err
+	}
+
+	GlobalHub.Publish(rec.BoardID, "recurring_task_created", map[string]interface{}{
+		"task":          created,
+		"recurrence_id": rec.ID,
+		"occurrence_at": occurrenceAt,
+		"next_at":       update["next_at"],
+	})
+	return created, nil
+}
+
+// completeRecurringTasks tamamlanma sütununa taşınan görevlerden serisinin son örneği olanlar için
+// bir sonraki tekrarı hemen oluşturur. Hatalar isteği etkilemez, sadece loglanır.
+func completeRecurringTasks(token string, taskIDs []string) {
+	if len(taskIDs) == 0 {
+		return
+	}
+	endpoint := fmt.Sprintf("tasks?id=in.(%s)&recurrence_id=not.is.null&select=id,recurrence_id", strings.Join(taskIDs, ","))
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		log.Printf("Tekrar Kontrol Hatası: %v", err)
+		return
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		return
+	}
+	for _, t := range tasks {
+		rec, err := loadRecurrence(token, *t.RecurrenceID)
+		if err != nil {
+			log.Printf("Tekrar Yükleme Hatası (%s): %v", *t.RecurrenceID, err)
+			continue
+		}
+		if rec.CurrentTaskID == nil || *rec.CurrentTaskID != t.ID {
+			continue // eski bir örnek tamamlandı; sonraki zaten oluşturulmuş
+		}
+		if _, err := generateNextInstance(token, rec); err != nil {
+			log.Printf("Tekrar Oluşturma Hatası (%s): %v", rec.ID, err)
+		}
+	}
+}
+
+// catchUpRecurrence sunucu kapalıyken geride kalan seride kaçırılan tekrarları atlar: next_at, now'a
+// kadar olan son tekrara ilerletilir ve aradakiler skipped_count'a eklenir. Böylece her turda eski bir
+// tekrar oluşturulmaz, sadece en son kaçırılan oluşturulur. Seri bu arada başka yerden ilerlediyse
+// false döner.
+func catchUpRecurrence(token string, rec *TaskRecurrence, now time.Time) (bool, error) {
+	schedule, err := rec.schedule()
+	if err != nil {
+		return false, err
+	}
+	occurrence, err := time.Parse(time.RFC3339, *rec.NextAt)
+	if err != nil {
+		return false, fmt.Errorf("geçersiz next_at: %w", err)
+	}
+	missed := schedule.Between(occurrence, now)
+	if len(missed) == 0 {
+		return true, nil
+	}
+
+	latest := formatTimestamp(missed[len(missed)-1])
+	skipped := append([]time.Time{occurrence}, missed[:len(missed)-1]...)
+	endpoint := fmt.Sprintf("task_recurrences?id=eq.%s&next_at=eq.%s", rec.ID, url.QueryEscape(*rec.NextAt))
+	resp, err := performSupabaseRequest("PATCH", endpoint, token, map[string]interface{}{
+		"next_at":       latest,
+		"skipped_count": rec.SkippedCount + len(skipped),
+		"updated_at":    formatTimestamp(time.Now()),
+	})
+	if err != nil {
+		return false, err
+	}
+	var saved []TaskRecurrence
+	if err := json.Unmarshal(resp, &saved); err != nil {
+		return false, err
+	}
+	if len(saved) == 0 {
+		return false, nil
+	}
+
+	GlobalHub.Publish(rec.BoardID, "recurrence_skipped", map[string]interface{}{
+		"recurrence_id": rec.ID,
+		"skipped":       skipped,
+		"next_at":       latest,
+		"missed":        true,
+	})
+	rec.NextAt, rec.SkippedCount = saved[0].NextAt, saved[0].SkippedCount
+	return true, nil
+}
+
+// runDueRecurrences zamanı gelmiş (next_at <= now) serilerin bir sonraki örneğini oluşturur.
+// Arşivlenmiş panolar atlanır. Kesintiden sonra geride kalan serilerde sadece en son kaçırılan
+// tekrar oluşturulur, öncekiler atlanmış sayılır (catchUpRecurrence).
+func runDueRecurrences(token string, now time.Time) {
+	endpoint := fmt.Sprintf("task_recurrences?select=*,boards!inner(archived_at)&boards.archived_at=is.null&stopped_at=is.null&next_at=lte.%s&order=next_at.asc&limit=%d",
+		url.QueryEscape(formatTimestamp(now)), recurrenceBatchSize)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		log.Printf("Tekrar Üretici Hatası: %v", err)
+		return
+	}
+	var due []TaskRecurrence
+	if err := json.Unmarshal(resp, &due); err != nil {
+		log.Printf("Tekrar Üretici Hatası: %v", err)
+		return
+	}
+	for i := range due {
+		if ok, err := catchUpRecurrence(token, &due[i], now); err != nil || !ok {
+			if err != nil {
+				log.Printf("Tekrar Atlama Hatası (%s): %v", due[i].ID, err)
+			}
+			continue
+		}
+		if _, err := generateNextInstance(token, &due[i]); err != nil {
+			log.Printf("Tekrar Oluşturma Hatası (%s): %v", due[i].ID, err)
+		}
+	}
+}
+
+// StartRecurrenceWorker zamanı gelen tekrarları arka planda oluşturan üreticiyi başlatır.
+// Üretici hiçbir kullanıcı adına çalışmadığı için SUPABASE_SERVICE_ROLE_KEY gerekir; yoksa sadece
+// tamamlanan görevler sonraki tekrarı oluşturur. Aralık RECURRENCE_INTERVAL ile değiştirilebilir (varsayılan 1m).
+// Sunucusuz (Vercel) dağıtımda çalışmaz; orada sadece sunucu (main.go) modu zamanlamayı yapar.
+func StartRecurrenceWorker() {
+	serviceKey := os.Getenv("SUPABASE_SERVICE_ROLE_KEY")
+	if serviceKey == "" {
+		log.Println("SUPABASE_SERVICE_ROLE_KEY tanımlı değil, tekrarlayan görev üretici çalışmıyor")
+		return
+	}
+	interval := time.Minute
+	if v, err := time.ParseDuration(os.Getenv("RECURRENCE_INTERVAL")); err == nil && v >= time.Second {
+		interval = v
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runDueRecurrences(serviceKey, time.Now())
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+)
+
+// revalidateInterval determines how often open connections re-check the caller's credential
+// and board membership, so members removed elsewhere (or disabled accounts) are dropped.
+const revalidateInterval = 2 * time.Minute
+
+// Session describes one active realtime connection.
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	BoardID     string    `json:"board_id"`
+	Role        string    `json:"role"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// sessionRegistry indexes connected clients by session ID, user and board.
+type sessionRegistry struct {
+	mu      sync.RWMutex
+	byID    map[string]*Client
+	byUser  map[string]map[string]*Client
+	byBoard map[string]map[string]*Client
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{
+		byID:    make(map[string]*Client),
+		byUser:  make(map[string]map[string]*Client),
+		byBoard: make(map[string]map[string]*Client),
+	}
+}
+
+func newSessionID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func (s *sessionRegistry) add(c *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.byID[c.SessionID] = c
+	if s.byUser[c.UserID] == nil {
+		s.byUser[c.UserID] = make(map[string]*Client)
+	}
+	s.byUser[c.UserID][c.SessionID] = c
+	if s.byBoard[c.Room.BoardID] == nil {
+		s.byBoard[c.Room.BoardID] = make(map[string]*Client)
+	}
+	s.byBoard[c.Room.BoardID][c.SessionID] = c
+}
+
+func (s *sessionRegistry) remove(c *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.byID, c.SessionID)
+	if m := s.byUser[c.UserID]; m != nil {
+		delete(m, c.SessionID)
+		if len(m) == 0 {
+			delete(s.byUser, c.UserID)
+		}
+	}
+	if m := s.byBoard[c.Room.BoardID]; m != nil {
+		delete(m, c.SessionID)
+		if len(m) == 0 {
+			delete(s.byBoard, c.Room.BoardID)
+		}
+	}
+}
+
+// collect returns the clients matching the filter under the read lock.
+func (s *sessionRegistry) collect(boardID, userID, sessionID string) []*Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var clients []*Client
+	for id, c := range s.byBoard[boardID] {
+		if sessionID != "" && id != sessionID {
+			continue
+		}
+		if userID != "" && c.UserID != userID {
+			continue
+		}
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// BoardSessions lists active realtime sessions of a board.
+func (h *Hub) BoardSessions(boardID string) []Session {
+	clients := h.sessions.collect(boardID, "", "")
+	sessions := make([]Session, 0, len(clients))
+	for _, c := range clients {
+		sessions = append(sessions, c.Session())
+	}
+	return sessions
+}
+
+// UserSessions lists every active session of a user across boards.
+func (h *Hub) UserSessions(userID string) []Session {
+	h.sessions.mu.RLock()
+	defer h.sessions.mu.RUnlock()
+
+	sessions := make([]Session, 0, len(h.sessions.byUser[userID]))
+	for _, c := range h.sessions.byUser[userID] {
+		sessions = append(sessions, c.Session())
+	}
+	return sessions
+}
+
+// NotifyUser pushes an event to every active session of a user, whichever board they have open.
+// Users without a connection miss the event. Returns the number of sessions notified.
+func (h *Hub) NotifyUser(userID, boardID, eventType string, data interface{}) int {
+	h.sessions.mu.RLock()
+	clients := make([]*Client, 0, len(h.sessions.byUser[userID]))
+	for _, c := range h.sessions.byUser[userID] {
+		clients = append(clients, c)
+	}
+	h.sessions.mu.RUnlock()
+
+	for _, c := range clients {
+		msg := &Message{
+			Type:      eventType,
+			BoardID:   boardID,
+			Timestamp: time.Now().UnixMilli(),
+			Data:      data,
+		}
+		// Delivered through the client's room loop, which owns the Send channel
+		go func(c *Client) { c.Room.direct <- directMessage{client: c, message: msg} }(c)
+	}
+	return len(clients)
+}
+
+// DisconnectBoard force-closes matching sessions on a board.
+// Empty userID / sessionID act as wildcards. Returns the number of closed sessions.
+func (h *Hub) DisconnectBoard(boardID, userID, sessionID, reason string) int {
+	clients := h.sessions.collect(boardID, userID, sessionID)
+	for _, c := range clients {
+		c.kick(closeForbidden, reason)
+	}
+	return len(clients)
+}
+
+// DisconnectUser force-closes all sessions of a user (e.g. disabled account).
+func (h *Hub) DisconnectUser(userID, reason string) int {
+	h.sessions.mu.RLock()
+	clients := make([]*Client, 0, len(h.sessions.byUser[userID]))
+	for _, c := range h.sessions.byUser[userID] {
+		clients = append(clients, c)
+	}
+	h.sessions.mu.RUnlock()
+
+	for _, c := range clients {
+		c.kick(closeForbidden, reason)
+	}
+	return len(clients)
+}
+
+// Session returns the public view of the client.
+func (c *Client) Session() Session {
+	return Session{
+		ID:          c.SessionID,
+		UserID:      c.UserID,
+		Email:       c.Email,
+		BoardID:     c.Room.BoardID,
+		Role:        c.role(),
+		RemoteAddr:  c.RemoteAddr,
+		ConnectedAt: c.ConnectedAt,
+	}
+}
+
+// kick sends a close frame and closes the connection; ReadPump then unregisters the client.
+func (c *Client) kick(code int, reason string) {
+	closeWithReason(c.Conn, code, reason)
+	c.Conn.Close()
+}
+
+// revalidate re-authenticates the stored credential and reloads the board role.
+// Removed members, revoked tokens and disabled accounts are disconnected.
+func (c *Client) revalidate() {
+	c.mu.Lock()
+	credential := c.credential
+	c.mu.Unlock()
+
+	principal, err := authenticate(context.Background(), credential)
+	if err != nil || principal.UserID != c.UserID {
+		c.kick(closeUnauthorized, "session revoked")
+		return
+	}
+
+	role, err := chatRole(principal.Token, c.Room.BoardID, c.UserID)
+	if err != nil {
+		// Transient errors should not drop the connection
+		log.Printf("Session revalidation failed: %v", err)
+		return
+	}
+	if !RoleAllows(role, PermRead) {
+		c.kick(closeForbidden, "membership revoked")
+		return
+	}
+
+	c.mu.Lock()
+	c.Role = role
+	c.mu.Unlock()
+}
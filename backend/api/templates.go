@@ -0,0 +1,287 @@
+package api
+
+// Status kategorileri: panolar kendi sütunlarını tanımlasa da raporlama ve kurallar kategoriye bakar.
+const (
+	CategoryTodo       = "todo"
+	CategoryInProgress = "in_progress"
+	CategoryDone       = "done"
+)
+
+// BoardStatus panonun bir sütunu (tasks.status değeri Key ile eşleşir)
+type BoardStatus struct {
+	Key      string `json:"key"`
+	Title    string `json:"title"`
+	Color    string `json:"color,omitempty"`
+	Order    int    `json:"order"`
+	Category string `json:"category"` // todo, in_progress, done
+
+	// WIP limitleri (0 = sınırsız): sütundaki toplam ve kişi başı görev sayısı
+	WIPLimit         int `json:"wip_limit,omitempty"`
+	AssigneeWIPLimit int `json:"assignee_wip_limit,omitempty"`
+}
+
+// Workflow panonun sütun tanımları (boards.workflow).
+// Transitions boşsa her sütundan her sütuna geçiş serbesttir; tanımlıysa bir sütun için
+// listelenmemiş hedeflere geçiş reddedilir (listede olmayan sütunlar serbest kalır).
+type Workflow struct {
+	Statuses    []BoardStatus       `json:"statuses"`
+	Transitions map[string][]string `json:"transitions,omitempty"` // kaynak sütun -> izin verilen hedefler
+}
+
+// BoardSettings panonun varsayılan ayarları (boards.settings)
+type BoardSettings struct {
+	DefaultView     string   `json:"default_view,omitempty"` // board, list
+	Priorities      []string `json:"priorities,omitempty"`
+	DefaultAssignee string   `json:"default_assignee,omitempty"` // yeni görevlere atanan kullanıcı (pano üyesi)
+	Timezone        string   `json:"timezone,omitempty"`         // IANA, ör. Europe/Istanbul
+}
+
+// boardViews panoda seçilebilecek görünümler
+var boardViews = []string{"board", "list"}
+
+// TemplateLabel şablonla gelen varsayılan etiket
+type TemplateLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TemplateTask şablonla gelen örnek görev
+type TemplateTask struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"`
+	Priority    string   `json:"priority,omitempty"`
+	Subtasks    []string `json:"subtasks,omitempty"`
+}
+
+// TemplateDefinition CreateBoard'un uyguladığı pano içeriği
+type TemplateDefinition struct {
+	Workflow Workflow        `json:"workflow"`
+	Settings BoardSettings   `json:"settings"`
+	Labels   []TemplateLabel `json:"labels,omitempty"`
+	Tasks    []TemplateTask  `json:"tasks,omitempty"`
+}
+
+// BoardTemplate hazır veya kullanıcının kaydettiği şablon
+type BoardTemplate struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Custom      bool               `json:"custom"`
+	Definition  TemplateDefinition `json:"definition"`
+}
+
+var defaultPriorities = []string{"Low", "Medium", "High"}
+
+// builtinTemplates Board.Type değerleri (standard, professional, smart, minimal)
+var builtinTemplates = []BoardTemplate{
+	{
+		ID:          "standard",
+		Name:        "Standart",
+		Description: "Yapılacak, Yapılıyor, Tamamlandı",
+		Definition: TemplateDefinition{
+			Workflow: Workflow{Statuses: []BoardStatus{
+				{Key: "Todo", Title: "Yapılacaklar", Color: "bg-orange-500", Order: 0, Category: CategoryTodo},
+				{Key: "Doing", Title: "Yapılıyor", Color: "bg-blue-500", Order: 1, Category: CategoryInProgress},
+				{Key: "Done", Title: "Tamamlandı", Color: "bg-green-500", Order: 2, Category: CategoryDone},
+			}},
+			Settings: BoardSettings{DefaultView: "board", Priorities: defaultPriorities},
+		},
+	},
+	{
+		ID:          "professional",
+		Name:        "Profesyonel",
+		Description: "Backlog, Planlanan, Yapılıyor, Tamamlandı",
+		Definition: TemplateDefinition{
+			Workflow: Workflow{Statuses: []BoardStatus{
+				{Key: "Backlog", Title: "Backlog", Color: "bg-gray-500", Order: 0, Category: CategoryTodo},
+				{Key: "Todo", Title: "Yapılacak", Color: "bg-orange-500", Order: 1, Category: CategoryTodo},
+				{Key: "Doing", Title: "Yapılıyor", Color: "bg-blue-500", Order: 2, Category: CategoryInProgress},
+				{Key: "Done", Title: "Tamamlandı", Color: "bg-green-500", Order: 3, Category: CategoryDone},
+			}},
+			Settings: BoardSettings{DefaultView: "board", Priorities: defaultPriorities},
+			Labels: []TemplateLabel{
+				{Name: "bug", Color: "#ef4444"},
+				{Name: "feature", Color: "#3b82f6"},
+				{Name: "improvement", Color: "#22c55e"},
+			},
+			Tasks: []TemplateTask{
+				{
+					Title:    "Sprint hedeflerini belirle",
+					Status:   "Todo",
+					Priority: "High",
+					Subtasks: []string{"Backlog'u gözden geçir", "Kapasiteyi hesapla", "Hedefleri ekiple paylaş"},
+				},
+				{
+					Title:    "Backlog'u önceliklendir",
+					Status:   "Backlog",
+					Priority: "Medium",
+				},
+			},
+		},
+	},
+	{
+		ID:          "smart",
+		Name:        "Akıllı Set",
+		Description: "Fikir, Yapılacak, Kontrol, Beklemede, Tamamlandı",
+		Definition: TemplateDefinition{
+			Workflow: Workflow{Statuses: []BoardStatus{
+				{Key: "Idea", Title: "Fikir / Notlar", Color: "bg-purple-500", Order: 0, Category: CategoryTodo},
+				{Key: "Todo", Title: "Yapılacak", Color: "bg-orange-500", Order: 1, Category: CategoryTodo},
+				{Key: "Doing", Title: "Yapılıyor", Color: "bg-blue-500", Order: 2, Category: CategoryInProgress},
+				{Key: "Review", Title: "Kontrol / Beklemede", Color: "bg-yellow-500", Order: 3, Category: CategoryInProgress},
+				{Key: "Done", Title: "Tamamlandı", Color: "bg-green-500", Order: 4, Category: CategoryDone},
+			}},
+			Settings: BoardSettings{DefaultView: "board", Priorities: defaultPriorities},
+			Labels: []TemplateLabel{
+				{Name: "fikir", Color: "#a855f7"},
+				{Name: "acil", Color: "#ef4444"},
+			},
+			Tasks: []TemplateTask{
+				{
+					Title:    "İlk fikrini not al",
+					Status:   "Idea",
+					Priority: "Low",
+					Subtasks: []string{"Fikri kısaca tanımla", "Yapılacaklara taşı"},
+				},
+			},
+		},
+	},
+	{
+		ID:          "minimal",
+		Name:        "Minimal",
+		Description: "Aktif, Bitti",
+		Definition: TemplateDefinition{
+			Workflow: Workflow{Statuses: []BoardStatus{
+				{Key: "Active", Title: "Aktif Görevler", Color: "bg-blue-600", Order: 0, Category: CategoryInProgress},
+				{Key: "Done", Title: "Bitti", Color: "bg-green-600", Order: 1, Category: CategoryDone},
+			}},
+			Settings: BoardSettings{DefaultView: "list", Priorities: defaultPriorities},
+		},
+	},
+}
+
+// builtinTemplate ID'si verilen hazır şablonu döndürür.
+func builtinTemplate(id string) (BoardTemplate, bool) {
+	for _, t := range builtinTemplates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return BoardTemplate{}, false
+}
+
+// effectiveWorkflow şablon migrasyonundan önce oluşturulmuş (sütunları boş) panolar için
+// pano tipinin hazır şablonundaki sütunları döndürür.
+func effectiveWorkflow(boardType string, wf Workflow) Workflow {
+	if len(wf.Statuses) > 0 {
+		return wf
+	}
+	if t, ok := builtinTemplate(boardType); ok {
+		return t.Definition.Workflow
+	}
+	standard, _ := builtinTemplate("standard")
+	return standard.Definition.Workflow
+}
+
+// Status anahtarı verilen sütunu döndürür.
+func (wf Workflow) Status(key string) (BoardStatus, bool) {
+	for _, s := range wf.Statuses {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return BoardStatus{}, false
+}
+
+// Category status değerinin kategorisini döndürür; tanımsız değerler "todo" sayılır.
+func (wf Workflow) Category(status string) string {
+	for _, s := range wf.Statuses {
+		if s.Key == status {
+			return s.Category
+		}
+	}
+	return CategoryTodo
+}
+
+// Has status değerinin workflow'da tanımlı olup olmadığını döndürür.
+func (wf Workflow) Has(status string) bool {
+	for _, s := range wf.Statuses {
+		if s.Key == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows from sütunundan to sütununa geçişe izin verilip verilmediğini döndürür.
+func (wf Workflow) Allows(from, to string) bool {
+	if from == to || from == "" {
+		return true
+	}
+	targets, ok := wf.Transitions[from]
+	if !ok {
+		return true
+	}
+	for _, t := range targets {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// validate sütun ve geçiş tanımlarını kontrol eder.
+func (wf Workflow) validate() error {
+	if len(wf.Statuses) == 0 {
+		return requestError("En az bir sütun tanımlanmalı")
+	}
+
+	keys := make(map[string]bool, len(wf.Statuses))
+	for _, s := range wf.Statuses {
+		if s.Key == "" {
+			return requestError("Sütun anahtarı boş olamaz")
+		}
+		if keys[s.Key] {
+			return requestError("Sütun anahtarı tekrar ediyor: " + s.Key)
+		}
+		switch s.Category {
+		case CategoryTodo, CategoryInProgress, CategoryDone:
+		default:
+			return requestError("Geçersiz sütun kategorisi: " + s.Category)
+		}
+		if s.WIPLimit < 0 || s.AssigneeWIPLimit < 0 {
+			return requestError("WIP limiti negatif olamaz: " + s.Key)
+		}
+		keys[s.Key] = true
+	}
+
+	for from, targets := range wf.Transitions {
+		if !keys[from] {
+			return requestError("Geçiş tanımında bilinmeyen sütun: " + from)
+		}
+		for _, to := range targets {
+			if !keys[to] {
+				return requestError("Geçiş tanımında bilinmeyen sütun: " + to)
+			}
+		}
+	}
+	return nil
+}
+
+// validate özel şablon tanımlarını CreateBoard'a gitmeden önce kontrol eder.
+func (d *TemplateDefinition) validate() error {
+	if err := d.Workflow.validate(); err != nil {
+		return err
+	}
+
+	for _, t := range d.Tasks {
+		if t.Title == "" {
+			return requestError("Şablondaki görevlerin başlığı olmalı")
+		}
+		if !d.Workflow.Has(t.Status) {
+			return requestError("Görev tanımsız bir sütunda: " + t.Status)
+		}
+	}
+	return nil
+}
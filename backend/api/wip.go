@@ -0,0 +1,337 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// wipEntry bir sütuna giren görev (yeni, taşınan veya yeniden atanan)
+type wipEntry struct {
+	TaskID     string // yeni görevlerde boş
+	Assignee   string
+	Reassigned bool // görev zaten sütunda, sadece atanan kişi değişiyor (sütun limiti etkilenmez)
+}
+
+// wipViolation bir hareketin aşacağı WIP limiti
+type wipViolation struct {
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"` // kişi başı limit aşıldıysa
+	Limit    int    `json:"limit"`
+	Current  int    `json:"current"`
+	Incoming int    `json:"incoming"`
+}
+
+func (v *wipViolation) Error() string {
+	if v.Assignee != "" {
+		return fmt.Sprintf("%q sütununda kişi başı WIP limiti (%d) aşılıyor: atanan kişinin %d görevi var", v.Status, v.Limit, v.Current)
+	}
+	return fmt.Sprintf("%q sütununda WIP limiti (%d) aşılıyor: sütunda %d görev var", v.Status, v.Limit, v.Current)
+}
+
+// countTasks panodaki görevleri sayar (assignee boşsa tüm sütun).
+func countTasks(token, boardID, status, assignee string) (int, error) {
+	endpoint := fmt.Sprintf("tasks?board_id=eq.%s&status=eq.%s&select=id", boardID, url.QueryEscape(status))
+	if assignee != "" {
+		endpoint += "&assigned_to=eq." + assignee
+	}
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		return 0, err
+	}
+	var rows []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp, &rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// checkWIPLimits entering görevleri status sütununa girdiğinde limitlerin aşılıp aşılmadığını kontrol eder.
+// Önce sütun limiti, sonra kişi başı limit kontrol edilir; ilk ihlal döner.
+func checkWIPLimits(token, boardID string, wf Workflow, status string, entering []wipEntry) (*wipViolation, error) {
+	column, ok := wf.Status(status)
+	if !ok || len(entering) == 0 {
+		return nil, nil
+	}
+
+	moving := 0
+	for _, e := range entering {
+		if !e.Reassigned {
+			moving++
+		}
+	}
+	if column.WIPLimit > 0 && moving > 0 {
+		current, err := countTasks(token, boardID, status, "")
+		if err != nil {
+			return nil, err
+		}
+		if current+moving > column.WIPLimit {
+			return &wipViolation{Status: status, Limit: column.WIPLimit, Current: current, Incoming: moving}, nil
+		}
+	}
+
+	if column.AssigneeWIPLimit > 0 {
+		incoming := make(map[string]int)
+		var order []string
+		for _, e := range entering {
+			if e.Assignee == "" {
+				continue
+			}
+			if incoming[e.Assignee] == 0 {
+				order = append(order, e.Assignee)
+			}
+			incoming[e.Assignee]++
+		}
+		for _, assignee := range order {
+			current, err := countTasks(token, boardID, status, assignee)
+			if err != nil {
+				return nil, err
+			}
+			if current+incoming[assignee] > column.AssigneeWIPLimit {
+				return &wipViolation{Status: status, Assignee: assignee, Limit: column.AssigneeWIPLimit, Current: current, Incoming: incoming[assignee]}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// enforceWIP limitleri kontrol eder. İhlal varsa ve override istenmemişse 409 (mevcut sayı ile) yazar.
+// Override sadece pano sahibine açıktır; kabul edilen ihlal, yazma başarılı olduktan sonra
+// recordWIPOverride ile kaydedilmek üzere döndürülür. ok=false ise yanıt yazılmıştır.
+func enforceWIP(w http.ResponseWriter, token string, access *BoardAccess, wf Workflow, status string, entering []wipEntry, override bool) (*wipViolation, bool) {
+	violation, err := checkWIPLimits(token, access.BoardID, wf, status, entering)
+	if err != nil {
+		fmt.Println("WIP Kontrol Hatası:", err)
+		http.Error(w, "WIP limiti kontrol edilemedi", http.StatusInternalServerError)
+		return nil, false
+	}
+	if violation == nil {
+		return nil, true
+	}
+
+	if override {
+		if access.Role != RoleOwner {
+			http.Error(w, "WIP limitini sadece pano sahibi aşabilir", http.StatusForbidden)
+			return nil, false
+		}
+		return violation, true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     violation.Error(),
+		"wip_limit": violation,
+	})
+	return nil, false
+}
+
+// recordWIPOverride sahibin limit aşımını wip_overrides tablosuna yazar (hata işlemi durdurmaz).
+func recordWIPOverride(token string, access *BoardAccess, v *wipViolation, taskIDs []string) {
+	if v == nil {
+		return
+	}
+	if taskIDs == nil {
+		taskIDs = []string{} // içe aktarılan görevlerin ID'si yazmadan önce bilinmez
+	}
+	row := map[string]interface{}{
+		"board_id":      access.BoardID,
+		"user_id":       access.UserID,
+		"status":        v.Status,
+		"wip_limit":     v.Limit,
+		"current_count": v.Current,
+		"task_ids":      taskIDs,
+	}
+	if v.Assignee != "" {
+		row["assignee"] = v.Assignee
+	}
+	if _, err := performSupabaseRequest("POST", "wip_overrides", token, row); err != nil {
+		fmt.Println("WIP Override Kayıt Hatası:", err)
+	}
+	GlobalHub.Publish(access.BoardID, "wip_limit_overridden", map[string]interface{}{
+		"status":   v.Status,
+		"assignee": v.Assignee,
+		"limit":    v.Limit,
+		"current":  v.Current + v.Incoming,
+		"by":       access.UserID,
+		"task_ids": taskIDs,
+	})
+}
+
+// wipOverrideRequested query'de override_wip=true verilip verilmediği. Görev yazan tüm uç noktalar
+// (görev, toplu taşıma, sütun remap'i, içe aktarmalar) override'ı bu parametreyle alır.
+func wipOverrideRequested(r *http.Request) bool {
+	return r.URL.Query().Get("override_wip") == "true"
+}
+
+// enforceWIPByStatus görevleri hedef sütunlarına göre gruplayıp her sütunun limitini kontrol eder
+// (içe aktarma ve sütun remap'i gibi toplu yazmalar için). Kabul edilen ihlaller yazma başarılı
+// olduktan sonra recordWIPOverride ile kaydedilmelidir. ok=false ise yanıt yazılmıştır.
+func enforceWIPByStatus(w http.ResponseWriter, token string, access *BoardAccess, wf Workflow, tasks []Task, override bool) ([]*wipViolation, bool) {
+	entering := make(map[string][]wipEntry)
+	var order []string
+	for _, t := range tasks {
+		if _, ok := entering[t.Status]; !ok {
+			order = append(order, t.Status)
+		}
+		entry := wipEntry{TaskID: t.ID}
+		if t.AssignedTo != nil {
+			entry.Assignee = *t.AssignedTo
+		}
+		entering[t.Status] = append(entering[t.Status], entry)
+	}
+
+	var overrides []*wipViolation
+	for _, status := range order {
+		violation, ok := enforceWIP(w, token, access, wf, status, entering[status], override)
+		if !ok {
+			return nil, false
+		}
+		if violation != nil {
+			overrides = append(overrides, violation)
+		}
+	}
+	return overrides, true
+}
+
+// MoveTasksRequest toplu taşıma isteği. Limit ve bağımlılık aşımı diğer görev yazmalarıyla aynı
+// şekilde ?override_wip=true ve ?override_dependencies=true ile istenir.
+type MoveTasksRequest struct {
+	BoardID string   `json:"board_id"`
+	TaskIDs []string `json:"task_ids"`
+	Status  string   `json:"status"`
+}
+
+// MoveTasks birden fazla görevi tek seferde başka bir sütuna taşır. Geçiş kuralları ve bağımlılıklar her görev için,
+// WIP limitleri ise taşınan görevlerin toplamı için kontrol edilir; biri bile uymazsa hiçbiri taşınmaz.
+func MoveTasks(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 {
+		http.Error(w, "Geçersiz Token formatı", http.StatusUnauthorized)
+		return
+	}
+	token := authHeader[7:]
+
+	access, ok := BoardAccessFromContext(r.Context())
+	if !ok {
+		http.Error(w, "board_id gerekli", http.StatusBadRequest)
+		return
+	}
+
+	var req MoveTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Geçersiz İstek Gövdesi", http.StatusBadRequest)
+		return
+	}
+	// Aynı görev birden fazla gönderildiyse bir kez sayılır
+	var taskIDs []string
+	seen := make(map[string]bool, len(req.TaskIDs))
+	for _, id := range req.TaskIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			taskIDs = append(taskIDs, id)
+		}
+	}
+	if len(taskIDs) == 0 || req.Status == "" {
+		http.Error(w, "task_ids ve status gerekli", http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := boardWorkflow(token, access.BoardID)
+	if err != nil {
+		fmt.Println("MoveTasks Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	endpoint := fmt.Sprintf("tasks?id=in.(%s)&board_id=eq.%s&select=id,status,assigned_to", strings.Join(taskIDs, ","), access.BoardID)
+	resp, err := performSupabaseRequest("GET", endpoint, token, nil)
+	if err != nil {
+		fmt.Println("MoveTasks Hatası:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var tasks []Task
+	if err := json.Unmarshal(resp, &tasks); err != nil {
+		http.Error(w, "Veri işleme hatası", http.StatusInternalServerError)
+		return
+	}
+	if len(tasks) != len(taskIDs) {
+		http.Error(w, "Görevlerden bazıları bu panoda bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	var status string
+	var entering []wipEntry
+	var moved []string
+	for _, t := range tasks {
+		key, err := validateStatusMove(workflow, t.Status, req.Status)
+		if err != nil {
+			if moveErr, ok := err.(*statusMoveError); ok {
+				http.Error(w, fmt.Sprintf("Görev %s: %v", t.ID, moveErr), http.StatusConflict)
+				return
+			}
+			writeStatusError(w, "MoveTasks", err)
+			return
+		}
+		status = key
+		if t.Status == key {
+			continue
+		}
+		entry := wipEntry{TaskID: t.ID}
+		if t.AssignedTo != nil {
+			entry.Assignee = *t.AssignedTo
+		}
+		entering = append(entering, entry)
+		moved = append(moved, t.ID)
+	}
+
+	// Zaten tamamlanmış bir sütundan gelen görevlerin engelleyicileri tekrar kontrol edilmez
+	var completing []string
+	for _, t := range tasks {
+		if t.Status != status && workflow.Category(t.Status) != CategoryDone {
+			completing = append(completing, t.ID)
+		}
+	}
+	dependencyOverride, ok := enforceDependencies(w, token, access, workflow, status, completing, dependencyOverrideRequested(r))
+	if !ok {
+		return
+	}
+
+	override, ok := enforceWIP(w, token, access, workflow, status, entering, wipOverrideRequested(r))
+	if !ok {
+		return
+	}
+
+	if len(moved) > 0 {
+		endpoint = fmt.Sprintf("tasks?id=in.(%s)&board_id=eq.%s", strings.Join(moved, ","), access.BoardID)
+		if _, err := performSupabaseRequest("PATCH", endpoint, token, map[string]string{"status": status}); err != nil {
+			fmt.Println("MoveTasks Hatası:", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		recordWIPOverride(token, access, override, moved)
+		recordDependencyOverride(access, dependencyOverride)
+		if workflow.Category(status) == CategoryDone {
+			go completeRecurringTasks(token, completing)
+		}
+		GlobalHub.Publish(access.BoardID, "tasks_moved", map[string]interface{}{
+			"task_ids": moved,
+			"status":   status,
+			"moved_by": access.UserID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":                  status,
+		"moved":                   moved,
+		"already_in_place":        len(tasks) - len(moved),
+		"wip_overridden":          override != nil,
+		"dependencies_overridden": dependencyOverride != nil,
+	})
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Doğrulama modları (AUTH_MODE ortam değişkeni)
+const (
+	// ModeAuto token'ı yerelde doğrular; anahtar bulunamazsa Supabase Auth'a sorar.
+	ModeAuto = "auto"
+	// ModeLocal sadece yerel doğrulama yapar.
+	ModeLocal = "local"
+	// ModeRemote her istekte Supabase Auth'a sorar (eski davranış).
+	ModeRemote = "remote"
+)
+
+// Mode AUTH_MODE ortam değişkenini okur, tanınmayan değerlerde ModeAuto döner.
+func Mode() string {
+	switch m := strings.ToLower(os.Getenv("AUTH_MODE")); m {
+	case ModeLocal, ModeRemote:
+		return m
+	default:
+		return ModeAuto
+	}
+}
+
+// NewVerifierFromEnv ortam değişkenlerinden bir Verifier oluşturur.
+//
+//	SUPABASE_JWT_SECRET   HS256 için proje JWT secret'ı (opsiyonel)
+//	SUPABASE_JWKS_URL     RS256/ES256 anahtarları (varsayılan: $SUPABASE_URL/auth/v1/.well-known/jwks.json)
+//	SUPABASE_JWT_AUDIENCE beklenen aud (varsayılan: authenticated)
+//	SUPABASE_JWT_ISSUER   beklenen iss (varsayılan: $SUPABASE_URL/auth/v1)
+func NewVerifierFromEnv() *Verifier {
+	supabaseUrl := strings.TrimRight(os.Getenv("SUPABASE_URL"), "/")
+
+	v := &Verifier{
+		Audience: envOr("SUPABASE_JWT_AUDIENCE", "authenticated"),
+		Issuer:   os.Getenv("SUPABASE_JWT_ISSUER"),
+		Leeway:   30 * time.Second,
+	}
+
+	if secret := os.Getenv("SUPABASE_JWT_SECRET"); secret != "" {
+		v.Secret = []byte(secret)
+	}
+
+	jwksUrl := os.Getenv("SUPABASE_JWKS_URL")
+	if jwksUrl == "" && supabaseUrl != "" {
+		jwksUrl = supabaseUrl + "/auth/v1/.well-known/jwks.json"
+	}
+	if jwksUrl != "" {
+		v.JWKS = NewJWKSCache(jwksUrl)
+	}
+
+	if v.Issuer == "" && supabaseUrl != "" {
+		v.Issuer = supabaseUrl + "/auth/v1"
+	}
+
+	return v
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwk JWKS uç noktasından gelen tek bir anahtar.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKSCache JWKS anahtarlarını önbellekte tutar.
+// Anahtarlar TTL dolunca yenilenir; bilinmeyen bir kid geldiğinde (anahtar rotasyonu)
+// en fazla MinRefreshInterval aralıkla yeniden çekilir.
+type JWKSCache struct {
+	URL                string
+	TTL                time.Duration
+	MinRefreshInterval time.Duration
+	HTTPClient         *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewJWKSCache varsayılan sürelerle yeni bir önbellek oluşturur.
+func NewJWKSCache(url string) *JWKSCache {
+	return &JWKSCache{
+		URL:                url,
+		TTL:                10 * time.Minute,
+		MinRefreshInterval: 30 * time.Second,
+		HTTPClient:         &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Key verilen kid'e ait açık anahtarı döndürür.
+func (c *JWKSCache) Key(kid string) (crypto.PublicKey, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	fresh := time.Since(c.fetchedAt) < c.TTL
+	c.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+
+	// Süresi dolmuş ya da bilinmeyen anahtar: yenilemeyi dene.
+	if err := c.refresh(); err != nil {
+		// Yenileme başarısızsa elimizdeki (eski) anahtarla devam et.
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrKeyUnavailable, err)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid=%s", ErrKeyUnavailable, kid)
+}
+
+func (c *JWKSCache) refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Aynı anda gelen isteklerin JWKS uç noktasını boğmasını engelle
+	if time.Since(c.lastAttempt) < c.MinRefreshInterval {
+		if c.keys == nil {
+			return fmt.Errorf("JWKS henüz yüklenemedi")
+		}
+		return nil
+	}
+	c.lastAttempt = time.Now()
+
+	resp, err := c.HTTPClient.Get(c.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS isteği başarısız (%d)", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("desteklenmeyen eğri: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("desteklenmeyen anahtar tipi: %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformedToken   = errors.New("token formatı geçersiz")
+	ErrUnsupportedAlg   = errors.New("desteklenmeyen imza algoritması")
+	ErrInvalidSignature = errors.New("token imzası geçersiz")
+	ErrTokenExpired     = errors.New("token süresi dolmuş")
+	ErrTokenNotYetValid = errors.New("token henüz geçerli değil")
+	ErrInvalidAudience  = errors.New("token audience değeri geçersiz")
+	ErrInvalidIssuer    = errors.New("token issuer değeri geçersiz")
+	// ErrKeyUnavailable imzayı doğrulayacak anahtar materyaline ulaşılamadığında döner.
+	// Bu durumda token'ın geçersiz olduğu söylenemez; çağıran taraf uzaktan doğrulamaya düşebilir.
+	ErrKeyUnavailable = errors.New("doğrulama anahtarı bulunamadı")
+)
+
+// Audience JWT "aud" alanı hem string hem de string dizisi olabilir.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Contains verilen değerin audience listesinde olup olmadığını kontrol eder.
+func (a Audience) Contains(v string) bool {
+	for _, s := range a {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims Supabase access token'ında kullandığımız alanlar.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Email     string   `json:"email"`
+	Role      string   `json:"role"`
+	Audience  Audience `json:"aud"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	SessionID string   `json:"session_id,omitempty"`
+}
+
+// Expiry token'ın bitiş zamanını döndürür (exp yoksa sıfır zaman).
+func (c *Claims) Expiry() time.Time {
+	if c.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Verifier Supabase JWT'lerini yerel olarak doğrular.
+// HS256 için proje JWT secret'ı, RS256/ES256 için JWKS kullanılır.
+type Verifier struct {
+	Secret   []byte
+	JWKS     *JWKSCache
+	Audience string
+	Issuer   string
+	Leeway   time.Duration
+}
+
+// Configured yerel doğrulama için en az bir anahtar kaynağı olup olmadığını döndürür.
+func (v *Verifier) Configured() bool {
+	return v != nil && (len(v.Secret) > 0 || v.JWKS != nil)
+}
+
+// Verify token'ın imzasını ve exp/nbf/aud/iss alanlarını kontrol eder.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var h header
+	if err := json.Unmarshal(headerBytes, &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+
+	if err := v.verifySignature(h, signingInput, signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) verifySignature(h header, signingInput, signature []byte) error {
+	switch h.Alg {
+	case "HS256":
+		if len(v.Secret) == 0 {
+			return ErrKeyUnavailable
+		}
+		mac := hmac.New(sha256.New, v.Secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "RS256", "ES256":
+		if v.JWKS == nil {
+			return ErrKeyUnavailable
+		}
+		key, err := v.JWKS.Key(h.Kid)
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256(signingInput)
+		return verifyAsymmetric(h.Alg, key, digest[:], signature)
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlg, h.Alg)
+	}
+}
+
+func verifyAsymmetric(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: anahtar tipi RS256 ile uyuşmuyor", ErrUnsupportedAlg)
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().BitSize != 256 {
+			return fmt.Errorf("%w: anahtar tipi ES256 ile uyuşmuyor", ErrUnsupportedAlg)
+		}
+		// JWS, ECDSA imzasını DER yerine sabit uzunlukta r||s olarak taşır.
+		if len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrUnsupportedAlg
+}
+
+func (v *Verifier) validateClaims(c *Claims) error {
+	now := time.Now()
+
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != 0 && now.Add(v.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrTokenNotYetValid
+	}
+	if v.Audience != "" && !c.Audience.Contains(v.Audience) {
+		return ErrInvalidAudience
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if c.Subject == "" {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+// UnverifiedExpiry imzayı kontrol etmeden token'ın exp alanını okur.
+// Sadece başka bir yolla (ör. Supabase Auth) doğrulanmış token'lar için kullanılmalıdır.
+func UnverifiedExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}
+	}
+	return claims.Expiry()
+}
+
+// SignHS256 claim'leri verilen secret ile imzalar.
+// PAT ile gelen isteklerde PostgREST'e (RLS için) kısa ömürlü bir kullanıcı token'ı vermek için kullanılır.
+func SignHS256(claims map[string]interface{}, secret []byte) (string, error) {
+	headerBytes, err := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,363 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-jwt-secret")
+
+// testKeys testlerde kullanılan RSA ve EC anahtarları
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// signToken claim'leri alg ile imzalar; "none" imzasız token üretir.
+func signToken(t *testing.T, keys testKeys, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	headerBytes, _ := json.Marshal(header{Alg: alg, Kid: kid, Typ: "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := b64(headerBytes) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case "HS256":
+		token, err := SignHS256(claims, testSecret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	case "RS256":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, keys.rsa, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = sig
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, keys.ec, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		// JWS: sabit uzunlukta r||s (DER değil)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	case "none":
+	}
+	return signingInput + "." + b64(signature)
+}
+
+// jwksServer anahtarları JWKS olarak sunar ve istek sayısını tutar.
+type jwksServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []map[string]string
+	hits int
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	s := &jwksServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.hits++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) publish(kid string, key crypto.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		s.keys = append(s.keys, map[string]string{
+			"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+			"n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes()),
+		})
+	case *ecdsa.PublicKey:
+		x, y := make([]byte, 32), make([]byte, 32)
+		k.X.FillBytes(x)
+		k.Y.FillBytes(y)
+		s.keys = append(s.keys, map[string]string{
+			"kty": "EC", "kid": kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+			"x": b64(x), "y": b64(y),
+		})
+	}
+}
+
+func (s *jwksServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits
+}
+
+func testCache(url string, minRefresh time.Duration) *JWKSCache {
+	c := NewJWKSCache(url)
+	c.MinRefreshInterval = minRefresh
+	return c
+}
+
+func validClaims(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "user-1",
+		"email": "a@example.com",
+		"role":  "authenticated",
+		"aud":   "authenticated",
+		"iss":   "https://proj.supabase.co/auth/v1",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+// with claim'lerin kopyasını verilen alanlarla döndürür (nil değer alanı siler).
+func with(claims map[string]interface{}, changes map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(claims))
+	for k, v := range claims {
+		out[k] = v
+	}
+	for k, v := range changes {
+		if v == nil {
+			delete(out, k)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func TestVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	server := newJWKSServer(t)
+	server.publish("rsa-1", &keys.rsa.PublicKey)
+	server.publish("ec-1", &keys.ec.PublicKey)
+
+	now := time.Now()
+	claims := validClaims(now)
+	verifier := &Verifier{
+		Secret:   testSecret,
+		JWKS:     testCache(server.URL, 0),
+		Audience: "authenticated",
+		Issuer:   "https://proj.supabase.co/auth/v1",
+		Leeway:   30 * time.Second,
+	}
+
+	tamper := func(token string) string {
+		// imzanın ilk karakterini değiştir
+		i := len(token) - 10
+		c := byte('A')
+		if token[i] == 'A' {
+			c = 'B'
+		}
+		return token[:i] + string(c) + token[i+1:]
+	}
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		token    string
+		wantErr  error
+	}{
+		{name: "geçerli HS256", verifier: verifier, token: signToken(t, keys, "HS256", "", claims)},
+		{name: "geçerli RS256", verifier: verifier, token: signToken(t, keys, "RS256", "rsa-1", claims)},
+		{name: "geçerli ES256 (r||s)", verifier: verifier, token: signToken(t, keys, "ES256", "ec-1", claims)},
+		{name: "bozuk HS256 imzası", verifier: verifier, token: tamper(signToken(t, keys, "HS256", "", claims)), wantErr: ErrInvalidSignature},
+		{name: "bozuk RS256 imzası", verifier: verifier, token: tamper(signToken(t, keys, "RS256", "rsa-1", claims)), wantErr: ErrInvalidSignature},
+		{name: "bozuk ES256 imzası", verifier: verifier, token: tamper(signToken(t, keys, "ES256", "ec-1", claims)), wantErr: ErrInvalidSignature},
+		{name: "yanlış anahtar tipi", verifier: verifier, token: signToken(t, keys, "RS256", "ec-1", claims), wantErr: ErrUnsupportedAlg},
+		{name: "alg none", verifier: verifier, token: signToken(t, keys, "none", "", claims), wantErr: ErrUnsupportedAlg},
+		{
+			name: "secret yokken HS256", verifier: &Verifier{JWKS: verifier.JWKS},
+			token: signToken(t, keys, "HS256", "", claims), wantErr: ErrKeyUnavailable,
+		},
+		{
+			name: "JWKS yokken RS256", verifier: &Verifier{Secret: testSecret},
+			token: signToken(t, keys, "RS256", "rsa-1", claims), wantErr: ErrKeyUnavailable,
+		},
+		{name: "format hatalı", verifier: verifier, token: "abc.def", wantErr: ErrMalformedToken},
+		{
+			name: "süresi dolmuş", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"exp": now.Add(-time.Minute).Unix()})), wantErr: ErrTokenExpired,
+		},
+		{
+			name: "süresi leeway içinde dolmuş", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"exp": now.Add(-10 * time.Second).Unix()})),
+		},
+		{
+			name: "exp yok", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"exp": nil})), wantErr: ErrTokenExpired,
+		},
+		{
+			name: "henüz geçerli değil", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"nbf": now.Add(time.Minute).Unix()})), wantErr: ErrTokenNotYetValid,
+		},
+		{
+			name: "nbf leeway içinde", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"nbf": now.Add(10 * time.Second).Unix()})),
+		},
+		{
+			name: "yanlış aud", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"aud": "anon"})), wantErr: ErrInvalidAudience,
+		},
+		{
+			name: "aud dizisi", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"aud": []string{"other", "authenticated"}})),
+		},
+		{
+			name: "yanlış iss", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"iss": "https://evil.example/auth/v1"})), wantErr: ErrInvalidIssuer,
+		},
+		{
+			name: "sub yok", verifier: verifier,
+			token: signToken(t, keys, "HS256", "", with(claims, map[string]interface{}{"sub": nil})), wantErr: ErrMalformedToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.verifier.Verify(tt.token)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("beklenmeyen hata: %v", err)
+				}
+				if got.Subject != "user-1" || got.Email != "a@example.com" {
+					t.Errorf("claim'ler hatalı: %+v", got)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("hata = %v, beklenen %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWKSUnknownKidRefreshes(t *testing.T) {
+	keys := newTestKeys(t)
+	server := newJWKSServer(t)
+	server.publish("rsa-1", &keys.rsa.PublicKey)
+
+	verifier := &Verifier{JWKS: testCache(server.URL, 0)}
+	claims := validClaims(time.Now())
+
+	if _, err := verifier.Verify(signToken(t, keys, "RS256", "rsa-1", claims)); err != nil {
+		t.Fatalf("ilk doğrulama: %v", err)
+	}
+	if server.requests() != 1 {
+		t.Fatalf("JWKS istek sayısı = %d, beklenen 1", server.requests())
+	}
+
+	// Önbellekteki anahtar TTL içinde tekrar çekilmez
+	if _, err := verifier.Verify(signToken(t, keys, "RS256", "rsa-1", claims)); err != nil {
+		t.Fatal(err)
+	}
+	if server.requests() != 1 {
+		t.Fatalf("JWKS istek sayısı = %d, beklenen 1", server.requests())
+	}
+
+	// Anahtar rotasyonu: bilinmeyen kid yenileme tetikler
+	server.publish("ec-2", &keys.ec.PublicKey)
+	if _, err := verifier.Verify(signToken(t, keys, "ES256", "ec-2", claims)); err != nil {
+		t.Fatalf("rotasyon sonrası doğrulama: %v", err)
+	}
+	if server.requests() != 2 {
+		t.Fatalf("JWKS istek sayısı = %d, beklenen 2", server.requests())
+	}
+
+	// Yenilemeden sonra da bulunamayan kid
+	if _, err := verifier.Verify(signToken(t, keys, "ES256", "missing", claims)); !errors.Is(err, ErrKeyUnavailable) {
+		t.Errorf("hata = %v, beklenen %v", err, ErrKeyUnavailable)
+	}
+}
+
+func TestJWKSMinRefreshInterval(t *testing.T) {
+	keys := newTestKeys(t)
+	server := newJWKSServer(t)
+	server.publish("rsa-1", &keys.rsa.PublicKey)
+
+	cache := testCache(server.URL, time.Hour)
+	verifier := &Verifier{JWKS: cache}
+	claims := validClaims(time.Now())
+
+	if _, err := verifier.Verify(signToken(t, keys, "RS256", "rsa-1", claims)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Yeni anahtar yayınlansa da MinRefreshInterval dolmadan tekrar çekilmez
+	server.publish("ec-2", &keys.ec.PublicKey)
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(signToken(t, keys, "ES256", "ec-2", claims)); !errors.Is(err, ErrKeyUnavailable) {
+			t.Fatalf("hata = %v, beklenen %v", err, ErrKeyUnavailable)
+		}
+	}
+	if server.requests() != 1 {
+		t.Fatalf("JWKS istek sayısı = %d, beklenen 1", server.requests())
+	}
+
+	// Süre dolunca yenilenir
+	cache.mu.Lock()
+	cache.lastAttempt = time.Now().Add(-2 * time.Hour)
+	cache.mu.Unlock()
+	if _, err := verifier.Verify(signToken(t, keys, "ES256", "ec-2", claims)); err != nil {
+		t.Fatalf("aralık dolduktan sonra: %v", err)
+	}
+	if server.requests() != 2 {
+		t.Fatalf("JWKS istek sayısı = %d, beklenen 2", server.requests())
+	}
+}
+
+func TestJWKSUnavailableKeepsStaleKey(t *testing.T) {
+	keys := newTestKeys(t)
+	server := newJWKSServer(t)
+	server.publish("rsa-1", &keys.rsa.PublicKey)
+
+	cache := testCache(server.URL, 0)
+	verifier := &Verifier{JWKS: cache}
+	claims := validClaims(time.Now())
+	if _, err := verifier.Verify(signToken(t, keys, "RS256", "rsa-1", claims)); err != nil {
+		t.Fatal(err)
+	}
+
+	// TTL doldu ve JWKS uç noktası kapandı: eldeki anahtarla devam edilir
+	server.Close()
+	cache.mu.Lock()
+	cache.fetchedAt = time.Now().Add(-time.Hour)
+	cache.mu.Unlock()
+	if _, err := verifier.Verify(signToken(t, keys, "RS256", "rsa-1", claims)); err != nil {
+		t.Errorf("eski anahtarla doğrulama: %v", err)
+	}
+	if _, err := verifier.Verify(signToken(t, keys, "RS256", "unknown", claims)); !errors.Is(err, ErrKeyUnavailable) {
+		t.Errorf("hata = %v, beklenen %v", err, ErrKeyUnavailable)
+	}
+}
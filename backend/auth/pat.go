@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// PATPrefix kişisel erişim token'larını Supabase JWT'lerinden ayırır.
+const PATPrefix = "gp_pat_"
+
+// GeneratePAT yeni bir kişisel erişim token'ı ve veritabanında saklanacak hash'ini üretir.
+// Düz token sadece bir kez (oluşturma yanıtında) kullanıcıya gösterilir.
+func GeneratePAT() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = PATPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashPAT(token), nil
+}
+
+// HashPAT token'ın SHA-256 hash'ini hex olarak döndürür.
+func HashPAT(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsPAT token'ın kişisel erişim token'ı formatında olup olmadığını döndürür.
+func IsPAT(token string) bool {
+	return strings.HasPrefix(token, PATPrefix)
+}
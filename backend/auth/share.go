@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// SharePrefix pano paylaşım linklerindeki token'ları diğer token'lardan ayırır.
+const SharePrefix = "gp_share_"
+
+// GenerateShareToken yeni bir paylaşım token'ı ve veritabanında saklanacak hash'ini üretir.
+// PAT'lerde olduğu gibi düz token sadece oluşturma yanıtında döner.
+func GenerateShareToken() (token, hash string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = SharePrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashPAT(token), nil
+}
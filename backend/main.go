@@ -4,13 +4,13 @@ import (
 	"fmt"
 	"go-panel/backend/api"
 	"go-panel/backend/db"
+	"go-panel/backend/router"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
 )
 
@@ -25,60 +25,11 @@ func main() {
 		log.Fatalf("Supabase başlatılamadı: %v", err)
 	}
 
-	// Router (Yönlendirici)
-	r := chi.NewRouter()
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	// Tekrarlayan görevleri zamanı geldiğinde oluşturan arka plan üreticisi
+	api.StartRecurrenceWorker()
 
-	// CORS Middleware
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
-
-			next.ServeHTTP(w, r)
-		})
-	})
-
-	// Rotalar
-	r.Get("/api/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"status":"ok"}`))
-	})
-
-	r.Route("/api", func(r chi.Router) {
-		// WebSocket Route (Auth handled via Query Param)
-		r.Get("/chat", api.HandleWebSocket)
-
-		// Protected Routes Group
-		r.Group(func(r chi.Router) {
-			r.Use(api.AuthMiddleware)
-
-			r.Get("/tasks", api.GetTasks)
-			r.Post("/tasks", api.CreateTask)
-			r.Put("/tasks", api.UpdateTask)
-			r.Delete("/tasks", api.DeleteTask)
-			r.Delete("/tasks/bulk", api.DeleteTasksByStatus)
-
-			// Panolar (Boards)
-			r.Get("/boards", api.GetBoards)
-			r.Post("/boards", api.CreateBoard)
-			r.Post("/boards/join", api.JoinBoard)
-			r.Delete("/boards", api.DeleteBoard)
-			r.Get("/boards/members", api.GetBoardMembers)
-
-			// Alt Görevler (Subtasks)
-			r.Post("/subtasks", api.CreateSubtask)
-			r.Put("/subtasks", api.UpdateSubtask)
-			r.Delete("/subtasks", api.DeleteSubtask)
-		})
-	})
+	// Router (Yönlendirici) - Vercel ile aynı rota tanımları
+	r := router.Setup("http://localhost:5173")
 
 	// Statik Dosyalar (Frontend Deployment)
 	// Eğer "../frontend/dist" klasörü varsa oradan sunar.
@@ -0,0 +1,45 @@
+-- 1. Board metadata. Settings (default view, default assignee, timezone) live in boards.settings.
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS color TEXT;
+
+-- 2. Archiving: archived boards are read-only and hidden from the board list by default
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS archived_by UUID REFERENCES auth.users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS boards_active_idx ON public.boards (created_at DESC) WHERE archived_at IS NULL;
+
+-- 3. Safety net: no new content on archived boards (the Go API rejects these writes first).
+-- Deletes are not checked so an archived board can still be deleted with its tasks.
+CREATE OR REPLACE FUNCTION public.reject_archived_board_writes()
+RETURNS trigger AS $$
+DECLARE
+  target_board UUID;
+BEGIN
+  IF TG_TABLE_NAME = 'subtasks' THEN
+    SELECT t.board_id INTO target_board FROM public.tasks t WHERE t.id = NEW.task_id;
+  ELSE
+    target_board := NEW.board_id;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM public.boards b WHERE b.id = target_board AND b.archived_at IS NOT NULL) THEN
+    RAISE EXCEPTION 'board % is archived', target_board
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_reject_archived ON public.tasks;
+CREATE TRIGGER tasks_reject_archived
+  BEFORE INSERT OR UPDATE ON public.tasks
+  FOR EACH ROW EXECUTE FUNCTION public.reject_archived_board_writes();
+
+DROP TRIGGER IF EXISTS subtasks_reject_archived ON public.subtasks;
+CREATE TRIGGER subtasks_reject_archived
+  BEFORE INSERT OR UPDATE ON public.subtasks
+  FOR EACH ROW EXECUTE FUNCTION public.reject_archived_board_writes();
+
+DROP TRIGGER IF EXISTS messages_reject_archived ON public.messages;
+CREATE TRIGGER messages_reject_archived
+  BEFORE INSERT ON public.messages
+  FOR EACH ROW EXECUTE FUNCTION public.reject_archived_board_writes();
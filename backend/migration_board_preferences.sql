@@ -0,0 +1,62 @@
+-- 1. Per-user board preferences, synced across devices.
+-- notification_level: 'all' (every board event), 'mentions' (only when mentioned), 'none'
+CREATE TABLE IF NOT EXISTS public.board_preferences (
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE,
+    pinned BOOLEAN NOT NULL DEFAULT false,
+    sort_order INTEGER, -- NULL: not ordered manually (listed after ordered boards)
+    hidden BOOLEAN NOT NULL DEFAULT false,
+    default_view TEXT,
+    default_sort TEXT,
+    notification_level TEXT NOT NULL DEFAULT 'all' CHECK (notification_level IN ('all', 'mentions', 'none')),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    PRIMARY KEY (user_id, board_id)
+);
+
+ALTER TABLE public.board_preferences ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Users can manage their board preferences" ON public.board_preferences;
+CREATE POLICY "Users can manage their board preferences"
+ON public.board_preferences FOR ALL
+USING ( auth.uid() = user_id )
+WITH CHECK ( auth.uid() = user_id AND public.board_role(board_id) IS NOT NULL );
+
+-- 2. Upsert the caller's preferences for a board. Only keys present in p_prefs change;
+-- a JSON null clears sort_order, default_view and default_sort.
+CREATE OR REPLACE FUNCTION public.set_board_preferences(p_board UUID, p_prefs JSONB)
+RETURNS SETOF public.board_preferences AS $$
+  INSERT INTO public.board_preferences AS p
+    (user_id, board_id, pinned, sort_order, hidden, default_view, default_sort, notification_level)
+  VALUES (
+    auth.uid(),
+    p_board,
+    COALESCE((p_prefs->>'pinned')::boolean, false),
+    (p_prefs->>'sort_order')::integer,
+    COALESCE((p_prefs->>'hidden')::boolean, false),
+    p_prefs->>'default_view',
+    p_prefs->>'default_sort',
+    COALESCE(p_prefs->>'notification_level', 'all')
+  )
+  ON CONFLICT (user_id, board_id) DO UPDATE SET
+    pinned = CASE WHEN p_prefs ? 'pinned' THEN EXCLUDED.pinned ELSE p.pinned END,
+    sort_order = CASE WHEN p_prefs ? 'sort_order' THEN EXCLUDED.sort_order ELSE p.sort_order END,
+    hidden = CASE WHEN p_prefs ? 'hidden' THEN EXCLUDED.hidden ELSE p.hidden END,
+    default_view = CASE WHEN p_prefs ? 'default_view' THEN EXCLUDED.default_view ELSE p.default_view END,
+    default_sort = CASE WHEN p_prefs ? 'default_sort' THEN EXCLUDED.default_sort ELSE p.default_sort END,
+    notification_level = CASE WHEN p_prefs ? 'notification_level' THEN EXCLUDED.notification_level ELSE p.notification_level END,
+    updated_at = timezone('utc'::text, now())
+  RETURNING *;
+$$ LANGUAGE sql;
+
+-- 3. Manual ordering: boards get sort_order 0..n-1 in the given order
+CREATE OR REPLACE FUNCTION public.set_board_order(p_boards UUID[])
+RETURNS INTEGER AS $$
+DECLARE
+  i INTEGER;
+BEGIN
+  FOR i IN 1 .. COALESCE(array_length(p_boards, 1), 0) LOOP
+    PERFORM public.set_board_preferences(p_boards[i], jsonb_build_object('sort_order', i - 1));
+  END LOOP;
+  RETURN COALESCE(array_length(p_boards, 1), 0);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,9 @@
+-- Owners and admins can add members directly (board cloning copies the member list).
+-- Roles match ValidMemberRole; only the owner can add admins, as in UpdateBoardMemberRole.
+DROP POLICY IF EXISTS "Admins can add members" ON public.board_members;
+CREATE POLICY "Admins can add members"
+ON public.board_members FOR INSERT
+WITH CHECK (
+  public.board_role(board_id) IN ('owner', 'admin')
+  AND (role IN ('member', 'viewer') OR (role = 'admin' AND public.board_role(board_id) = 'owner'))
+);
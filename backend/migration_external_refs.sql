@@ -0,0 +1,69 @@
+-- 1. External references so imports (Trello, ...) can be re-run without creating duplicates.
+-- Format: '<source>:<id>', e.g. 'trello:5f1c...'
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS external_ref TEXT;
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS external_ref TEXT;
+ALTER TABLE public.subtasks ADD COLUMN IF NOT EXISTS external_ref TEXT;
+
+-- NULLs are distinct, so tasks created in the app are unaffected
+ALTER TABLE public.tasks DROP CONSTRAINT IF EXISTS tasks_board_external_ref_key;
+ALTER TABLE public.tasks ADD CONSTRAINT tasks_board_external_ref_key UNIQUE (board_id, external_ref);
+
+ALTER TABLE public.subtasks DROP CONSTRAINT IF EXISTS subtasks_task_external_ref_key;
+ALTER TABLE public.subtasks ADD CONSTRAINT subtasks_task_external_ref_key UNIQUE (task_id, external_ref);
+
+CREATE INDEX IF NOT EXISTS boards_external_ref_idx ON public.boards (external_ref) WHERE external_ref IS NOT NULL;
+
+-- 2. Insert or update imported tasks and their subtasks in one transaction.
+-- Runs with the caller's rights (RLS applies). Existing assignments are kept when the import has none.
+-- p_tasks: [{external_ref, title, description, status, priority, due_date, position, assigned_to,
+--            subtasks: [{external_ref, title, is_completed, position}]}]
+CREATE OR REPLACE FUNCTION public.upsert_external_tasks(p_board UUID, p_tasks JSONB)
+RETURNS JSONB AS $$
+DECLARE
+  t JSONB;
+  s JSONB;
+  v_task UUID;
+  inserted BOOLEAN;
+  created INTEGER := 0;
+  updated INTEGER := 0;
+  sub_created INTEGER := 0;
+  sub_updated INTEGER := 0;
+BEGIN
+  FOR t IN SELECT * FROM jsonb_array_elements(p_tasks) LOOP
+    INSERT INTO public.tasks (board_id, external_ref, title, description, status, priority, due_date, position, assigned_to)
+    SELECT p_board, r.external_ref, r.title, r.description, r.status, r.priority, r.due_date, r.position, r.assigned_to
+    FROM jsonb_populate_record(NULL::public.tasks, t - 'subtasks') AS r
+    ON CONFLICT ON CONSTRAINT tasks_board_external_ref_key DO UPDATE SET
+      title = EXCLUDED.title,
+      description = EXCLUDED.description,
+      status = EXCLUDED.status,
+      priority = EXCLUDED.priority,
+      due_date = EXCLUDED.due_date,
+      position = EXCLUDED.position,
+      assigned_to = COALESCE(EXCLUDED.assigned_to, public.tasks.assigned_to)
+    RETURNING id, (xmax = 0) INTO v_task, inserted;
+
+    IF inserted THEN created := created + 1; ELSE updated := updated + 1; END IF;
+
+    FOR s IN SELECT * FROM jsonb_array_elements(COALESCE(t->'subtasks', '[]'::jsonb)) LOOP
+      INSERT INTO public.subtasks (task_id, external_ref, title, is_completed, position)
+      SELECT v_task, r.external_ref, r.title, COALESCE(r.is_completed, false), COALESCE(r.position, 0)
+      FROM jsonb_populate_record(NULL::public.subtasks, s) AS r
+      ON CONFLICT ON CONSTRAINT subtasks_task_external_ref_key DO UPDATE SET
+        title = EXCLUDED.title,
+        is_completed = EXCLUDED.is_completed,
+        position = EXCLUDED.position
+      RETURNING (xmax = 0) INTO inserted;
+
+      IF inserted THEN sub_created := sub_created + 1; ELSE sub_updated := sub_updated + 1; END IF;
+    END LOOP;
+  END LOOP;
+
+  RETURN jsonb_build_object(
+    'created', created,
+    'updated', updated,
+    'subtasks_created', sub_created,
+    'subtasks_updated', sub_updated
+  );
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,125 @@
+-- 1. Invite links: expiring, limited-use, role-bearing
+CREATE TABLE IF NOT EXISTS public.board_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE,
+    code TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member', 'viewer')),
+    max_uses INTEGER CHECK (max_uses IS NULL OR max_uses > 0), -- NULL: unlimited
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE, -- NULL: never
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES auth.users(id) ON DELETE SET NULL DEFAULT auth.uid(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS board_invites_board_id_idx ON public.board_invites(board_id);
+
+-- 2. Record which invite a member joined with (NULL: the board's default code)
+ALTER TABLE public.board_members
+ADD COLUMN IF NOT EXISTS invite_id UUID REFERENCES public.board_invites(id) ON DELETE SET NULL;
+
+-- 3. RLS: owners and admins manage invites; only the owner can hand out admin invites
+-- (same rule as CreateBoardInvite)
+ALTER TABLE public.board_invites ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Admins can view invites" ON public.board_invites;
+CREATE POLICY "Admins can view invites"
+ON public.board_invites FOR SELECT
+USING ( public.board_role(board_id) IN ('owner', 'admin') );
+
+DROP POLICY IF EXISTS "Admins can create invites" ON public.board_invites;
+CREATE POLICY "Admins can create invites"
+ON public.board_invites FOR INSERT
+WITH CHECK (
+  public.board_role(board_id) IN ('owner', 'admin')
+  AND (role IN ('member', 'viewer') OR public.board_role(board_id) = 'owner')
+);
+
+DROP POLICY IF EXISTS "Admins can revoke invites" ON public.board_invites;
+CREATE POLICY "Admins can revoke invites"
+ON public.board_invites FOR UPDATE
+USING ( public.board_role(board_id) IN ('owner', 'admin') )
+WITH CHECK (
+  public.board_role(board_id) IN ('owner', 'admin')
+  -- admins may still revoke the owner's admin invites; a revoked invite cannot be redeemed
+  AND (role IN ('member', 'viewer') OR public.board_role(board_id) = 'owner' OR revoked_at IS NOT NULL)
+);
+
+-- 4. Owners and admins can update board metadata (invite code rotation, settings)
+DROP POLICY IF EXISTS "Admins can update boards" ON public.boards;
+CREATE POLICY "Admins can update boards"
+ON public.boards FOR UPDATE
+USING ( public.board_role(id) IN ('owner', 'admin') )
+WITH CHECK ( public.board_role(id) IN ('owner', 'admin') );
+
+-- Ownership only moves through transfer_board_ownership (SECURITY DEFINER, so current_user is
+-- the function owner there); direct API updates cannot rewrite boards.user_id.
+CREATE OR REPLACE FUNCTION public.keep_board_owner()
+RETURNS trigger AS $$
+BEGIN
+  IF NEW.user_id IS DISTINCT FROM OLD.user_id AND current_user IN ('authenticated', 'anon') THEN
+    RAISE EXCEPTION 'board owner can only change through transfer_board_ownership'
+      USING ERRCODE = '42501';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS boards_keep_owner ON public.boards;
+CREATE TRIGGER boards_keep_owner
+  BEFORE UPDATE OF user_id ON public.boards
+  FOR EACH ROW EXECUTE FUNCTION public.keep_board_owner();
+
+-- 5. Redeem an invite code for the calling user.
+-- Non-members cannot see boards or invites through RLS, so this runs as SECURITY DEFINER.
+-- status: 'joined', 'already_member' or 'invalid'
+CREATE OR REPLACE FUNCTION public.redeem_board_invite(p_code TEXT)
+RETURNS TABLE (status TEXT, board_id UUID, role TEXT, invite_id UUID) AS $$
+DECLARE
+  inv public.board_invites%ROWTYPE;
+  target_board UUID;
+  target_role TEXT := 'member';
+  target_invite UUID := NULL;
+BEGIN
+  IF auth.uid() IS NULL THEN
+    RETURN QUERY SELECT 'invalid'::TEXT, NULL::UUID, NULL::TEXT, NULL::UUID;
+    RETURN;
+  END IF;
+
+  -- Lock the invite row so concurrent joins cannot exceed max_uses
+  SELECT * INTO inv FROM public.board_invites i
+  WHERE i.code = p_code
+    AND i.revoked_at IS NULL
+    AND (i.expires_at IS NULL OR i.expires_at > now())
+    AND (i.max_uses IS NULL OR i.use_count < i.max_uses)
+  FOR UPDATE;
+
+  IF FOUND THEN
+    target_board := inv.board_id;
+    target_role := inv.role;
+    target_invite := inv.id;
+  ELSE
+    SELECT b.id INTO target_board FROM public.boards b WHERE b.invite_code = p_code;
+  END IF;
+
+  IF target_board IS NULL THEN
+    RETURN QUERY SELECT 'invalid'::TEXT, NULL::UUID, NULL::TEXT, NULL::UUID;
+    RETURN;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM public.boards b WHERE b.id = target_board AND b.user_id = auth.uid())
+     OR EXISTS (SELECT 1 FROM public.board_members m WHERE m.board_id = target_board AND m.user_id = auth.uid()) THEN
+    RETURN QUERY SELECT 'already_member'::TEXT, target_board, public.board_role(target_board), NULL::UUID;
+    RETURN;
+  END IF;
+
+  INSERT INTO public.board_members (board_id, user_id, role, invite_id)
+  VALUES (target_board, auth.uid(), target_role, target_invite);
+
+  IF target_invite IS NOT NULL THEN
+    UPDATE public.board_invites SET use_count = use_count + 1 WHERE id = target_invite;
+  END IF;
+
+  RETURN QUERY SELECT 'joined'::TEXT, target_board, target_role, target_invite;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
@@ -0,0 +1,73 @@
+-- Member lifecycle: remove member, leave board, transfer ownership.
+-- These change rows the caller cannot touch through RLS (other members' rows, boards.user_id),
+-- so they run as SECURITY DEFINER functions that check the caller's role themselves.
+-- p_policy decides what happens to tasks assigned to the departing user:
+--   'unassign' -> assigned_to = NULL, 'reassign' -> assigned_to = board owner, 'keep' -> untouched
+
+-- 1. Remove a member (owner/admin) or leave a board (the member itself)
+CREATE OR REPLACE FUNCTION public.remove_board_member(p_board UUID, p_user UUID, p_policy TEXT DEFAULT 'unassign')
+RETURNS TABLE (removed_user UUID, reassigned_tasks INTEGER) AS $$
+DECLARE
+  caller_role TEXT := public.board_role(p_board);
+  target_role TEXT;
+  owner_id UUID;
+  affected INTEGER := 0;
+BEGIN
+  SELECT b.user_id INTO owner_id FROM public.boards b WHERE b.id = p_board;
+  SELECT m.role INTO target_role FROM public.board_members m WHERE m.board_id = p_board AND m.user_id = p_user;
+
+  IF owner_id IS NULL OR target_role IS NULL THEN
+    RAISE EXCEPTION 'member not found' USING ERRCODE = 'P0002';
+  END IF;
+
+  IF p_user <> auth.uid() THEN
+    IF caller_role NOT IN ('owner', 'admin') OR (target_role = 'admin' AND caller_role <> 'owner') THEN
+      RAISE EXCEPTION 'not allowed' USING ERRCODE = '42501';
+    END IF;
+  END IF;
+
+  IF p_policy = 'reassign' THEN
+    UPDATE public.tasks SET assigned_to = owner_id WHERE board_id = p_board AND assigned_to = p_user;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  ELSIF p_policy = 'unassign' THEN
+    UPDATE public.tasks SET assigned_to = NULL WHERE board_id = p_board AND assigned_to = p_user;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  END IF;
+
+  DELETE FROM public.board_members WHERE board_id = p_board AND user_id = p_user;
+
+  RETURN QUERY SELECT p_user, affected;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- 2. Transfer ownership to an existing member; the previous owner stays on as admin
+CREATE OR REPLACE FUNCTION public.transfer_board_ownership(p_board UUID, p_new_owner UUID, p_policy TEXT DEFAULT 'keep')
+RETURNS TABLE (previous_owner UUID, new_owner UUID, reassigned_tasks INTEGER) AS $$
+DECLARE
+  old_owner UUID;
+  affected INTEGER := 0;
+BEGIN
+  SELECT b.user_id INTO old_owner FROM public.boards b WHERE b.id = p_board FOR UPDATE;
+
+  IF old_owner IS NULL OR old_owner <> auth.uid() THEN
+    RAISE EXCEPTION 'not allowed' USING ERRCODE = '42501';
+  END IF;
+  IF NOT EXISTS (SELECT 1 FROM public.board_members m WHERE m.board_id = p_board AND m.user_id = p_new_owner) THEN
+    RAISE EXCEPTION 'member not found' USING ERRCODE = 'P0002';
+  END IF;
+
+  UPDATE public.boards SET user_id = p_new_owner WHERE id = p_board;
+  DELETE FROM public.board_members WHERE board_id = p_board AND user_id = p_new_owner;
+  INSERT INTO public.board_members (board_id, user_id, role) VALUES (p_board, old_owner, 'admin');
+
+  IF p_policy = 'reassign' THEN
+    UPDATE public.tasks SET assigned_to = p_new_owner WHERE board_id = p_board AND assigned_to = old_owner;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  ELSIF p_policy = 'unassign' THEN
+    UPDATE public.tasks SET assigned_to = NULL WHERE board_id = p_board AND assigned_to = old_owner;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  END IF;
+
+  RETURN QUERY SELECT old_owner, p_new_owner, affected;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
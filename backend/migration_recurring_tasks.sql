@@ -0,0 +1,53 @@
+-- 1. Recurring task series. The rule is an iCalendar RRULE evaluated by the Go API (backend/rrule)
+-- in the series' IANA timezone, starting at dtstart. next_at is the occurrence of the next instance
+-- that has not been created yet; NULL once the rule is exhausted or the series is stopped.
+CREATE TABLE IF NOT EXISTS public.task_recurrences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    dtstart TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_at TIMESTAMP WITH TIME ZONE,
+    current_task_id UUID, -- latest instance; the template for the next one (FK added below)
+    instance_count INTEGER NOT NULL DEFAULT 1,
+    skipped_count INTEGER NOT NULL DEFAULT 0,
+    created_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    stopped_at TIMESTAMP WITH TIME ZONE
+);
+
+-- The generator polls for due series
+CREATE INDEX IF NOT EXISTS task_recurrences_due_idx ON public.task_recurrences (next_at)
+  WHERE stopped_at IS NULL AND next_at IS NOT NULL;
+
+-- 2. Each task instance knows its series and the occurrence it stands for.
+-- The unique index makes generation idempotent: the completion hook and the background
+-- generator may race, only one of them creates the instance.
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS recurrence_id UUID REFERENCES public.task_recurrences(id) ON DELETE SET NULL;
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMP WITH TIME ZONE;
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_recurrence_occurrence_key ON public.tasks (recurrence_id, occurrence_at);
+
+ALTER TABLE public.task_recurrences DROP CONSTRAINT IF EXISTS task_recurrences_current_task_fkey;
+ALTER TABLE public.task_recurrences
+ADD CONSTRAINT task_recurrences_current_task_fkey FOREIGN KEY (current_task_id) REFERENCES public.tasks(id) ON DELETE SET NULL;
+
+-- 3. RLS: board members see series; editors create, change, skip and stop them.
+-- The background generator uses the service role key and bypasses RLS.
+ALTER TABLE public.task_recurrences ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view recurrences" ON public.task_recurrences;
+CREATE POLICY "Board members can view recurrences"
+ON public.task_recurrences FOR SELECT
+USING ( public.board_role(board_id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Editors can create recurrences" ON public.task_recurrences;
+CREATE POLICY "Editors can create recurrences"
+ON public.task_recurrences FOR INSERT
+WITH CHECK ( public.board_role(board_id) IN ('owner', 'admin', 'member') AND created_by = auth.uid() );
+
+DROP POLICY IF EXISTS "Editors can update recurrences" ON public.task_recurrences;
+CREATE POLICY "Editors can update recurrences"
+ON public.task_recurrences FOR UPDATE
+USING ( public.board_role(board_id) IN ('owner', 'admin', 'member') )
+WITH CHECK ( public.board_role(board_id) IN ('owner', 'admin', 'member') );
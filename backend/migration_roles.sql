@@ -0,0 +1,80 @@
+-- 1. Add role to board_members (owner is always boards.user_id)
+ALTER TABLE public.board_members
+ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member';
+
+ALTER TABLE public.board_members DROP CONSTRAINT IF EXISTS board_members_role_check;
+ALTER TABLE public.board_members
+ADD CONSTRAINT board_members_role_check CHECK (role IN ('admin', 'member', 'viewer'));
+
+-- 2. Helper: caller's role on a board ('owner', 'admin', 'member', 'viewer' or NULL)
+-- SECURITY DEFINER so policies can read board_members without recursive RLS checks.
+CREATE OR REPLACE FUNCTION public.board_role(target_board uuid)
+RETURNS TEXT AS $$
+  SELECT CASE
+    WHEN EXISTS (SELECT 1 FROM public.boards b WHERE b.id = target_board AND b.user_id = auth.uid()) THEN 'owner'
+    ELSE (SELECT m.role FROM public.board_members m WHERE m.board_id = target_board AND m.user_id = auth.uid())
+  END;
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- 3. Tasks RLS based on board role (the Go API enforces the full matrix, this is the safety net)
+DROP POLICY IF EXISTS "Users can manage their own tasks" ON public.tasks;
+
+DROP POLICY IF EXISTS "Board members can view tasks" ON public.tasks;
+CREATE POLICY "Board members can view tasks"
+  ON public.tasks FOR SELECT
+  USING ( auth.uid() = user_id OR public.board_role(board_id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Editors can insert tasks" ON public.tasks;
+CREATE POLICY "Editors can insert tasks"
+  ON public.tasks FOR INSERT
+  WITH CHECK ( public.board_role(board_id) IN ('owner', 'admin', 'member') );
+
+DROP POLICY IF EXISTS "Editors can update tasks" ON public.tasks;
+CREATE POLICY "Editors can update tasks"
+  ON public.tasks FOR UPDATE
+  USING ( public.board_role(board_id) IN ('owner', 'admin', 'member') )
+  WITH CHECK ( public.board_role(board_id) IN ('owner', 'admin', 'member') );
+
+-- The creator is fixed: "delete own tasks" depends on it, so user_id cannot be rewritten.
+CREATE OR REPLACE FUNCTION public.keep_task_creator()
+RETURNS trigger AS $$
+BEGIN
+  IF NEW.user_id IS DISTINCT FROM OLD.user_id THEN
+    RAISE EXCEPTION 'task creator cannot be changed'
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_keep_creator ON public.tasks;
+CREATE TRIGGER tasks_keep_creator
+  BEFORE UPDATE OF user_id ON public.tasks
+  FOR EACH ROW EXECUTE FUNCTION public.keep_task_creator();
+
+DROP POLICY IF EXISTS "Admins or creators can delete tasks" ON public.tasks;
+CREATE POLICY "Admins or creators can delete tasks"
+  ON public.tasks FOR DELETE
+  USING (
+    public.board_role(board_id) IN ('owner', 'admin')
+    OR (auth.uid() = user_id AND public.board_role(board_id) = 'member')
+  );
+
+-- 4. Members of the same board can see each other (needed for GetBoardMembers)
+DROP POLICY IF EXISTS "Board members can view memberships" ON public.board_members;
+CREATE POLICY "Board members can view memberships"
+  ON public.board_members FOR SELECT
+  USING ( public.board_role(board_id) IS NOT NULL );
+
+-- 5. Owners and admins can change roles; only the owner can promote or demote admins
+DROP POLICY IF EXISTS "Admins can update memberships" ON public.board_members;
+CREATE POLICY "Admins can update memberships"
+  ON public.board_members FOR UPDATE
+  USING (
+    public.board_role(board_id) = 'owner'
+    OR (public.board_role(board_id) = 'admin' AND role IN ('member', 'viewer'))
+  )
+  WITH CHECK (
+    public.board_role(board_id) = 'owner'
+    OR (public.board_role(board_id) = 'admin' AND role IN ('member', 'viewer'))
+  );
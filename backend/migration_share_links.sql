@@ -0,0 +1,93 @@
+-- 1. Public read-only share links (only the SHA-256 hash of the token is stored)
+CREATE TABLE IF NOT EXISTS public.board_share_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE,
+    name TEXT,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS board_share_links_board_idx ON public.board_share_links (board_id);
+
+-- 2. RLS: only board owners manage share links
+ALTER TABLE public.board_share_links ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Owners can view share links" ON public.board_share_links;
+CREATE POLICY "Owners can view share links"
+ON public.board_share_links FOR SELECT
+USING ( public.board_role(board_id) = 'owner' );
+
+DROP POLICY IF EXISTS "Owners can create share links" ON public.board_share_links;
+CREATE POLICY "Owners can create share links"
+ON public.board_share_links FOR INSERT
+WITH CHECK ( public.board_role(board_id) = 'owner' AND created_by = auth.uid() );
+
+DROP POLICY IF EXISTS "Owners can revoke share links" ON public.board_share_links;
+CREATE POLICY "Owners can revoke share links"
+ON public.board_share_links FOR UPDATE
+USING ( public.board_role(board_id) = 'owner' );
+
+-- 3. Read a shared board for unauthenticated visitors (called with the anon key, looks up by hash only).
+-- Returns NULL for unknown, revoked or expired links. Only presentation fields are exposed:
+-- no user ids, emails, assignees or chat. last_used_at writes are throttled to once per minute.
+CREATE OR REPLACE FUNCTION public.shared_board(p_token_hash TEXT)
+RETURNS JSONB AS $$
+DECLARE
+  link public.board_share_links%ROWTYPE;
+  result JSONB;
+BEGIN
+  SELECT * INTO link FROM public.board_share_links l
+  WHERE l.token_hash = p_token_hash
+    AND l.revoked_at IS NULL
+    AND (l.expires_at IS NULL OR l.expires_at > now());
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  UPDATE public.board_share_links
+  SET last_used_at = now()
+  WHERE id = link.id AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+  SELECT jsonb_build_object(
+    'board', jsonb_build_object(
+      'title', b.title,
+      'type', b.type,
+      'description', b.description,
+      'color', b.color,
+      'workflow', b.workflow,
+      'archived', b.archived_at IS NOT NULL
+    ),
+    'tasks', COALESCE((
+      SELECT jsonb_agg(jsonb_build_object(
+        'id', t.id,
+        'title', t.title,
+        'description', t.description,
+        'status', t.status,
+        'priority', t.priority,
+        'due_date', t.due_date,
+        'position', t.position,
+        'subtasks', COALESCE((
+          SELECT jsonb_agg(jsonb_build_object(
+            'id', s.id,
+            'title', s.title,
+            'is_completed', s.is_completed,
+            'position', s.position
+          ) ORDER BY s.position)
+          FROM public.subtasks s WHERE s.task_id = t.id
+        ), '[]'::jsonb)
+      ) ORDER BY t.position)
+      FROM public.tasks t WHERE t.board_id = b.id
+    ), '[]'::jsonb),
+    'expires_at', link.expires_at
+  ) INTO result
+  FROM public.boards b
+  WHERE b.id = link.board_id;
+
+  RETURN result;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
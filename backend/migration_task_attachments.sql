@@ -0,0 +1,63 @@
+-- 1. Task attachments. Only metadata lives here; the file itself is in the blob store
+-- (local disk or S3-compatible, see backend/storage) under storage_key.
+CREATE TABLE IF NOT EXISTS public.task_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES public.tasks(id) ON DELETE CASCADE,
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE, -- copied from the task (for RLS)
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL, -- sniffed from the content, not the client's claim
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS task_attachments_task_idx ON public.task_attachments (task_id, created_at);
+CREATE INDEX IF NOT EXISTS task_attachments_board_idx ON public.task_attachments (board_id);
+
+-- task_attachments.user_id -> profiles for uploader email embedding
+ALTER TABLE public.task_attachments DROP CONSTRAINT IF EXISTS task_attachments_user_id_profiles_fkey;
+ALTER TABLE public.task_attachments
+ADD CONSTRAINT task_attachments_user_id_profiles_fkey FOREIGN KEY (user_id) REFERENCES public.profiles(id) ON DELETE CASCADE;
+
+-- 2. Board follows the task; no uploads on archived boards
+CREATE OR REPLACE FUNCTION public.prepare_task_attachment()
+RETURNS trigger AS $$
+BEGIN
+  SELECT t.board_id INTO NEW.board_id FROM public.tasks t WHERE t.id = NEW.task_id;
+  IF EXISTS (SELECT 1 FROM public.boards b WHERE b.id = NEW.board_id AND b.archived_at IS NOT NULL) THEN
+    RAISE EXCEPTION 'board % is archived', NEW.board_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_attachments_prepare ON public.task_attachments;
+CREATE TRIGGER task_attachments_prepare
+  BEFORE INSERT ON public.task_attachments
+  FOR EACH ROW EXECUTE FUNCTION public.prepare_task_attachment();
+
+-- 3. Attachment count on tasks: PostgREST computed column (tasks?select=*,attachment_count)
+CREATE OR REPLACE FUNCTION public.attachment_count(public.tasks)
+RETURNS BIGINT AS $$
+  SELECT count(*) FROM public.task_attachments a WHERE a.task_id = $1.id;
+$$ LANGUAGE sql STABLE;
+
+-- 4. RLS: board members see and download; editors upload; uploaders, owners and admins delete
+ALTER TABLE public.task_attachments ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view attachments" ON public.task_attachments;
+CREATE POLICY "Board members can view attachments"
+ON public.task_attachments FOR SELECT
+USING ( public.board_role(board_id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Editors can upload attachments" ON public.task_attachments;
+CREATE POLICY "Editors can upload attachments"
+ON public.task_attachments FOR INSERT
+WITH CHECK ( auth.uid() = user_id AND public.board_role(board_id) IN ('owner', 'admin', 'member') );
+
+DROP POLICY IF EXISTS "Uploaders and admins can delete attachments" ON public.task_attachments;
+CREATE POLICY "Uploaders and admins can delete attachments"
+ON public.task_attachments FOR DELETE
+USING ( auth.uid() = user_id OR public.board_role(board_id) IN ('owner', 'admin') );
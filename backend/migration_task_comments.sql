@@ -0,0 +1,166 @@
+-- 1. Task comments. Replies point to a top-level comment of the same task (one level of threading).
+-- Deleting a comment that has replies keeps a placeholder (deleted_at set, body cleared) so the thread stays intact.
+CREATE TABLE IF NOT EXISTS public.task_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES public.tasks(id) ON DELETE CASCADE,
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE, -- copied from the task (for RLS and realtime)
+    parent_id UUID REFERENCES public.task_comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    body TEXT NOT NULL,
+    mentions UUID[] NOT NULL DEFAULT '{}', -- mentioned board members (resolved from @email)
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS task_comments_task_idx ON public.task_comments (task_id, created_at);
+CREATE INDEX IF NOT EXISTS task_comments_parent_idx ON public.task_comments (parent_id) WHERE parent_id IS NOT NULL;
+
+-- task_comments.user_id -> profiles for author email embedding (same as board_members)
+ALTER TABLE public.task_comments DROP CONSTRAINT IF EXISTS task_comments_user_id_profiles_fkey;
+ALTER TABLE public.task_comments
+ADD CONSTRAINT task_comments_user_id_profiles_fkey FOREIGN KEY (user_id) REFERENCES public.profiles(id) ON DELETE CASCADE;
+
+-- 2. Edit history: previous bodies, written by the trigger below
+CREATE TABLE IF NOT EXISTS public.task_comment_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES public.task_comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_by UUID REFERENCES auth.users(id) ON DELETE SET NULL,
+    edited_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS task_comment_edits_comment_idx ON public.task_comment_edits (comment_id, edited_at);
+
+-- 3. Integrity: board follows the task, replies stay on the same task, no writes on archived boards
+CREATE OR REPLACE FUNCTION public.prepare_task_comment()
+RETURNS trigger AS $$
+DECLARE
+  parent public.task_comments%ROWTYPE;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    SELECT t.board_id INTO NEW.board_id FROM public.tasks t WHERE t.id = NEW.task_id;
+    IF NEW.parent_id IS NOT NULL THEN
+      SELECT * INTO parent FROM public.task_comments WHERE id = NEW.parent_id;
+      IF parent.id IS NULL OR parent.task_id <> NEW.task_id OR parent.parent_id IS NOT NULL THEN
+        RAISE EXCEPTION 'parent comment % is not a top-level comment of task %', NEW.parent_id, NEW.task_id
+          USING ERRCODE = 'check_violation';
+      END IF;
+    END IF;
+  ELSE
+    -- task, board, parent and author never change
+    NEW.task_id := OLD.task_id;
+    NEW.board_id := OLD.board_id;
+    NEW.parent_id := OLD.parent_id;
+    NEW.user_id := OLD.user_id;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM public.boards b WHERE b.id = NEW.board_id AND b.archived_at IS NOT NULL) THEN
+    RAISE EXCEPTION 'board % is archived', NEW.board_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_comments_prepare ON public.task_comments;
+CREATE TRIGGER task_comments_prepare
+  BEFORE INSERT OR UPDATE ON public.task_comments
+  FOR EACH ROW EXECUTE FUNCTION public.prepare_task_comment();
+
+-- Keeps the previous body on edit; deleting the comment also drops its history.
+-- SECURITY DEFINER: task_comment_edits has no insert/delete policies.
+CREATE OR REPLACE FUNCTION public.record_task_comment_edit()
+RETURNS trigger AS $$
+BEGIN
+  IF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+    DELETE FROM public.task_comment_edits WHERE comment_id = OLD.id;
+  ELSIF NEW.body IS DISTINCT FROM OLD.body THEN
+    INSERT INTO public.task_comment_edits (comment_id, body, edited_by)
+    VALUES (OLD.id, OLD.body, auth.uid());
+    NEW.edited_at := timezone('utc'::text, now());
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+DROP TRIGGER IF EXISTS task_comments_record_edit ON public.task_comments;
+CREATE TRIGGER task_comments_record_edit
+  BEFORE UPDATE ON public.task_comments
+  FOR EACH ROW EXECUTE FUNCTION public.record_task_comment_edit();
+
+-- 4. Comment count on tasks: PostgREST computed column (tasks?select=*,comment_count)
+CREATE OR REPLACE FUNCTION public.comment_count(public.tasks)
+RETURNS BIGINT AS $$
+  SELECT count(*) FROM public.task_comments c WHERE c.task_id = $1.id AND c.deleted_at IS NULL;
+$$ LANGUAGE sql STABLE;
+
+-- 5. People who can be mentioned on a board (owner, board members, workspace members) with their
+-- notification level for the board. Only callable by someone who can see the board.
+CREATE OR REPLACE FUNCTION public.board_people(p_board UUID)
+RETURNS TABLE (user_id UUID, email TEXT, notification_level TEXT) AS $$
+BEGIN
+  IF public.board_role(p_board) IS NULL THEN
+    RAISE EXCEPTION 'not a member of board %', p_board
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+  RETURN QUERY
+  SELECT p.id, p.email, COALESCE(bp.notification_level, 'all')
+  FROM (
+    SELECT b.user_id AS id FROM public.boards b WHERE b.id = p_board
+    UNION
+    SELECT m.user_id FROM public.board_members m WHERE m.board_id = p_board
+    UNION
+    SELECT wm.user_id FROM public.workspace_members wm
+    JOIN public.boards b ON b.workspace_id = wm.workspace_id
+    WHERE b.id = p_board
+  ) people
+  JOIN public.profiles p ON p.id = people.id
+  LEFT JOIN public.board_preferences bp ON bp.user_id = people.id AND bp.board_id = p_board;
+END;
+$$ LANGUAGE plpgsql STABLE SECURITY DEFINER SET search_path = public;
+
+-- 6. RLS: board members read; editors (owner, admin, member) comment; authors edit the body while they
+-- can still comment; authors, owners and admins delete (owners and admins only via a soft delete
+-- that clears the body)
+ALTER TABLE public.task_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.task_comment_edits ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view comments" ON public.task_comments;
+CREATE POLICY "Board members can view comments"
+ON public.task_comments FOR SELECT
+USING ( public.board_role(board_id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Editors can comment" ON public.task_comments;
+CREATE POLICY "Editors can comment"
+ON public.task_comments FOR INSERT
+WITH CHECK ( auth.uid() = user_id AND public.board_role(board_id) IN ('owner', 'admin', 'member') );
+
+DROP POLICY IF EXISTS "Authors and admins can update comments" ON public.task_comments;
+DROP POLICY IF EXISTS "Authors can update comments" ON public.task_comments;
+CREATE POLICY "Authors can update comments"
+ON public.task_comments FOR UPDATE
+USING ( auth.uid() = user_id AND public.board_role(board_id) IN ('owner', 'admin', 'member') )
+WITH CHECK ( auth.uid() = user_id AND public.board_role(board_id) IN ('owner', 'admin', 'member') );
+
+-- Owners and admins cannot rewrite someone else's comment; they can only turn it into a placeholder
+DROP POLICY IF EXISTS "Admins can soft-delete comments" ON public.task_comments;
+CREATE POLICY "Admins can soft-delete comments"
+ON public.task_comments FOR UPDATE
+USING ( public.board_role(board_id) IN ('owner', 'admin') )
+WITH CHECK (
+  public.board_role(board_id) IN ('owner', 'admin')
+  AND deleted_at IS NOT NULL
+  AND body = ''
+  AND mentions = '{}'
+);
+
+DROP POLICY IF EXISTS "Authors and admins can delete comments" ON public.task_comments;
+CREATE POLICY "Authors and admins can delete comments"
+ON public.task_comments FOR DELETE
+USING ( auth.uid() = user_id OR public.board_role(board_id) IN ('owner', 'admin') );
+
+DROP POLICY IF EXISTS "Board members can view comment history" ON public.task_comment_edits;
+CREATE POLICY "Board members can view comment history"
+ON public.task_comment_edits FOR SELECT
+USING ( EXISTS (SELECT 1 FROM public.task_comments c WHERE c.id = comment_id AND public.board_role(c.board_id) IS NOT NULL) );
@@ -0,0 +1,134 @@
+-- 1. Task dependencies: blocker_id blocks blocked_id. Tasks may be on different boards.
+CREATE TABLE IF NOT EXISTS public.task_dependencies (
+    blocker_id UUID NOT NULL REFERENCES public.tasks(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES public.tasks(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_dependencies_blocked_idx ON public.task_dependencies (blocked_id);
+
+-- 2. Whether a status is in a done-category column of the board's workflow.
+-- Boards created before templates (empty workflow) use the built-in templates, whose done column is "Done".
+CREATE OR REPLACE FUNCTION public.task_status_done(p_board uuid, p_status text)
+RETURNS BOOLEAN AS $$
+  SELECT CASE
+    WHEN jsonb_array_length(COALESCE(b.workflow->'statuses', '[]'::jsonb)) = 0 THEN p_status = 'Done'
+    ELSE EXISTS (
+      SELECT 1 FROM jsonb_array_elements(b.workflow->'statuses') AS s
+      WHERE s->>'key' = p_status AND s->>'category' = 'done'
+    )
+  END
+  FROM public.boards b WHERE b.id = p_board;
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- 3. Cycle detection: adding blocker -> blocked must not close a loop, i.e. the blocker must not
+-- already (transitively) depend on the blocked task. The advisory lock serializes inserts so two
+-- concurrent links cannot form a cycle together. Archived boards are read-only.
+CREATE OR REPLACE FUNCTION public.check_task_dependency()
+RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_advisory_xact_lock(hashtext('task_dependencies'));
+
+  IF EXISTS (
+    SELECT 1 FROM public.tasks t JOIN public.boards b ON b.id = t.board_id
+    WHERE t.id IN (NEW.blocker_id, NEW.blocked_id) AND b.archived_at IS NOT NULL
+  ) THEN
+    RAISE EXCEPTION 'board is archived'
+      USING ERRCODE = 'check_violation';
+  END IF;
+
+  IF EXISTS (
+    WITH RECURSIVE upstream(id) AS (
+      SELECT d.blocker_id FROM public.task_dependencies d WHERE d.blocked_id = NEW.blocker_id
+      UNION
+      SELECT d.blocker_id FROM public.task_dependencies d JOIN upstream u ON d.blocked_id = u.id
+    )
+    SELECT 1 FROM upstream WHERE id = NEW.blocked_id
+  ) THEN
+    RAISE EXCEPTION 'dependency cycle: task % already depends on task %', NEW.blocker_id, NEW.blocked_id
+      USING ERRCODE = 'check_violation', HINT = 'dependency_cycle';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+DROP TRIGGER IF EXISTS task_dependencies_check ON public.task_dependencies;
+CREATE TRIGGER task_dependencies_check
+  BEFORE INSERT ON public.task_dependencies
+  FOR EACH ROW EXECUTE FUNCTION public.check_task_dependency();
+
+-- 4. Open blockers of a task, including those on boards the caller cannot read
+-- (their title is hidden). Used by the API to block moves into done columns.
+-- Only callable by someone who can see the task's board.
+CREATE OR REPLACE FUNCTION public.open_blockers(p_task uuid)
+RETURNS TABLE (id uuid, board_id uuid, title text, status text) AS $$
+BEGIN
+  IF public.board_role((SELECT t.board_id FROM public.tasks t WHERE t.id = p_task)) IS NULL THEN
+    RAISE EXCEPTION 'not a member of the board of task %', p_task
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+  RETURN QUERY
+  SELECT t.id, t.board_id,
+         CASE WHEN public.board_role(t.board_id) IS NOT NULL THEN t.title END,
+         t.status
+  FROM public.task_dependencies d
+  JOIN public.tasks t ON t.id = d.blocker_id
+  WHERE d.blocked_id = p_task
+    AND NOT COALESCE(public.task_status_done(t.board_id, t.status), false)
+  ORDER BY t.title;
+END;
+$$ LANGUAGE plpgsql STABLE SECURITY DEFINER SET search_path = public;
+
+-- 5. Blocked indicator on tasks: PostgREST computed column (tasks?select=*,blocked:is_blocked)
+CREATE OR REPLACE FUNCTION public.is_blocked(public.tasks)
+RETURNS BOOLEAN AS $$
+  SELECT EXISTS (
+    SELECT 1 FROM public.task_dependencies d
+    JOIN public.tasks t ON t.id = d.blocker_id
+    WHERE d.blocked_id = $1.id
+      AND NOT COALESCE(public.task_status_done(t.board_id, t.status), false)
+  );
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- 6. RLS: a link is visible to readers of either task's board. Creating one needs read access to both
+-- tasks and edit rights on the blocked task's board (the link restricts that task); editors of either
+-- board may remove it.
+ALTER TABLE public.task_dependencies ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view dependencies" ON public.task_dependencies;
+CREATE POLICY "Board members can view dependencies"
+ON public.task_dependencies FOR SELECT
+USING (
+  EXISTS (
+    SELECT 1 FROM public.tasks t
+    WHERE t.id IN (blocker_id, blocked_id) AND public.board_role(t.board_id) IS NOT NULL
+  )
+);
+
+DROP POLICY IF EXISTS "Editors can add dependencies" ON public.task_dependencies;
+CREATE POLICY "Editors can add dependencies"
+ON public.task_dependencies FOR INSERT
+WITH CHECK (
+  created_by = auth.uid()
+  AND (
+    SELECT count(*) FROM public.tasks t
+    WHERE t.id IN (blocker_id, blocked_id) AND public.board_role(t.board_id) IS NOT NULL
+  ) = 2
+  AND EXISTS (
+    SELECT 1 FROM public.tasks t
+    WHERE t.id = blocked_id AND public.board_role(t.board_id) IN ('owner', 'admin', 'member')
+  )
+);
+
+DROP POLICY IF EXISTS "Editors can remove dependencies" ON public.task_dependencies;
+CREATE POLICY "Editors can remove dependencies"
+ON public.task_dependencies FOR DELETE
+USING (
+  EXISTS (
+    SELECT 1 FROM public.tasks t
+    WHERE t.id IN (blocker_id, blocked_id) AND public.board_role(t.board_id) IN ('owner', 'admin', 'member')
+  )
+);
@@ -0,0 +1,122 @@
+-- 1. Task <-> label (many-to-many). Labels are board-scoped (board_labels, see migration_templates.sql);
+-- a task can only carry labels of its own board.
+CREATE TABLE IF NOT EXISTS public.task_labels (
+    task_id UUID NOT NULL REFERENCES public.tasks(id) ON DELETE CASCADE,
+    label_id UUID NOT NULL REFERENCES public.board_labels(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_labels_label_idx ON public.task_labels (label_id);
+
+CREATE OR REPLACE FUNCTION public.check_task_label_board()
+RETURNS trigger AS $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM public.tasks t
+    JOIN public.board_labels l ON l.board_id = t.board_id
+    WHERE t.id = NEW.task_id AND l.id = NEW.label_id
+  ) THEN
+    RAISE EXCEPTION 'label % does not belong to the board of task %', NEW.label_id, NEW.task_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+  IF EXISTS (
+    SELECT 1 FROM public.tasks t JOIN public.boards b ON b.id = t.board_id
+    WHERE t.id = NEW.task_id AND b.archived_at IS NOT NULL
+  ) THEN
+    RAISE EXCEPTION 'board of task % is archived', NEW.task_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_labels_check_board ON public.task_labels;
+CREATE TRIGGER task_labels_check_board
+  BEFORE INSERT OR UPDATE ON public.task_labels
+  FOR EACH ROW EXECUTE FUNCTION public.check_task_label_board();
+
+-- 2. Replace the label set of a task in one transaction
+CREATE OR REPLACE FUNCTION public.set_task_labels(p_task UUID, p_labels UUID[])
+RETURNS SETOF public.task_labels AS $$
+BEGIN
+  DELETE FROM public.task_labels WHERE task_id = p_task AND NOT (label_id = ANY(p_labels));
+  INSERT INTO public.task_labels (task_id, label_id)
+  SELECT p_task, l FROM unnest(p_labels) AS l
+  ON CONFLICT DO NOTHING;
+  RETURN QUERY SELECT * FROM public.task_labels WHERE task_id = p_task;
+END;
+$$ LANGUAGE plpgsql;
+
+-- 3. RLS: same rules as board_labels - members see, editors change
+ALTER TABLE public.task_labels ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view task labels" ON public.task_labels;
+CREATE POLICY "Board members can view task labels"
+ON public.task_labels FOR SELECT
+USING ( EXISTS (SELECT 1 FROM public.tasks t WHERE t.id = task_id AND public.board_role(t.board_id) IS NOT NULL) );
+
+DROP POLICY IF EXISTS "Editors can manage task labels" ON public.task_labels;
+CREATE POLICY "Editors can manage task labels"
+ON public.task_labels FOR ALL
+USING ( EXISTS (SELECT 1 FROM public.tasks t WHERE t.id = task_id AND public.board_role(t.board_id) IN ('owner', 'admin', 'member')) )
+WITH CHECK ( EXISTS (SELECT 1 FROM public.tasks t WHERE t.id = task_id AND public.board_role(t.board_id) IN ('owner', 'admin', 'member')) );
+
+-- 4. Imports can link tasks to board labels by name ("labels": ["bug", ...]).
+-- Links are only added, so labels set in the app survive a re-import.
+CREATE OR REPLACE FUNCTION public.upsert_external_tasks(p_board UUID, p_tasks JSONB)
+RETURNS JSONB AS $$
+DECLARE
+  t JSONB;
+  s JSONB;
+  v_task UUID;
+  inserted BOOLEAN;
+  created INTEGER := 0;
+  updated INTEGER := 0;
+  sub_created INTEGER := 0;
+  sub_updated INTEGER := 0;
+BEGIN
+  FOR t IN SELECT * FROM jsonb_array_elements(p_tasks) LOOP
+    INSERT INTO public.tasks (board_id, external_ref, title, description, status, priority, due_date, position, assigned_to)
+    SELECT p_board, r.external_ref, r.title, r.description, r.status, r.priority, r.due_date, r.position, r.assigned_to
+    FROM jsonb_populate_record(NULL::public.tasks, t - 'subtasks' - 'labels') AS r
+    ON CONFLICT ON CONSTRAINT tasks_board_external_ref_key DO UPDATE SET
+      title = EXCLUDED.title,
+      description = EXCLUDED.description,
+      status = EXCLUDED.status,
+      priority = EXCLUDED.priority,
+      due_date = EXCLUDED.due_date,
+      position = EXCLUDED.position,
+      assigned_to = COALESCE(EXCLUDED.assigned_to, public.tasks.assigned_to)
+    RETURNING id, (xmax = 0) INTO v_task, inserted;
+
+    IF inserted THEN created := created + 1; ELSE updated := updated + 1; END IF;
+
+    INSERT INTO public.task_labels (task_id, label_id)
+    SELECT v_task, l.id FROM public.board_labels l
+    WHERE l.board_id = p_board
+      AND l.name IN (SELECT jsonb_array_elements_text(COALESCE(t->'labels', '[]'::jsonb)))
+    ON CONFLICT DO NOTHING;
+
+    FOR s IN SELECT * FROM jsonb_array_elements(COALESCE(t->'subtasks', '[]'::jsonb)) LOOP
+      INSERT INTO public.subtasks (task_id, external_ref, title, is_completed, position)
+      SELECT v_task, r.external_ref, r.title, COALESCE(r.is_completed, false), COALESCE(r.position, 0)
+      FROM jsonb_populate_record(NULL::public.subtasks, s) AS r
+      ON CONFLICT ON CONSTRAINT subtasks_task_external_ref_key DO UPDATE SET
+        title = EXCLUDED.title,
+        is_completed = EXCLUDED.is_completed,
+        position = EXCLUDED.position
+      RETURNING (xmax = 0) INTO inserted;
+
+      IF inserted THEN sub_created := sub_created + 1; ELSE sub_updated := sub_updated + 1; END IF;
+    END LOOP;
+  END LOOP;
+
+  RETURN jsonb_build_object(
+    'created', created,
+    'updated', updated,
+    'subtasks_created', sub_created,
+    'subtasks_updated', sub_updated
+  );
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,91 @@
+-- 1. Board workflow (columns/statuses) and settings, filled from the template at creation
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS workflow JSONB NOT NULL DEFAULT '{"statuses": []}'::jsonb;
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- 2. Tasks carry a priority (Low, Medium, High) - used by the frontend but missing from schema.sql
+ALTER TABLE public.tasks ADD COLUMN IF NOT EXISTS priority TEXT;
+
+-- 3. Board-scoped labels
+CREATE TABLE IF NOT EXISTS public.board_labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL DEFAULT '#6b7280',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    UNIQUE (board_id, name)
+);
+
+ALTER TABLE public.board_labels ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view labels" ON public.board_labels;
+CREATE POLICY "Board members can view labels"
+ON public.board_labels FOR SELECT
+USING ( public.board_role(board_id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Editors can manage labels" ON public.board_labels;
+CREATE POLICY "Editors can manage labels"
+ON public.board_labels FOR ALL
+USING ( public.board_role(board_id) IN ('owner', 'admin', 'member') )
+WITH CHECK ( public.board_role(board_id) IN ('owner', 'admin', 'member') );
+
+-- 4. Custom templates saved by users
+CREATE TABLE IF NOT EXISTS public.board_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    name TEXT NOT NULL,
+    description TEXT,
+    definition JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+ALTER TABLE public.board_templates ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Users can manage their own templates" ON public.board_templates;
+CREATE POLICY "Users can manage their own templates"
+ON public.board_templates FOR ALL
+USING ( auth.uid() = user_id )
+WITH CHECK ( auth.uid() = user_id );
+
+-- 5. Create a board and everything the template provisions in a single transaction.
+-- p_definition: {"workflow": {...}, "settings": {...}, "labels": [{name, color}],
+--                "tasks": [{title, description, status, priority, subtasks: [title]}]}
+CREATE OR REPLACE FUNCTION public.create_board_from_template(p_title TEXT, p_type TEXT, p_definition JSONB)
+RETURNS SETOF public.boards AS $$
+DECLARE
+  new_board public.boards%ROWTYPE;
+  t JSONB;
+  new_task UUID;
+  task_pos INTEGER := 0;
+  sub_pos INTEGER;
+  sub TEXT;
+BEGIN
+  INSERT INTO public.boards (title, type, workflow, settings)
+  VALUES (
+    p_title,
+    p_type,
+    COALESCE(p_definition->'workflow', '{"statuses": []}'::jsonb),
+    COALESCE(p_definition->'settings', '{}'::jsonb)
+  )
+  RETURNING * INTO new_board;
+
+  INSERT INTO public.board_labels (board_id, name, color)
+  SELECT new_board.id, l->>'name', COALESCE(l->>'color', '#6b7280')
+  FROM jsonb_array_elements(COALESCE(p_definition->'labels', '[]'::jsonb)) AS l;
+
+  FOR t IN SELECT * FROM jsonb_array_elements(COALESCE(p_definition->'tasks', '[]'::jsonb)) LOOP
+    INSERT INTO public.tasks (board_id, title, description, status, priority, position)
+    VALUES (new_board.id, t->>'title', t->>'description', t->>'status', t->>'priority', task_pos)
+    RETURNING id INTO new_task;
+    task_pos := task_pos + 1;
+
+    sub_pos := 0;
+    FOR sub IN SELECT * FROM jsonb_array_elements_text(COALESCE(t->'subtasks', '[]'::jsonb)) LOOP
+      INSERT INTO public.subtasks (task_id, title, is_completed, position)
+      VALUES (new_task, sub, false, sub_pos);
+      sub_pos := sub_pos + 1;
+    END LOOP;
+  END LOOP;
+
+  RETURN NEXT new_board;
+END;
+$$ LANGUAGE plpgsql;
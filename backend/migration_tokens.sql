@@ -0,0 +1,53 @@
+-- 1. Personal access tokens (only the SHA-256 hash of the token is stored)
+CREATE TABLE IF NOT EXISTS public.personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    CONSTRAINT personal_access_tokens_scopes_check
+        CHECK (scopes <@ ARRAY['read:tasks', 'write:tasks', 'chat', 'admin:board']::TEXT[])
+);
+
+-- 2. RLS: users manage only their own tokens
+ALTER TABLE public.personal_access_tokens ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Users can view their own tokens" ON public.personal_access_tokens;
+CREATE POLICY "Users can view their own tokens"
+ON public.personal_access_tokens FOR SELECT
+USING (auth.uid() = user_id);
+
+DROP POLICY IF EXISTS "Users can create their own tokens" ON public.personal_access_tokens;
+CREATE POLICY "Users can create their own tokens"
+ON public.personal_access_tokens FOR INSERT
+WITH CHECK (auth.uid() = user_id);
+
+DROP POLICY IF EXISTS "Users can revoke their own tokens" ON public.personal_access_tokens;
+CREATE POLICY "Users can revoke their own tokens"
+ON public.personal_access_tokens FOR UPDATE
+USING (auth.uid() = user_id);
+
+-- 3. Verification for the backend (called with the anon key, looks up by hash only).
+-- Also tracks last use; writes are throttled to once per minute per token.
+CREATE OR REPLACE FUNCTION public.verify_personal_access_token(p_token_hash TEXT)
+RETURNS TABLE (user_id UUID, email TEXT, scopes TEXT[], expires_at TIMESTAMP WITH TIME ZONE) AS $$
+BEGIN
+  UPDATE public.personal_access_tokens t
+  SET last_used_at = now()
+  WHERE t.token_hash = p_token_hash
+    AND (t.last_used_at IS NULL OR t.last_used_at < now() - interval '1 minute');
+
+  RETURN QUERY
+  SELECT t.user_id, p.email, t.scopes, t.expires_at
+  FROM public.personal_access_tokens t
+  LEFT JOIN public.profiles p ON p.id = t.user_id
+  WHERE t.token_hash = p_token_hash
+    AND t.revoked_at IS NULL
+    AND (t.expires_at IS NULL OR t.expires_at > now());
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
@@ -0,0 +1,27 @@
+-- WIP limits live in boards.workflow (statuses[].wip_limit, statuses[].assignee_wip_limit).
+-- Owners may exceed a limit with an explicit override; every override is recorded here.
+CREATE TABLE IF NOT EXISTS public.wip_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id UUID NOT NULL REFERENCES public.boards(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(),
+    status TEXT NOT NULL,
+    assignee UUID REFERENCES auth.users(id) ON DELETE SET NULL, -- set when the per-assignee limit was exceeded
+    wip_limit INTEGER NOT NULL,
+    current_count INTEGER NOT NULL,
+    task_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS wip_overrides_board_idx ON public.wip_overrides (board_id, created_at DESC);
+
+ALTER TABLE public.wip_overrides ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Board members can view WIP overrides" ON public.wip_overrides;
+CREATE POLICY "Board members can view WIP overrides"
+ON public.wip_overrides FOR SELECT
+USING ( public.board_role(board_id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Owners can record WIP overrides" ON public.wip_overrides;
+CREATE POLICY "Owners can record WIP overrides"
+ON public.wip_overrides FOR INSERT
+WITH CHECK ( public.board_role(board_id) = 'owner' AND user_id = auth.uid() );
@@ -0,0 +1,27 @@
+-- 1. Statuses come from the board's workflow; the API fills in the first column when none is given
+ALTER TABLE public.tasks ALTER COLUMN status DROP DEFAULT;
+
+-- 2. Safety net: a task's status must be one of its board's columns.
+-- Boards created before templates (empty workflow) are not checked. Transitions are enforced by the Go API.
+CREATE OR REPLACE FUNCTION public.check_task_status()
+RETURNS trigger AS $$
+DECLARE
+  wf JSONB;
+BEGIN
+  SELECT workflow INTO wf FROM public.boards WHERE id = NEW.board_id;
+  IF wf IS NULL OR jsonb_array_length(COALESCE(wf->'statuses', '[]'::jsonb)) = 0 THEN
+    RETURN NEW;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM jsonb_array_elements(wf->'statuses') AS s WHERE s->>'key' = NEW.status) THEN
+    RAISE EXCEPTION 'status "%" is not a column of board %', NEW.status, NEW.board_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_check_status ON public.tasks;
+CREATE TRIGGER tasks_check_status
+  BEFORE INSERT OR UPDATE OF status, board_id ON public.tasks
+  FOR EACH ROW EXECUTE FUNCTION public.check_task_status();
@@ -0,0 +1,337 @@
+-- 1. Workspaces own boards; their members get access to every board in the workspace.
+-- Each user has one personal workspace (personal = true) that cannot be deleted.
+CREATE TABLE IF NOT EXISTS public.workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    owner_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE DEFAULT auth.uid(), -- creator
+    personal BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS workspaces_personal_key ON public.workspaces (owner_id) WHERE personal;
+
+-- Workspace roles: owner, admin, member, viewer.
+-- On the workspace's boards owners/admins act as board admins, members as members, viewers as viewers.
+CREATE TABLE IF NOT EXISTS public.workspace_members (
+    workspace_id UUID NOT NULL REFERENCES public.workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member', 'viewer')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT timezone('utc'::text, now()) NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS workspace_members_user_idx ON public.workspace_members (user_id);
+
+-- workspace_members.user_id -> profiles for email embedding (same as board_members)
+ALTER TABLE public.workspace_members DROP CONSTRAINT IF EXISTS workspace_members_user_id_profiles_fkey;
+ALTER TABLE public.workspace_members
+ADD CONSTRAINT workspace_members_user_id_profiles_fkey FOREIGN KEY (user_id) REFERENCES public.profiles(id) ON DELETE CASCADE;
+
+ALTER TABLE public.boards ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES public.workspaces(id);
+CREATE INDEX IF NOT EXISTS boards_workspace_idx ON public.boards (workspace_id);
+
+-- 2. Helpers (SECURITY DEFINER so policies can use them without recursive RLS checks)
+CREATE OR REPLACE FUNCTION public.workspace_role(target_workspace uuid)
+RETURNS TEXT AS $$
+  SELECT m.role FROM public.workspace_members m
+  WHERE m.workspace_id = target_workspace AND m.user_id = auth.uid();
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+CREATE OR REPLACE FUNCTION public.role_rank(r TEXT)
+RETURNS INTEGER AS $$
+  SELECT CASE r WHEN 'owner' THEN 4 WHEN 'admin' THEN 3 WHEN 'member' THEN 2 WHEN 'viewer' THEN 1 ELSE 0 END;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Board role now also comes from the board's workspace; the stronger of the two wins.
+-- Board ownership (boards.user_id) is still the only way to be 'owner' on a board.
+CREATE OR REPLACE FUNCTION public.board_role(target_board uuid)
+RETURNS TEXT AS $$
+  SELECT CASE
+    WHEN b.user_id = auth.uid() THEN 'owner'
+    ELSE (
+      SELECT r FROM (
+        SELECT m.role AS r FROM public.board_members m
+        WHERE m.board_id = b.id AND m.user_id = auth.uid()
+        UNION ALL
+        SELECT CASE wm.role WHEN 'owner' THEN 'admin' ELSE wm.role END FROM public.workspace_members wm
+        WHERE wm.workspace_id = b.workspace_id AND wm.user_id = auth.uid()
+      ) roles
+      ORDER BY public.role_rank(r) DESC
+      LIMIT 1
+    )
+  END
+  FROM public.boards b
+  WHERE b.id = target_board;
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- Personal workspace of a user, created on first use.
+-- Called directly (RPC) it only works for the caller; the board trigger below (pg_trigger_depth() > 0)
+-- and the migration (no auth.uid()) may pass another user.
+CREATE OR REPLACE FUNCTION public.ensure_personal_workspace(p_user UUID)
+RETURNS UUID AS $$
+DECLARE
+  ws UUID;
+BEGIN
+  IF auth.uid() IS NOT NULL AND p_user IS DISTINCT FROM auth.uid() AND pg_trigger_depth() = 0 THEN
+    RAISE EXCEPTION 'not allowed' USING ERRCODE = '42501';
+  END IF;
+
+  SELECT id INTO ws FROM public.workspaces WHERE owner_id = p_user AND personal;
+  IF ws IS NULL THEN
+    INSERT INTO public.workspaces (name, owner_id, personal)
+    VALUES ('Kişisel', p_user, true)
+    ON CONFLICT DO NOTHING
+    RETURNING id INTO ws;
+    IF ws IS NULL THEN
+      SELECT id INTO ws FROM public.workspaces WHERE owner_id = p_user AND personal;
+    END IF;
+    INSERT INTO public.workspace_members (workspace_id, user_id, role)
+    VALUES (ws, p_user, 'owner')
+    ON CONFLICT DO NOTHING;
+  END IF;
+  RETURN ws;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Create a workspace with the caller as owner
+CREATE OR REPLACE FUNCTION public.create_workspace(p_name TEXT)
+RETURNS SETOF public.workspaces AS $$
+DECLARE
+  ws public.workspaces%ROWTYPE;
+BEGIN
+  IF auth.uid() IS NULL THEN
+    RAISE EXCEPTION 'not authenticated';
+  END IF;
+  INSERT INTO public.workspaces (name, owner_id) VALUES (p_name, auth.uid()) RETURNING * INTO ws;
+  INSERT INTO public.workspace_members (workspace_id, user_id, role) VALUES (ws.id, auth.uid(), 'owner');
+  RETURN NEXT ws;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- 3. Every board belongs to a workspace: new boards default to the creator's personal workspace.
+-- Putting a board into (or moving it to) a workspace requires owner, admin or member there.
+-- SECURITY DEFINER functions (transfer_board_ownership) move boards without that check.
+CREATE OR REPLACE FUNCTION public.assign_board_workspace()
+RETURNS trigger AS $$
+BEGIN
+  IF NEW.workspace_id IS NULL THEN
+    NEW.workspace_id := public.ensure_personal_workspace(NEW.user_id);
+  ELSIF auth.uid() IS NOT NULL
+        AND current_user IN ('authenticated', 'anon')
+        AND (TG_OP = 'INSERT' OR NEW.workspace_id IS DISTINCT FROM OLD.workspace_id)
+        AND public.role_rank(public.workspace_role(NEW.workspace_id)) < public.role_rank('member') THEN
+    RAISE EXCEPTION 'not allowed to add boards to workspace %', NEW.workspace_id
+      USING ERRCODE = 'insufficient_privilege';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS boards_assign_workspace ON public.boards;
+CREATE TRIGGER boards_assign_workspace
+  BEFORE INSERT OR UPDATE OF workspace_id ON public.boards
+  FOR EACH ROW EXECUTE FUNCTION public.assign_board_workspace();
+
+-- Boards created from a template can go straight into a workspace (NULL = personal workspace)
+DROP FUNCTION IF EXISTS public.create_board_from_template(TEXT, TEXT, JSONB);
+CREATE OR REPLACE FUNCTION public.create_board_from_template(p_title TEXT, p_type TEXT, p_definition JSONB, p_workspace UUID DEFAULT NULL)
+RETURNS SETOF public.boards AS $$
+DECLARE
+  new_board public.boards%ROWTYPE;
+  t JSONB;
+  new_task UUID;
+  task_pos INTEGER := 0;
+  sub_pos INTEGER;
+  sub TEXT;
+BEGIN
+  INSERT INTO public.boards (title, type, workflow, settings, workspace_id)
+  VALUES (
+    p_title,
+    p_type,
+    COALESCE(p_definition->'workflow', '{"statuses": []}'::jsonb),
+    COALESCE(p_definition->'settings', '{}'::jsonb),
+    p_workspace
+  )
+  RETURNING * INTO new_board;
+
+  INSERT INTO public.board_labels (board_id, name, color)
+  SELECT new_board.id, l->>'name', COALESCE(l->>'color', '#6b7280')
+  FROM jsonb_array_elements(COALESCE(p_definition->'labels', '[]'::jsonb)) AS l;
+
+  FOR t IN SELECT * FROM jsonb_array_elements(COALESCE(p_definition->'tasks', '[]'::jsonb)) LOOP
+    INSERT INTO public.tasks (board_id, title, description, status, priority, position)
+    VALUES (new_board.id, t->>'title', t->>'description', t->>'status', t->>'priority', task_pos)
+    RETURNING id INTO new_task;
+    task_pos := task_pos + 1;
+
+    sub_pos := 0;
+    FOR sub IN SELECT * FROM jsonb_array_elements_text(COALESCE(t->'subtasks', '[]'::jsonb)) LOOP
+      INSERT INTO public.subtasks (task_id, title, is_completed, position)
+      VALUES (new_task, sub, false, sub_pos);
+      sub_pos := sub_pos + 1;
+    END LOOP;
+  END LOOP;
+
+  RETURN NEXT new_board;
+END;
+$$ LANGUAGE plpgsql;
+
+-- 4. Migrate existing boards into their owners' personal workspaces
+DO $$
+DECLARE
+  u record;
+BEGIN
+  FOR u IN SELECT DISTINCT user_id FROM public.boards WHERE workspace_id IS NULL LOOP
+    UPDATE public.boards
+    SET workspace_id = public.ensure_personal_workspace(u.user_id)
+    WHERE user_id = u.user_id AND workspace_id IS NULL;
+  END LOOP;
+END $$;
+
+-- 5. RLS
+ALTER TABLE public.workspaces ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.workspace_members ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Members can view workspaces" ON public.workspaces;
+CREATE POLICY "Members can view workspaces"
+ON public.workspaces FOR SELECT
+USING ( public.workspace_role(id) IS NOT NULL );
+
+DROP POLICY IF EXISTS "Admins can update workspaces" ON public.workspaces;
+CREATE POLICY "Admins can update workspaces"
+ON public.workspaces FOR UPDATE
+USING ( public.workspace_role(id) IN ('owner', 'admin') );
+
+DROP POLICY IF EXISTS "Owners can delete workspaces" ON public.workspaces;
+CREATE POLICY "Owners can delete workspaces"
+ON public.workspaces FOR DELETE
+USING ( public.workspace_role(id) = 'owner' AND NOT personal );
+
+DROP POLICY IF EXISTS "Members can view workspace memberships" ON public.workspace_members;
+CREATE POLICY "Members can view workspace memberships"
+ON public.workspace_members FOR SELECT
+USING ( public.workspace_role(workspace_id) IS NOT NULL );
+
+-- Admins manage members and viewers only; granting or changing admin/owner rows needs the owner
+-- (same rules as AddWorkspaceMember / UpdateWorkspaceMemberRole)
+DROP POLICY IF EXISTS "Admins can add workspace members" ON public.workspace_members;
+CREATE POLICY "Admins can add workspace members"
+ON public.workspace_members FOR INSERT
+WITH CHECK (
+  public.workspace_role(workspace_id) IN ('owner', 'admin')
+  AND (role IN ('member', 'viewer') OR public.workspace_role(workspace_id) = 'owner')
+);
+
+DROP POLICY IF EXISTS "Admins can update workspace members" ON public.workspace_members;
+CREATE POLICY "Admins can update workspace members"
+ON public.workspace_members FOR UPDATE
+USING (
+  public.workspace_role(workspace_id) IN ('owner', 'admin')
+  AND (role IN ('member', 'viewer') OR public.workspace_role(workspace_id) = 'owner')
+)
+WITH CHECK (
+  public.workspace_role(workspace_id) IN ('owner', 'admin')
+  AND (role IN ('member', 'viewer') OR public.workspace_role(workspace_id) = 'owner')
+);
+
+DROP POLICY IF EXISTS "Admins or self can remove workspace members" ON public.workspace_members;
+CREATE POLICY "Admins or self can remove workspace members"
+ON public.workspace_members FOR DELETE
+USING (
+  user_id = auth.uid()
+  OR (
+    public.workspace_role(workspace_id) IN ('owner', 'admin')
+    AND (role IN ('member', 'viewer') OR public.workspace_role(workspace_id) = 'owner')
+  )
+);
+
+-- Workspace members can see the workspace's boards
+DROP POLICY IF EXISTS "Workspace members can view boards" ON public.boards;
+CREATE POLICY "Workspace members can view boards"
+ON public.boards FOR SELECT
+USING ( workspace_id IS NOT NULL AND public.workspace_role(workspace_id) IS NOT NULL );
+
+-- 6. Member lifecycle with workspaces (replaces the versions in migration_member_lifecycle.sql).
+-- A board in the owner's personal workspace follows the owner on transfer; otherwise the previous
+-- owner would keep admin access as owner of that personal workspace.
+CREATE OR REPLACE FUNCTION public.transfer_board_ownership(p_board UUID, p_new_owner UUID, p_policy TEXT DEFAULT 'keep')
+RETURNS TABLE (previous_owner UUID, new_owner UUID, reassigned_tasks INTEGER) AS $$
+DECLARE
+  old_owner UUID;
+  old_workspace UUID;
+  affected INTEGER := 0;
+BEGIN
+  SELECT b.user_id, b.workspace_id INTO old_owner, old_workspace FROM public.boards b WHERE b.id = p_board FOR UPDATE;
+
+  IF old_owner IS NULL OR old_owner <> auth.uid() THEN
+    RAISE EXCEPTION 'not allowed' USING ERRCODE = '42501';
+  END IF;
+  IF NOT EXISTS (SELECT 1 FROM public.board_members m WHERE m.board_id = p_board AND m.user_id = p_new_owner) THEN
+    RAISE EXCEPTION 'member not found' USING ERRCODE = 'P0002';
+  END IF;
+
+  UPDATE public.boards SET user_id = p_new_owner WHERE id = p_board;
+  IF EXISTS (SELECT 1 FROM public.workspaces w WHERE w.id = old_workspace AND w.personal AND w.owner_id = old_owner) THEN
+    -- NULL: assign_board_workspace puts the board into the new owner's personal workspace
+    UPDATE public.boards SET workspace_id = NULL WHERE id = p_board;
+  END IF;
+  DELETE FROM public.board_members WHERE board_id = p_board AND user_id = p_new_owner;
+  INSERT INTO public.board_members (board_id, user_id, role) VALUES (p_board, old_owner, 'admin');
+
+  IF p_policy = 'reassign' THEN
+    UPDATE public.tasks SET assigned_to = p_new_owner WHERE board_id = p_board AND assigned_to = old_owner;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  ELSIF p_policy = 'unassign' THEN
+    UPDATE public.tasks SET assigned_to = NULL WHERE board_id = p_board AND assigned_to = old_owner;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  END IF;
+
+  RETURN QUERY SELECT old_owner, p_new_owner, affected;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Removing a board member (or leaving) cannot take away access that comes from the board's workspace;
+-- such users must be removed from the workspace instead (HINT workspace_access).
+CREATE OR REPLACE FUNCTION public.remove_board_member(p_board UUID, p_user UUID, p_policy TEXT DEFAULT 'unassign')
+RETURNS TABLE (removed_user UUID, reassigned_tasks INTEGER) AS $$
+DECLARE
+  caller_role TEXT := public.board_role(p_board);
+  target_role TEXT;
+  target_workspace_role TEXT;
+  owner_id UUID;
+  affected INTEGER := 0;
+BEGIN
+  SELECT b.user_id INTO owner_id FROM public.boards b WHERE b.id = p_board;
+  SELECT m.role INTO target_role FROM public.board_members m WHERE m.board_id = p_board AND m.user_id = p_user;
+  SELECT wm.role INTO target_workspace_role FROM public.workspace_members wm
+  JOIN public.boards b ON b.workspace_id = wm.workspace_id
+  WHERE b.id = p_board AND wm.user_id = p_user;
+
+  IF owner_id IS NULL OR (target_role IS NULL AND target_workspace_role IS NULL) THEN
+    RAISE EXCEPTION 'member not found' USING ERRCODE = 'P0002';
+  END IF;
+
+  IF p_user <> auth.uid() THEN
+    IF COALESCE(caller_role, '') NOT IN ('owner', 'admin') OR (target_role = 'admin' AND caller_role <> 'owner') THEN
+      RAISE EXCEPTION 'not allowed' USING ERRCODE = '42501';
+    END IF;
+  END IF;
+
+  IF target_workspace_role IS NOT NULL THEN
+    RAISE EXCEPTION 'user % has access to board % through its workspace', p_user, p_board
+      USING ERRCODE = 'check_violation', HINT = 'workspace_access';
+  END IF;
+
+  IF p_policy = 'reassign' THEN
+    UPDATE public.tasks SET assigned_to = owner_id WHERE board_id = p_board AND assigned_to = p_user;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  ELSIF p_policy = 'unassign' THEN
+    UPDATE public.tasks SET assigned_to = NULL WHERE board_id = p_board AND assigned_to = p_user;
+    GET DIAGNOSTICS affected = ROW_COUNT;
+  END IF;
+
+  DELETE FROM public.board_members WHERE board_id = p_board AND user_id = p_user;
+
+  RETURN QUERY SELECT p_user, affected;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
@@ -8,7 +8,9 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-func Setup() *chi.Mux {
+// Setup rotaları kurar. allowedOrigin giriş noktasına göre verilir:
+// main.go (yerel sunucu) http://localhost:5173, Vercel "*".
+func Setup(allowedOrigin string) *chi.Mux {
 	// Router (Yönlendirici)
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
@@ -17,9 +19,7 @@ func Setup() *chi.Mux {
 	// CORS Middleware
 	r.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Prod: Vercel frontend domainini buraya eklemek gerekebilir
-			// Dev: localhost
-			w.Header().Set("Access-Control-Allow-Origin", "*") // Vercel için * daha güvenli olabilir (public api ise) veya origin check
+			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, apikey, prefer")
 
@@ -38,26 +38,128 @@ func Setup() *chi.Mux {
 	})
 
 	r.Route("/api", func(r chi.Router) {
-		r.Use(api.AuthMiddleware)
-
-		// Panolar (Boards)
-		r.Get("/boards", api.GetBoards)
-		r.Post("/boards", api.CreateBoard)
-		r.Delete("/boards", api.DeleteBoard)
-
-		// Görevler (Tasks)
-		r.Get("/tasks", api.GetTasks)
-		r.Post("/tasks", api.CreateTask)
-		r.Patch("/tasks", api.UpdateTask) // PATCH destekle
-		r.Put("/tasks", api.UpdateTask)
-		r.Delete("/tasks", api.DeleteTask)
-		r.Delete("/tasks/bulk", api.DeleteTasksByStatus)
-
-		// Alt Görevler (Subtasks)
-		r.Post("/subtasks", api.CreateSubtask)
-		r.Patch("/subtasks", api.UpdateSubtask)
-		r.Put("/subtasks", api.UpdateSubtask)
-		r.Delete("/subtasks", api.DeleteSubtask)
+		// WebSocket Route (Auth: Sec-WebSocket-Protocol veya ilk frame)
+		r.Get("/chat", api.HandleWebSocket)
+
+		// Herkese açık paylaşım linkleri (oturumsuz, IP başına limitli)
+		r.Group(func(r chi.Router) {
+			r.Use(api.PublicRateLimit)
+			r.Get("/public/boards/{token}", api.GetSharedBoard)
+			r.Get("/files/*", api.ServeAttachmentFile) // yerel depodaki ekler, imzalı link ile
+		})
+
+		// Protected Routes Group
+		r.Group(func(r chi.Router) {
+			r.Use(api.AuthMiddleware)
+
+			// Kişisel Erişim Token'ları (PAT)
+			r.Group(func(r chi.Router) {
+				r.Use(api.RequireSession)
+				r.Get("/tokens", api.ListPersonalAccessTokens)
+				r.Post("/tokens", api.CreatePersonalAccessToken)
+				r.Delete("/tokens", api.RevokePersonalAccessToken)
+			})
+
+			// Arka plan işleri (Jobs)
+			r.Get("/jobs/{id}", api.GetJob)
+
+			// Çalışma alanları (Workspaces)
+			r.With(api.RequireScope(api.ScopeReadTasks)).Get("/workspaces", api.GetWorkspaces)
+			r.With(api.RequireScope(api.ScopeAdminBoard)).Post("/workspaces", api.CreateWorkspace)
+			r.With(api.RequireWorkspaceRole(api.RoleAdmin)).Patch("/workspaces/{id}", api.UpdateWorkspace)
+			r.With(api.RequireWorkspaceRole(api.RoleOwner)).Delete("/workspaces/{id}", api.DeleteWorkspace)
+			r.With(api.RequireWorkspaceRole(api.RoleViewer)).Get("/workspaces/{id}/members", api.GetWorkspaceMembers)
+			r.With(api.RequireWorkspaceRole(api.RoleAdmin)).Post("/workspaces/{id}/members", api.AddWorkspaceMember)
+			r.With(api.RequireWorkspaceRole(api.RoleAdmin)).Patch("/workspaces/{id}/members", api.UpdateWorkspaceMemberRole)
+			r.With(api.RequireWorkspaceRole(api.RoleViewer)).Delete("/workspaces/{id}/members", api.RemoveWorkspaceMember)
+
+			// Panolar (Boards)
+			r.With(api.RequireScope(api.ScopeReadTasks)).Get("/boards", api.GetBoards)
+			r.With(api.RequireScope(api.ScopeAdminBoard)).Post("/boards", api.CreateBoard)
+			r.With(api.RequireScope(api.ScopeAdminBoard)).Post("/boards/join", api.JoinBoard)
+			r.With(api.RequireScope(api.ScopeReadTasks)).Get("/boards/templates", api.GetBoardTemplates)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromBody)).Post("/boards/templates", api.SaveBoardAsTemplate)
+			r.With(api.RequireScope(api.ScopeAdminBoard)).Delete("/boards/templates", api.DeleteBoardTemplate)
+			r.With(api.RequireBoardPermission(api.PermDeleteBoard, api.BoardFromQuery("id"))).Delete("/boards", api.DeleteBoard)
+			r.With(api.RequireBoardPermission(api.PermManageBoard, api.AllowArchived(api.BoardFromURLParam("id")))).Patch("/boards/{id}", api.UpdateBoard)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromURLParam("id"))).Get("/boards/{id}/preferences", api.GetBoardPreferences)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromURLParam("id"))).Put("/boards/{id}/preferences", api.UpdateBoardPreferences)
+			r.With(api.RequireScope(api.ScopeReadTasks)).Put("/boards/order", api.UpdateBoardOrder)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromURLParam("id"))).Get("/boards/{id}/labels", api.GetBoardLabels)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromURLParam("id"))).Post("/boards/{id}/labels", api.CreateBoardLabel)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromURLParam("id"))).Patch("/boards/{id}/labels", api.UpdateBoardLabel)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromURLParam("id"))).Delete("/boards/{id}/labels", api.DeleteBoardLabel)
+			r.With(api.RequireBoardPermission(api.PermShareBoard, api.AllowArchived(api.BoardFromURLParam("id")))).Get("/boards/{id}/share-links", api.GetShareLinks)
+			r.With(api.RequireBoardPermission(api.PermShareBoard, api.BoardFromURLParam("id"))).Post("/boards/{id}/share-links", api.CreateShareLink)
+			r.With(api.RequireBoardPermission(api.PermShareBoard, api.AllowArchived(api.BoardFromURLParam("id")))).Delete("/boards/{id}/share-links", api.RevokeShareLink)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromQuery("board_id"))).Get("/boards/members", api.GetBoardMembers)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromBody)).Put("/boards/members", api.UpdateBoardMemberRole)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromQuery("board_id"))).Delete("/boards/members", api.RemoveBoardMember)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromQuery("board_id"))).Post("/boards/leave", api.LeaveBoard)
+			r.With(api.RequireBoardPermission(api.PermManageBoard, api.BoardFromBody)).Post("/boards/transfer", api.TransferBoardOwnership)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromURLParam("id"))).Post("/boards/{id}/clone", api.CloneBoard)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromURLParam("id"))).Get("/boards/{id}/export", api.ExportBoard)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromURLParam("id"))).Get("/boards/{id}/workflow", api.GetBoardWorkflow)
+			r.With(api.RequireBoardPermission(api.PermManageBoard, api.BoardFromURLParam("id"))).Put("/boards/{id}/workflow", api.UpdateBoardWorkflow)
+			r.With(api.RequireScope(api.ScopeAdminBoard)).Post("/boards/import", api.ImportBoard)
+			r.With(api.RequireScope(api.ScopeAdminBoard)).Post("/boards/import/trello", api.ImportTrelloBoard)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromQuery("board_id"))).Get("/boards/invites", api.GetBoardInvites)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromBody)).Post("/boards/invites", api.CreateBoardInvite)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromQuery("board_id"))).Delete("/boards/invites", api.RevokeBoardInvite)
+			r.With(api.RequireBoardPermission(api.PermManageBoard, api.BoardFromQuery("board_id"))).Post("/boards/invite-code/rotate", api.RotateBoardInviteCode)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromQuery("board_id"))).Get("/boards/sessions", api.GetBoardSessions)
+			r.With(api.RequireBoardPermission(api.PermManageMembers, api.BoardFromQuery("board_id"))).Delete("/boards/sessions", api.DisconnectBoardSessions)
+
+			// Görevler (Tasks)
+			r.With(api.RequireBoardPermission(api.PermRead, api.OptionalBoardFromQuery("board_id"))).Get("/tasks", api.GetTasks)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromBody)).Post("/tasks", api.CreateTask)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskBody)).Patch("/tasks", api.UpdateTask) // PATCH destekle
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskBody)).Put("/tasks", api.UpdateTask)
+			r.With(api.RequireBoardPermission(api.PermDeleteTasks, api.BoardFromTaskQuery("id"))).Delete("/tasks", api.DeleteTask)
+			r.With(api.RequireBoardPermission(api.PermDeleteTasks, api.BoardFromQuery("board_id"))).Delete("/tasks/bulk", api.DeleteTasksByStatus)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromBody)).Post("/tasks/move", api.MoveTasks)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskIDBody)).Put("/tasks/labels", api.SetTaskLabels)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskIDBody)).Post("/tasks/labels", api.SetTaskLabels)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskQuery("task_id"))).Delete("/tasks/labels", api.RemoveTaskLabel)
+
+			// Görev Yorumları
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromTaskQuery("task_id"))).Get("/tasks/comments", api.GetTaskComments)
+			r.With(api.RequireBoardPermission(api.PermComment, api.BoardFromTaskIDBody)).Post("/tasks/comments", api.CreateTaskComment)
+			r.With(api.RequireBoardPermission(api.PermComment, api.BoardFromCommentBody)).Patch("/tasks/comments", api.UpdateTaskComment)
+			r.With(api.RequireBoardPermission(api.PermComment, api.BoardFromCommentQuery("id"))).Delete("/tasks/comments", api.DeleteTaskComment)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromCommentQuery("id"))).Get("/tasks/comments/history", api.GetTaskCommentHistory)
+
+			// Görev Bağımlılıkları
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromTaskQuery("task_id"))).Get("/tasks/dependencies", api.GetTaskDependencies)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromBlockedTaskBody)).Post("/tasks/dependencies", api.CreateTaskDependency)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskQuery("task_id"))).Delete("/tasks/dependencies", api.DeleteTaskDependency)
+
+			// Tekrarlayan Görevler
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromTaskQuery("task_id"))).Get("/tasks/recurrence", api.GetTaskRecurrence)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskIDBody)).Put("/tasks/recurrence", api.SetTaskRecurrence)
+			r.Get("/tasks/recurrence/preview", api.PreviewRecurrence)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskIDBody)).Post("/tasks/recurrence/skip", api.SkipTaskRecurrence)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskIDBody)).Post("/tasks/recurrence/stop", api.StopTaskRecurrence)
+
+			// Görev Ekleri
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromTaskQuery("task_id"))).Get("/tasks/attachments", api.GetTaskAttachments)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromTaskQuery("task_id"))).Post("/tasks/attachments", api.UploadTaskAttachment)
+			r.With(api.RequireBoardPermission(api.PermRead, api.BoardFromAttachmentQuery("id"))).Get("/tasks/attachments/download", api.DownloadTaskAttachment)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromAttachmentQuery("id"))).Delete("/tasks/attachments", api.DeleteTaskAttachment)
+
+			r.With(api.RequireBoardPermission(api.PermRead, api.OptionalBoardFromQuery("board_id"))).Get("/tasks/export/csv", api.ExportTasksCSV)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromQuery("board_id"))).Post("/tasks/import/csv", api.ImportTasksCSV)
+			r.With(api.RequireBoardPermission(api.PermRead, api.OptionalBoardFromQuery("board_id"))).Get("/tasks/export/todotxt", api.ExportTasksTodoTxt)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromQuery("board_id"))).Post("/tasks/import/todotxt", api.ImportTasksTodoTxt)
+			r.With(api.RequireBoardPermission(api.PermRead, api.OptionalBoardFromQuery("board_id"))).Get("/tasks/export/markdown", api.ExportTasksMarkdown)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromQuery("board_id"))).Post("/tasks/import/markdown", api.ImportTasksMarkdown)
+
+			// Alt Görevler (Subtasks)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromSubtaskBody)).Post("/subtasks", api.CreateSubtask)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromSubtaskBody)).Patch("/subtasks", api.UpdateSubtask)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromSubtaskBody)).Put("/subtasks", api.UpdateSubtask)
+			r.With(api.RequireBoardPermission(api.PermEditTasks, api.BoardFromSubtaskQuery("id"))).Delete("/subtasks", api.DeleteSubtask)
+		})
 	})
 
 	return r
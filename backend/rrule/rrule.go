@@ -0,0 +1,298 @@
+// Package rrule iCalendar (RFC 5545) tekrar kurallarının sık kullanılan bir alt kümesini ayrıştırır
+// ve tekrar zamanlarını hesaplar: FREQ=DAILY|WEEKLY|MONTHLY|YEARLY, INTERVAL, COUNT, UNTIL, BYDAY,
+// BYMONTHDAY, BYMONTH, BYSETPOS ve WKST. Saat altı frekanslar, BYYEARDAY, BYWEEKNO ve BYHOUR gibi
+// kısımlar desteklenmez; tekrarlar DTSTART'ın saatinde, kuralın saat diliminde üretilir.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // alpine gibi zoneinfo içermeyen imajlarda da saat dilimleri yüklenebilsin
+)
+
+// Frequency tekrar sıklığı
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = []string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// WeekdayNum BYDAY değeri: N=0 her hafta günü, N>0 dönemin N'inci, N<0 sondan N'inci günü (ör. -1FR son cuma).
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	name := strings.ToUpper(w.Day.String()[:2])
+	if w.N == 0 {
+		return name
+	}
+	return strconv.Itoa(w.N) + name
+}
+
+// Rule ayrıştırılmış RRULE
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *Until
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// Until UNTIL değeri. UTC ("Z" ile biten) değerler mutlak zamandır; diğerleri kuralın saat dilimindeki
+// duvar saatidir. Sadece tarih verilmişse o günün sonuna kadar olan tekrarlar dahildir.
+type Until struct {
+	Time     time.Time // UTC değilse alanlar duvar saati olarak okunur
+	UTC      bool
+	DateOnly bool
+}
+
+// in UNTIL'i verilen saat diliminde mutlak zamana çevirir.
+func (u *Until) in(loc *time.Location) time.Time {
+	if u.UTC {
+		return u.Time
+	}
+	t := u.Time
+	if u.DateOnly {
+		return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
+
+func (u *Until) String() string {
+	switch {
+	case u.DateOnly:
+		return u.Time.Format("20060102")
+	case u.UTC:
+		return u.Time.UTC().Format("20060102T150405Z")
+	default:
+		return u.Time.Format("20060102T150405")
+	}
+}
+
+// ErrUnsupported kural geçerli ama bu paketin desteklemediği bir kısım içeriyor.
+var ErrUnsupported = errors.New("desteklenmeyen RRULE kısmı")
+
+// Parse "FREQ=WEEKLY;BYDAY=MO" biçimindeki kuralı ayrıştırır ("RRULE:" öneki kabul edilir).
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	if s == "" {
+		return nil, errors.New("RRULE boş")
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	hasFreq := false
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("geçersiz RRULE kısmı: %q", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("RRULE'da %s birden fazla kez verilmiş", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			hasFreq = true
+			r.Freq, err = parseFrequency(value)
+		case "INTERVAL":
+			r.Interval, err = parseInt(value, 1, 1000)
+		case "COUNT":
+			r.Count, err = parseInt(value, 1, 10000)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(value, 31)
+		case "BYMONTH":
+			r.ByMonth, err = parseIntList(value, 12)
+			for _, m := range r.ByMonth {
+				if m < 0 {
+					err = fmt.Errorf("BYMONTH negatif olamaz: %d", m)
+				}
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseIntList(value, 366)
+		case "WKST":
+			day, ok := weekdayNames[value]
+			if !ok {
+				err = fmt.Errorf("geçersiz WKST: %s", value)
+			}
+			r.WeekStart = day
+		case "BYSECOND", "BYMINUTE", "BYHOUR", "BYYEARDAY", "BYWEEKNO":
+			err = fmt.Errorf("%w: %s", ErrUnsupported, key)
+		default:
+			err = fmt.Errorf("bilinmeyen RRULE kısmı: %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasFreq {
+		return nil, errors.New("RRULE'da FREQ gerekli")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, errors.New("COUNT ve UNTIL birlikte kullanılamaz")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, errors.New("BYMONTHDAY WEEKLY ile kullanılamaz")
+	}
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return nil, fmt.Errorf("BYDAY sıra numarası (%s) sadece MONTHLY ve YEARLY ile kullanılabilir", d)
+			}
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return nil, errors.New("BYSETPOS başka bir BYxxx kısmıyla birlikte kullanılmalı")
+	}
+	return r, nil
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	for i, name := range frequencyNames {
+		if name == value {
+			return Frequency(i), nil
+		}
+	}
+	switch value {
+	case "SECONDLY", "MINUTELY", "HOURLY":
+		return 0, fmt.Errorf("%w: FREQ=%s", ErrUnsupported, value)
+	}
+	return 0, fmt.Errorf("geçersiz FREQ: %s", value)
+}
+
+func parseInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s geçersiz (%d-%d arası olmalı)", value, min, max)
+	}
+	return n, nil
+}
+
+// parseIntList virgülle ayrılmış, sıfır olmayan ve mutlak değeri max'ı aşmayan sayılar
+func parseIntList(value string, max int) ([]int, error) {
+	var list []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(item, "+"))
+		if err != nil || n == 0 || n < -max || n > max {
+			return nil, fmt.Errorf("geçersiz değer: %s", item)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var list []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("geçersiz BYDAY: %s", item)
+		}
+		day, ok := weekdayNames[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("geçersiz BYDAY: %s", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("geçersiz BYDAY: %s", item)
+			}
+		}
+		list = append(list, WeekdayNum{N: n, Day: day})
+	}
+	return list, nil
+}
+
+func parseUntil(value string) (*Until, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return &Until{Time: t, UTC: true}, nil
+	}
+	if t, err := time.Parse("20060102T150405", value); err == nil {
+		return &Until{Time: t}, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return &Until{Time: t, DateOnly: true}, nil
+	}
+	return nil, fmt.Errorf("geçersiz UNTIL: %s (YYYYMMDD veya YYYYMMDDTHHMMSS[Z])", value)
+}
+
+// String kuralı normalleştirilmiş biçimde yazar (kaydetmek için).
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.String())
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+strings.ToUpper(r.WeekStart.String()[:2]))
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(list []int) string {
+	items := make([]string, len(list))
+	for i, n := range list {
+		items[i] = strconv.Itoa(n)
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,194 @@
+package rrule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// occurrences kuralın ilk n tekrarını kuralın saat diliminde RFC3339 olarak döndürür.
+// start kuralın saat dilimindeki duvar saatidir ("2006-01-02 15:04").
+func occurrences(t *testing.T, rule, tz, start string, n int) []string {
+	t.Helper()
+	loc, err := LoadLocation(tz)
+	if err != nil {
+		t.Fatalf("saat dilimi: %v", err)
+	}
+	begin, err := time.ParseInLocation("2006-01-02 15:04", start, loc)
+	if err != nil {
+		t.Fatalf("başlangıç: %v", err)
+	}
+	s, err := New(rule, tz, begin)
+	if err != nil {
+		t.Fatalf("%s: %v", rule, err)
+	}
+	first, ok := s.First()
+	if !ok {
+		return nil
+	}
+	list := append([]time.Time{first}, s.After(first, n-1)...)
+	result := make([]string, len(list))
+	for i, occ := range list {
+		result[i] = occ.Format(time.RFC3339)
+	}
+	return result
+}
+
+func TestScheduleOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		tz    string
+		start string
+		n     int
+		want  []string
+	}{
+		{
+			name: "BYDAY sıra numarası: her ayın ikinci salısı",
+			rule: "FREQ=MONTHLY;BYDAY=2TU", start: "2026-01-01 09:00", n: 3,
+			want: []string{"2026-01-13T09:00:00Z", "2026-02-10T09:00:00Z", "2026-03-10T09:00:00Z"},
+		},
+		{
+			name: "BYDAY sondan sıra numarası: ayın son cuması",
+			rule: "FREQ=MONTHLY;BYDAY=-1FR", start: "2026-01-01 09:00", n: 3,
+			want: []string{"2026-01-30T09:00:00Z", "2026-02-27T09:00:00Z", "2026-03-27T09:00:00Z"},
+		},
+		{
+			name: "BYMONTH olmadan yıllık BYDAY: yılın 20. pazartesisi",
+			rule: "FREQ=YEARLY;BYDAY=20MO", start: "2026-01-01 09:00", n: 2,
+			want: []string{"2026-05-18T09:00:00Z", "2027-05-17T09:00:00Z"},
+		},
+		{
+			name: "BYMONTH ile yıllık BYDAY: kasımın dördüncü perşembesi",
+			rule: "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", start: "2026-01-01 09:00", n: 2,
+			want: []string{"2026-11-26T09:00:00Z", "2027-11-25T09:00:00Z"},
+		},
+		{
+			name: "BYSETPOS=-1: ayın son iş günü",
+			rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", start: "2026-01-01 09:00", n: 3,
+			want: []string{"2026-01-30T09:00:00Z", "2026-02-27T09:00:00Z", "2026-03-31T09:00:00Z"},
+		},
+		{
+			name: "BYSETPOS=1: ayın ilk iş günü",
+			rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1", start: "2026-01-01 09:00", n: 3,
+			want: []string{"2026-01-01T09:00:00Z", "2026-02-02T09:00:00Z", "2026-03-02T09:00:00Z"},
+		},
+		{
+			name: "31'inde başlayan aylık kural kısa ayları atlar",
+			rule: "FREQ=MONTHLY", start: "2026-01-31 09:00", n: 3,
+			want: []string{"2026-01-31T09:00:00Z", "2026-03-31T09:00:00Z", "2026-05-31T09:00:00Z"},
+		},
+		{
+			name: "sadece tarihli UNTIL o günü kapsar",
+			rule: "FREQ=DAILY;UNTIL=20260303", tz: "Europe/Istanbul", start: "2026-03-01 23:30", n: 10,
+			want: []string{"2026-03-01T23:30:00+03:00", "2026-03-02T23:30:00+03:00", "2026-03-03T23:30:00+03:00"},
+		},
+		{
+			name: "UTC UNTIL mutlak zamandır: yerel 23:00'te biter",
+			rule: "FREQ=DAILY;UNTIL=20260303T200000Z", tz: "Europe/Istanbul", start: "2026-03-01 23:30", n: 10,
+			want: []string{"2026-03-01T23:30:00+03:00", "2026-03-02T23:30:00+03:00"},
+		},
+		{
+			name: "yerel UNTIL kuralın saat dilimindeki duvar saatidir ve dahildir",
+			rule: "FREQ=DAILY;UNTIL=20260303T233000", tz: "Europe/Istanbul", start: "2026-03-01 23:30", n: 10,
+			want: []string{"2026-03-01T23:30:00+03:00", "2026-03-02T23:30:00+03:00", "2026-03-03T23:30:00+03:00"},
+		},
+		{
+			name: "yaz saatine geçişte yerel saat korunur",
+			rule: "FREQ=DAILY", tz: "America/New_York", start: "2026-03-07 09:00", n: 3,
+			want: []string{"2026-03-07T09:00:00-05:00", "2026-03-08T09:00:00-04:00", "2026-03-09T09:00:00-04:00"},
+		},
+		{
+			name: "kış saatine geçişte yerel saat korunur",
+			rule: "FREQ=WEEKLY;BYDAY=SA,SU", tz: "America/New_York", start: "2026-10-31 09:00", n: 3,
+			want: []string{"2026-10-31T09:00:00-04:00", "2026-11-01T09:00:00-05:00", "2026-11-07T09:00:00-05:00"},
+		},
+		{
+			name: "COUNT tekrar sayısını sınırlar",
+			rule: "FREQ=WEEKLY;COUNT=2", start: "2026-01-01 09:00", n: 5,
+			want: []string{"2026-01-01T09:00:00Z", "2026-01-08T09:00:00Z"},
+		},
+		{
+			name: "hiç gerçekleşmeyen kural: 30 Şubat",
+			rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", start: "2026-01-01 09:00", n: 3,
+			want: nil,
+		},
+		{
+			name: "hiç gerçekleşmeyen kural: nisan ve haziranın 31'i",
+			rule: "FREQ=MONTHLY;BYMONTH=4,6;BYMONTHDAY=31", start: "2026-01-01 09:00", n: 3,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrences(t, tt.rule, tt.tz, tt.start, tt.n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s\n got: %v\nwant: %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleBetween(t *testing.T) {
+	s, err := New("FREQ=DAILY", "Europe/Istanbul", time.Date(2026, 1, 1, 6, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Date(2026, 1, 2, 6, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 1, 5, 6, 0, 0, 0, time.UTC)
+
+	// after hariç, until dahil
+	var got []string
+	for _, occ := range s.Between(after, until) {
+		got = append(got, occ.Format(time.RFC3339))
+	}
+	want := []string{"2026-01-03T09:00:00+03:00", "2026-01-04T09:00:00+03:00", "2026-01-05T09:00:00+03:00"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v\nwant: %v", got, want)
+	}
+
+	if list := s.Between(until, after); len(list) != 0 {
+		t.Errorf("ters aralık boş olmalı: %v", list)
+	}
+}
+
+func TestNeverOccurringRuleTerminates(t *testing.T) {
+	s, err := New("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, ok := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); ok {
+			t.Error("imkansız kural tekrar üretmemeli")
+		}
+		if list := s.Between(time.Time{}, time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)); len(list) != 0 {
+			t.Errorf("imkansız kural tekrar üretmemeli: %v", list)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("imkansız kural sonlanmadı")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260101",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=DAILY;BYWEEKNO=1",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+	} {
+		if _, err := Parse(rule); err == nil {
+			t.Errorf("%q için hata bekleniyordu", rule)
+		}
+	}
+}
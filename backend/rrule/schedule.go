@@ -0,0 +1,327 @@
+package rrule
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// maxEmptyPeriods art arda hiç tekrar üretmeyen dönem sınırı; imkansız kurallarda
+// (ör. BYMONTH=2;BYMONTHDAY=30) sonsuz döngüyü önler.
+const maxEmptyPeriods = 1000
+
+// maxPeriods bir hesaplamada incelenecek en fazla dönem (DTSTART'ı çok eski kurallar için üst sınır)
+const maxPeriods = 200000
+
+// Schedule kural ve başlangıcı (DTSTART). Start'ın saat dilimi tekrarların duvar saatini belirler;
+// yaz saati geçişlerinde de tekrarlar aynı yerel saatte kalır.
+type Schedule struct {
+	Rule  *Rule
+	Start time.Time
+}
+
+// New kuralı ayrıştırır ve başlangıcı verilen IANA saat diliminde yorumlar (tz boşsa UTC).
+func New(rule, tz string, start time.Time) (*Schedule, error) {
+	r, err := Parse(rule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	return &Schedule{Rule: r, Start: start.In(loc).Truncate(time.Second)}, nil
+}
+
+// LoadLocation IANA saat dilimini yükler ("" UTC'dir; "Local" kabul edilmez).
+func LoadLocation(tz string) (*time.Location, error) {
+	if tz == "" || tz == "UTC" {
+		return time.UTC, nil
+	}
+	if tz == "Local" {
+		return nil, errors.New("saat dilimi IANA adıyla verilmeli (ör. Europe/Istanbul)")
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, errors.New("bilinmeyen saat dilimi: " + tz)
+	}
+	return loc, nil
+}
+
+// After t'den sonraki (t hariç) en fazla n tekrarı döndürür.
+func (s *Schedule) After(t time.Time, n int) []time.Time {
+	var result []time.Time
+	if n <= 0 {
+		return result
+	}
+	s.each(func(occurrence time.Time) bool {
+		if occurrence.After(t) {
+			result = append(result, occurrence)
+		}
+		return len(result) < n
+	})
+	return result
+}
+
+// Next t'den sonraki ilk tekrarı döndürür; kural bittiyse false.
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	list := s.After(t, 1)
+	if len(list) == 0 {
+		return time.Time{}, false
+	}
+	return list[0], true
+}
+
+// Between after'dan sonraki (after hariç) ve until'e kadar olan (until dahil) tekrarları döndürür.
+func (s *Schedule) Between(after, until time.Time) []time.Time {
+	var result []time.Time
+	s.each(func(occurrence time.Time) bool {
+		if occurrence.After(until) {
+			return false
+		}
+		if occurrence.After(after) {
+			result = append(result, occurrence)
+		}
+		return true
+	})
+	return result
+}
+
+// First başlangıçtaki veya sonraki ilk tekrar
+func (s *Schedule) First() (time.Time, bool) {
+	return s.Next(s.Start.Add(-time.Second))
+}
+
+// each tekrarları sırayla fn'e verir; fn false dönerse veya kural biterse durur.
+func (s *Schedule) each(fn func(time.Time) bool) {
+	r := s.Rule
+	loc := s.Start.Location()
+	hour, minute, second := s.Start.Clock()
+
+	var until time.Time
+	if r.Until != nil {
+		until = r.Until.in(loc)
+	}
+
+	count := 0
+	empty := 0
+	for period := 0; period < maxPeriods && empty < maxEmptyPeriods; period++ {
+		found := false
+		for _, day := range s.candidates(period) {
+			occurrence := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, loc)
+			if occurrence.Before(s.Start) {
+				continue
+			}
+			if r.Until != nil && occurrence.After(until) {
+				return
+			}
+			found = true
+			count++
+			if !fn(occurrence) || (r.Count > 0 && count >= r.Count) {
+				return
+			}
+		}
+		if found {
+			empty = 0
+		} else {
+			empty++
+		}
+	}
+}
+
+// date takvim günü (UTC gece yarısı; sadece tarih aritmetiği için)
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// candidates period'uncu dönemin (INTERVAL adımlı gün/hafta/ay/yıl) sıralı aday günleri
+func (s *Schedule) candidates(period int) []time.Time {
+	r := s.Rule
+	y, m, d := s.Start.Date()
+	step := period * r.Interval
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := date(y, m, d+step)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			days = []time.Time{day}
+		}
+
+	case Weekly:
+		offset := (int(s.Start.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := date(y, m, d-offset+7*step)
+		weekdays := []time.Weekday{s.Start.Weekday()}
+		if len(r.ByDay) > 0 {
+			weekdays = weekdays[:0]
+			for _, wd := range r.ByDay {
+				weekdays = append(weekdays, wd.Day)
+			}
+		}
+		for _, wd := range weekdays {
+			day := weekStart.AddDate(0, 0, (int(wd)-int(r.WeekStart)+7)%7)
+			if r.matchesMonth(day) {
+				days = append(days, day)
+			}
+		}
+
+	case Monthly:
+		month := date(y, m+time.Month(step), 1)
+		if r.matchesMonth(month) {
+			days = r.monthDays(month.Year(), month.Month(), d)
+		}
+
+	case Yearly:
+		year := y + step
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				days = append(days, r.monthDays(year, time.Month(month), d)...)
+			}
+		case len(r.ByDay) > 0:
+			// BYMONTH yoksa BYDAY sıra numaraları yıla göredir (ör. 20MO yılın 20. pazartesisi)
+			for _, day := range expandByDay(r.ByDay, date(year, time.January, 1), date(year, time.December, 31)) {
+				if len(r.ByMonthDay) == 0 || r.matchesMonthDay(day) {
+					days = append(days, day)
+				}
+			}
+		case len(r.ByMonthDay) > 0:
+			for month := time.January; month <= time.December; month++ {
+				days = append(days, r.monthDays(year, month, d)...)
+			}
+		default:
+			if day := date(year, m, d); day.Month() == m { // 29 Şubat sadece artık yıllarda
+				days = []time.Time{day}
+			}
+		}
+	}
+
+	return applySetPos(uniqueSorted(days), r.BySetPos)
+}
+
+// monthDays ayın aday günleri: BYMONTHDAY ve/veya BYDAY, ikisi de yoksa başlangıcın ayın günü.
+func (r *Rule) monthDays(year int, month time.Month, startDay int) []time.Time {
+	first := date(year, month, 1)
+	last := first.AddDate(0, 1, -1)
+
+	switch {
+	case len(r.ByDay) > 0:
+		var days []time.Time
+		for _, day := range expandByDay(r.ByDay, first, last) {
+			if len(r.ByMonthDay) == 0 || r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+		return days
+	case len(r.ByMonthDay) > 0:
+		var days []time.Time
+		for _, md := range r.ByMonthDay {
+			if n := resolveMonthDay(md, last.Day()); n > 0 {
+				days = append(days, date(year, month, n))
+			}
+		}
+		return days
+	default:
+		if startDay > last.Day() {
+			return nil // ör. 31'inde başlayan aylık kural 30 çeken ayları atlar
+		}
+		return []time.Time{date(year, month, startDay)}
+	}
+}
+
+// expandByDay [first, last] aralığında BYDAY'e uyan günler
+func expandByDay(byDay []WeekdayNum, first, last time.Time) []time.Time {
+	var days []time.Time
+	for _, wd := range byDay {
+		var matches []time.Time
+		start := first.AddDate(0, 0, (int(wd.Day)-int(first.Weekday())+7)%7)
+		for day := start; !day.After(last); day = day.AddDate(0, 0, 7) {
+			matches = append(matches, day)
+		}
+		switch {
+		case wd.N == 0:
+			days = append(days, matches...)
+		case wd.N > 0 && wd.N <= len(matches):
+			days = append(days, matches[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matches):
+			days = append(days, matches[len(matches)+wd.N])
+		}
+	}
+	return days
+}
+
+// resolveMonthDay negatif günleri ayın sonundan sayar; ayda olmayan günler için 0.
+func resolveMonthDay(md, daysInMonth int) int {
+	if md < 0 {
+		md = daysInMonth + md + 1
+	}
+	if md < 1 || md > daysInMonth {
+		return 0
+	}
+	return md
+}
+
+func (r *Rule) matchesMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == day.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := date(day.Year(), day.Month()+1, 0).Day()
+	for _, md := range r.ByMonthDay {
+		if resolveMonthDay(md, daysInMonth) == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueSorted(days []time.Time) []time.Time {
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	out := days[:0]
+	for _, day := range days {
+		if len(out) == 0 || !day.Equal(out[len(out)-1]) {
+			out = append(out, day)
+		}
+	}
+	return out
+}
+
+// applySetPos BYSETPOS ile dönemin aday kümesinden sıra numarasıyla seçer (ör. -1 son iş günü).
+func applySetPos(days []time.Time, setPos []int) []time.Time {
+	if len(setPos) == 0 {
+		return days
+	}
+	var picked []time.Time
+	for _, pos := range setPos {
+		switch {
+		case pos > 0 && pos <= len(days):
+			picked = append(picked, days[pos-1])
+		case pos < 0 && -pos <= len(days):
+			picked = append(picked, days[len(days)+pos])
+		}
+	}
+	return uniqueSorted(picked)
+}
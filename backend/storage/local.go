@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// LocalStore dosyaları yerel diskte saklar. İndirme linkleri BaseURL altında HMAC ile imzalanır
+// ve ServeFile tarafından doğrulanarak sunulur.
+type LocalStore struct {
+	Root    string
+	BaseURL string // ör. /api/files
+	secret  []byte
+}
+
+// NewLocalStore dizini (yoksa) oluşturur.
+func NewLocalStore(root, baseURL string, secret []byte) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{Root: root, BaseURL: baseURL, secret: secret}, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+// Put içeriği önce geçici dosyaya yazar, sonra yerine taşır (yarım dosya kalmaz).
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Delete dosyayı siler.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL BaseURL/<key>?exp=&name=&type=&inline=&sig= şeklinde süreli link üretir.
+func (s *LocalStore) SignedURL(key string, opts URLOptions) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	query := url.Values{}
+	query.Set("exp", strconv.FormatInt(time.Now().Add(clampTTL(opts.TTL)).Unix(), 10))
+	query.Set("name", opts.Filename)
+	query.Set("type", opts.ContentType)
+	if opts.Inline {
+		query.Set("inline", "1")
+	}
+	query.Set("sig", s.sign(key, query))
+	return s.BaseURL + "/" + uriEncode(key, false) + "?" + query.Encode(), nil
+}
+
+// sign anahtar ve link parametreleri üzerinden HMAC-SHA256
+func (s *LocalStore) sign(key string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.secret)
+	for _, part := range []string{key, query.Get("exp"), query.Get("name"), query.Get("type"), query.Get("inline")} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeFile imzalı linki doğrular ve dosyayı sunar. Süresi dolmuş veya imzası tutmayan
+// linkler 403, olmayan dosyalar 404 döner.
+func (s *LocalStore) ServeFile(w http.ResponseWriter, r *http.Request, key string) {
+	query := r.URL.Query()
+	exp, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil || time.Now().Unix() > exp ||
+		!hmac.Equal([]byte(query.Get("sig")), []byte(s.sign(key, query))) {
+		http.Error(w, "Link geçersiz veya süresi dolmuş", http.StatusForbidden)
+		return
+	}
+
+	target, err := s.path(key)
+	if err != nil {
+		http.Error(w, "Dosya bulunamadı", http.StatusNotFound)
+		return
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		http.Error(w, "Dosya bulunamadı", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, "Dosya bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	contentType := query.Get("type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", contentDisposition(query.Get("name"), query.Get("inline") == "1"))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	s, err := NewLocalStore(t.TempDir(), "/api/files", []byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// serve linki ServeFile'a, yönlendiricinin yapacağı gibi anahtarı yoldan çıkararak verir.
+func serve(s *LocalStore, link string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, link, nil)
+	key := strings.TrimPrefix(req.URL.Path, s.BaseURL+"/")
+	rec := httptest.NewRecorder()
+	s.ServeFile(rec, req, key)
+	return rec
+}
+
+func TestLocalSignedURLRoundTrip(t *testing.T) {
+	s := newTestLocalStore(t)
+	key := "boards/b1/rapor şubat.txt"
+	if err := s.Put(context.Background(), key, strings.NewReader("merhaba"), 7, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := s.SignedURL(key, URLOptions{Filename: "Rapor.txt", ContentType: "text/plain", TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := serve(s, link)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("durum = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != "merhaba" {
+		t.Errorf("gövde = %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("Content-Type = %s", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="Rapor.txt"`) {
+		t.Errorf("Content-Disposition = %s", got)
+	}
+
+	inline, _ := s.SignedURL(key, URLOptions{Inline: true})
+	if rec := serve(s, inline); rec.Code != http.StatusOK || rec.Header().Get("Content-Disposition") != "inline" {
+		t.Errorf("inline link: %d %s", rec.Code, rec.Header().Get("Content-Disposition"))
+	}
+}
+
+func TestLocalSignedURLRejected(t *testing.T) {
+	s := newTestLocalStore(t)
+	key := "boards/b1/a.txt"
+	if err := s.Put(context.Background(), key, strings.NewReader("gizli"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	link, err := s.SignedURL(key, URLOptions{Filename: "a.txt", ContentType: "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withQuery := func(change func(url.Values)) string {
+		u, _ := url.Parse(link)
+		q := u.Query()
+		change(q)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	expired := func() string {
+		// imzası doğru ama süresi dolmuş link
+		q := url.Values{}
+		q.Set("exp", strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+		q.Set("name", "a.txt")
+		q.Set("type", "text/plain")
+		q.Set("sig", s.sign(key, q))
+		return s.BaseURL + "/" + key + "?" + q.Encode()
+	}
+
+	tests := []struct {
+		name string
+		link string
+		want int
+	}{
+		{"imza değiştirilmiş", withQuery(func(q url.Values) { q.Set("sig", strings.Repeat("0", 64)) }), http.StatusForbidden},
+		{"imza yok", withQuery(func(q url.Values) { q.Del("sig") }), http.StatusForbidden},
+		{"süre uzatılmış", withQuery(func(q url.Values) { q.Set("exp", strconv.FormatInt(time.Now().Add(time.Hour*24*365).Unix(), 10)) }), http.StatusForbidden},
+		{"içerik tipi değiştirilmiş", withQuery(func(q url.Values) { q.Set("type", "text/html") }), http.StatusForbidden},
+		{"inline eklenmiş", withQuery(func(q url.Values) { q.Set("inline", "1") }), http.StatusForbidden},
+		{"başka dosya", strings.Replace(link, "/a.txt?", "/b.txt?", 1), http.StatusForbidden},
+		{"süresi dolmuş", expired(), http.StatusForbidden},
+		{"geçersiz exp", withQuery(func(q url.Values) { q.Set("exp", "yarın") }), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(s, tt.link); rec.Code != tt.want {
+				t.Errorf("durum = %d, beklenen %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	// İmzası geçerli ama dosyası silinmiş link
+	if err := s.Delete(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if rec := serve(s, link); rec.Code != http.StatusNotFound {
+		t.Errorf("silinmiş dosya: durum = %d, beklenen 404", rec.Code)
+	}
+}
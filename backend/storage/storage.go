@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Depolama sürücüleri (STORAGE_DRIVER ortam değişkeni)
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// MaxURLTTL imzalı linklerin en uzun geçerlilik süresi (S3 presign sınırı)
+const MaxURLTTL = 7 * 24 * time.Hour
+
+// ErrInvalidKey anahtar boş veya dizin dışına çıkmaya çalışıyor.
+var ErrInvalidKey = errors.New("geçersiz depolama anahtarı")
+
+// URLOptions imzalı indirme linkinin seçenekleri
+type URLOptions struct {
+	Filename    string        // indirilen dosyanın adı
+	ContentType string        // yanıtın Content-Type'ı
+	Inline      bool          // tarayıcıda göster (false: indir)
+	TTL         time.Duration // linkin geçerlilik süresi
+}
+
+// Store dosya içeriklerini (blob) saklayan arka uç. Meta veriler veritabanında tutulur.
+type Store interface {
+	// Put içeriği anahtarın altına yazar; aynı anahtar varsa üzerine yazılır.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Delete içeriği siler; olmayan anahtar hata değildir.
+	Delete(ctx context.Context, key string) error
+	// SignedURL içeriği oturumsuz indirmek için süreli bir link üretir.
+	SignedURL(key string, opts URLOptions) (string, error)
+}
+
+// NewFromEnv ortam değişkenlerinden bir Store oluşturur.
+//
+//	STORAGE_DRIVER        local (varsayılan) veya s3
+//	STORAGE_LOCAL_DIR     local: dosyaların yazılacağı dizin (varsayılan: data/attachments)
+//	STORAGE_URL_SECRET    local: imzalı linkler için anahtar (yoksa her açılışta rastgele üretilir)
+//	S3_ENDPOINT           s3: uç nokta, ör. http://localhost:9000 (varsayılan: https://s3.<region>.amazonaws.com)
+//	S3_REGION             s3: bölge (varsayılan: us-east-1)
+//	S3_BUCKET             s3: bucket adı
+//	S3_ACCESS_KEY_ID      s3: erişim anahtarı
+//	S3_SECRET_ACCESS_KEY  s3: gizli anahtar
+//	S3_FORCE_PATH_STYLE   s3: true ise bucket yolda taşınır (MinIO; özel uç noktada varsayılan true)
+func NewFromEnv(localBaseURL string) (Store, error) {
+	switch driver := strings.ToLower(envOr("STORAGE_DRIVER", DriverLocal)); driver {
+	case DriverLocal:
+		secret := []byte(os.Getenv("STORAGE_URL_SECRET"))
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		return NewLocalStore(envOr("STORAGE_LOCAL_DIR", "data/attachments"), localBaseURL, secret)
+
+	case DriverS3:
+		region := envOr("S3_REGION", "us-east-1")
+		endpoint := strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/")
+		pathStyle := endpoint != ""
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+		}
+		if v := os.Getenv("S3_FORCE_PATH_STYLE"); v != "" {
+			pathStyle = v == "true" || v == "1"
+		}
+		return NewS3Store(S3Config{
+			Endpoint:  endpoint,
+			Region:    region,
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle: pathStyle,
+		})
+
+	default:
+		return nil, fmt.Errorf("bilinmeyen STORAGE_DRIVER: %s (local, s3)", driver)
+	}
+}
+
+// validKey anahtarın göreli, boş olmayan ve ".." içermeyen bir yol olduğunu kontrol eder.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// clampTTL süreyi (0, MaxURLTTL] aralığına çeker; 0 ise 15 dakika.
+func clampTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return 15 * time.Minute
+	}
+	if ttl > MaxURLTTL {
+		return MaxURLTTL
+	}
+	return ttl
+}
+
+// contentDisposition indirme başlığı (RFC 6266, UTF-8 dosya adları için filename*)
+func contentDisposition(filename string, inline bool) string {
+	kind := "attachment"
+	if inline {
+		kind = "inline"
+	}
+	if filename == "" {
+		return kind
+	}
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, kind, ascii, uriEncode(filename, true))
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -90,9 +90,9 @@ export const deleteTask = async (id) => {
     })
 }
 
-export const deleteTasksByStatus = async (status) => {
+export const deleteTasksByStatus = async (status, boardId) => {
     const token = await getToken()
-    await axios.delete(`${API_URL}/tasks/bulk?status=${status}`, {
+    await axios.delete(`${API_URL}/tasks/bulk?status=${status}&board_id=${boardId}`, {
         headers: { Authorization: `Bearer ${token}` }
     })
 }
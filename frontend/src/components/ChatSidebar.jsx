@@ -39,11 +39,11 @@ export default function ChatSidebar({ boardId, boardTitle, isOpen, onClose }) {
 
         const connect = async () => {
             try {
-                const token = await getToken() // Though we pass params, token for future
-                // Passing params directly for MVP
-                const wsUrl = `${WS_URL}/chat?board_id=${boardId}&user_id=${user.id}&email=${user.email}&token=${token}`
+                const token = await getToken()
+                // Kimlik sunucuda token'dan çıkarılır; token URL yerine Sec-WebSocket-Protocol ile gider
+                const wsUrl = `${WS_URL}/chat?board_id=${boardId}`
 
-                ws = new WebSocket(wsUrl)
+                ws = new WebSocket(wsUrl, ['access_token', token])
 
                 ws.onopen = () => {
                     console.log('Chat Connected')
@@ -53,6 +53,8 @@ export default function ChatSidebar({ boardId, boardTitle, isOpen, onClose }) {
                 ws.onmessage = (event) => {
                     try {
                         const msg = JSON.parse(event.data)
+                        // Pano olayları (member_removed vb.) sohbet mesajı değildir
+                        if (msg.type !== 'text' && msg.type !== 'history') return
                         if (msg.type === 'history') {
                             setMessages(prev => {
                                 // Avoid duplicates if reconnecting
@@ -396,7 +396,7 @@ export default function Board() {
         setTasks(tasks.filter(t => t.status !== 'Done'))
 
         try {
-            await deleteTasksByStatus('Done')
+            await deleteTasksByStatus('Done', boardId)
         } catch (error) {
             console.error("Clear column failed", error)
             setTasks(previousTasks)